@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+)
+
+type activityEntry struct {
+	Seq     int64     `json:"seq"`
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`
+	Message string    `json:"message"`
+	Subject string    `json:"subject"`
+	Status  int       `json:"status"`
+}
+
+// runActivity prints the newest page of the server's activity feed.
+func runActivity(args []string) {
+	q := url.Values{}
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--type", "--since", "--limit":
+			if i+1 >= len(args) {
+				fmt.Fprintf(os.Stderr, "%s needs a value\n", args[i])
+				os.Exit(2)
+			}
+			q.Set(args[i][2:], args[i+1])
+			i++
+		default:
+			fmt.Fprintln(os.Stderr, "usage: envm activity [--type user,webhook,env] [--since 1h] [--limit N]")
+			os.Exit(2)
+		}
+	}
+	c := mustClient()
+	var page struct {
+		Entries []activityEntry `json:"entries"`
+	}
+	if err := c.Do("GET", "/api/v1/activity?"+q.Encode(), nil, &page); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	for _, e := range page.Entries {
+		line := fmt.Sprintf("%s  %-7s  %s", e.Time.Local().Format("2006-01-02 15:04:05"), e.Type, e.Message)
+		if e.Type == "env" {
+			line += "  [" + e.Subject + "]"
+		}
+		if e.Status >= 400 {
+			line += fmt.Sprintf("  (%d)", e.Status)
+		}
+		fmt.Println(line)
+	}
+}
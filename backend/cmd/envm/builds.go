@@ -24,7 +24,7 @@ type build struct {
 
 func runBuilds(args []string) {
 	if len(args) < 1 {
-		fmt.Fprintln(os.Stderr, "usage: envm builds <trigger|logs|list> <project>/<env> [...]")
+		fmt.Fprintln(os.Stderr, "usage: envm builds <trigger|logs|list|cancel> <project>/<env>|<build-id> [...]")
 		os.Exit(2)
 	}
 	switch args[0] {
@@ -34,6 +34,8 @@ func runBuilds(args []string) {
 		buildsLogs(args[1:])
 	case "list":
 		buildsList(args[1:])
+	case "cancel":
+		buildsCancel(args[1:])
 	default:
 		fmt.Fprintf(os.Stderr, "unknown builds subcommand %q\n", args[0])
 		os.Exit(2)
@@ -51,7 +53,7 @@ func envIDFromArg(s string) (string, error) {
 
 func buildsTrigger(args []string) {
 	if len(args) < 1 {
-		fmt.Fprintln(os.Stderr, "usage: envm builds trigger <project>/<env>")
+		fmt.Fprintln(os.Stderr, "usage: envm builds trigger <project>/<env> [--profile <name>]... [--no-profiles]")
 		os.Exit(2)
 	}
 	envID, err := envIDFromArg(args[0])
@@ -59,6 +61,26 @@ func buildsTrigger(args []string) {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(2)
 	}
+	// Profiles are sticky on the server: omitting both flags keeps the
+	// project's stored set.
+	var profiles []string
+	for i := 1; i < len(args); i++ {
+		switch args[i] {
+		case "--profile":
+			if i+1 >= len(args) {
+				fmt.Fprintln(os.Stderr, "--profile needs a value")
+				os.Exit(2)
+			}
+			i++
+			profiles = append(profiles, args[i])
+		case "--no-profiles":
+			profiles = []string{}
+		}
+	}
+	var body any
+	if profiles != nil {
+		body = map[string]any{"profiles": profiles}
+	}
 	c := mustClient()
 	var resp struct {
 		Data struct {
@@ -66,7 +88,7 @@ func buildsTrigger(args []string) {
 			EnvID   string `json:"env_id"`
 		} `json:"data"`
 	}
-	if err := c.Do("POST", "/api/v1/envs/"+url.PathEscape(envID)+"/build", nil, &resp); err != nil {
+	if err := c.Do("POST", "/api/v1/envs/"+url.PathEscape(envID)+"/build", body, &resp); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
@@ -131,6 +153,19 @@ func buildsList(args []string) {
 	_ = w.Flush()
 }
 
+func buildsCancel(args []string) {
+	if len(args) < 1 {
+		fmt.Fprintln(os.Stderr, "usage: envm builds cancel <build-id>")
+		os.Exit(2)
+	}
+	c := mustClient()
+	if err := c.Do("POST", "/api/v1/builds/"+url.PathEscape(args[0])+"/cancel", nil, nil); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("cancelling build %s\n", args[0])
+}
+
 func truncate(s string, n int) string {
 	if len(s) <= n {
 		return s
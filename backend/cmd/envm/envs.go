@@ -11,12 +11,14 @@ import (
 
 func runEnvs(args []string) {
 	if len(args) < 1 {
-		fmt.Fprintln(os.Stderr, "usage: envm envs destroy <project>/<env> [--yes]")
+		fmt.Fprintln(os.Stderr, "usage: envm envs destroy|pull <project>/<env>")
 		os.Exit(2)
 	}
 	switch args[0] {
 	case "destroy":
 		envsDestroy(args[1:])
+	case "pull":
+		envsPull(args[1:])
 	default:
 		fmt.Fprintf(os.Stderr, "unknown envs subcommand %q\n", args[0])
 		os.Exit(2)
@@ -25,7 +27,7 @@ func runEnvs(args []string) {
 
 func envsDestroy(args []string) {
 	if len(args) < 1 {
-		fmt.Fprintln(os.Stderr, "usage: envm envs destroy <project>/<env> [--yes]")
+		fmt.Fprintln(os.Stderr, "usage: envm envs destroy <project>/<env> [--yes] [--keep-volumes]")
 		os.Exit(2)
 	}
 	envID, err := envIDFromArg(args[0])
@@ -33,12 +35,19 @@ func envsDestroy(args []string) {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(2)
 	}
-	yes := false
+	yes, keepVolumes := false, false
 	for _, a := range args[1:] {
-		if a == "--yes" {
+		switch a {
+		case "--yes":
 			yes = true
+		case "--keep-volumes":
+			keepVolumes = true
 		}
 	}
+	q := url.Values{}
+	if keepVolumes {
+		q.Set("volumes", "false")
+	}
 	if !yes {
 		fmt.Fprintf(os.Stderr, "Type env id %q to confirm: ", envID)
 		typed, _ := bufio.NewReader(os.Stdin).ReadString('\n')
@@ -46,12 +55,60 @@ func envsDestroy(args []string) {
 			fmt.Fprintln(os.Stderr, "confirmation mismatch — aborting")
 			os.Exit(1)
 		}
+		// The typed id doubles as the server-side confirmation for
+		// removing a protected project's volumes.
+		q.Set("confirm", envID)
+	}
+	path := "/api/v1/envs/" + url.PathEscape(envID) + "/destroy"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
 	}
 	c := mustClient()
 	var resp json.RawMessage
-	if err := c.Do("POST", "/api/v1/envs/"+url.PathEscape(envID)+"/destroy", nil, &resp); err != nil {
+	if err := c.Do("POST", path, nil, &resp); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 	fmt.Println(string(resp))
 }
+
+func envsPull(args []string) {
+	if len(args) < 1 {
+		fmt.Fprintln(os.Stderr, "usage: envm envs pull <project>/<env>")
+		os.Exit(2)
+	}
+	envID, err := envIDFromArg(args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	c := mustClient()
+	var resp struct {
+		Results []struct {
+			Service string `json:"service"`
+			Image   string `json:"image"`
+			Skipped bool   `json:"skipped"`
+			OK      bool   `json:"ok"`
+			Error   string `json:"error"`
+		} `json:"results"`
+	}
+	if err := c.Do("POST", "/api/v1/envs/"+url.PathEscape(envID)+"/pull", nil, &resp); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	failed := false
+	for _, r := range resp.Results {
+		switch {
+		case r.Skipped:
+			fmt.Printf("%-20s skipped (built locally)\n", r.Service)
+		case r.OK:
+			fmt.Printf("%-20s pulled %s\n", r.Service, r.Image)
+		default:
+			failed = true
+			fmt.Printf("%-20s FAILED %s: %s\n", r.Service, r.Image, r.Error)
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
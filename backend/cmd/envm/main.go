@@ -49,6 +49,8 @@ func main() {
 		runLicense(os.Args[2:])
 	case "backup":
 		runBackup(os.Args[2:])
+	case "activity":
+		runActivity(os.Args[2:])
 	case "admin-token":
 		runAdminToken(os.Args[2:])
 	case "help", "-h", "--help":
@@ -71,18 +73,23 @@ Usage:
   envm secrets import <project> path/to/.env
   envm secrets check <project>
   envm projects list
-  envm projects onboard <git-url> [--token PAT]
+  envm projects onboard <git-url> [--token PAT] [--build] [--dry-run]
   envm projects show <project-id>
-  envm projects delete <project-id> [--yes]
-  envm builds trigger <project>/<env>
+  envm projects delete <project-id> [--yes] [--force]
+  envm projects protect|unprotect <project-id>
+  envm projects reconcile <project-id>
+  envm builds trigger <project>/<env> [--profile NAME]... [--no-profiles]
   envm builds logs <project>/<env>
   envm builds list <project>/<env>
-  envm envs destroy <project>/<env> [--yes]
-  envm services status
+  envm builds cancel <build-id>
+  envm envs destroy <project>/<env> [--yes] [--keep-volumes]
+  envm envs pull <project>/<env>
+  envm services status|restart
   envm license gen-keypair
   envm license issue --to "Acme" --private-key KEY [--days 365] [--max-projects N]
   envm license verify --file FILE --public-key KEY
   envm backup [--out FILE]
+  envm activity [--type user,webhook,env] [--since 1h] [--limit N]
   envm admin-token show|rotate          (server-local: needs DATA_DIR + CREDENTIAL_KEY)
   envm config show
   envm version
@@ -11,11 +11,14 @@ import (
 )
 
 type project struct {
-	ID            string `json:"id"`
-	Name          string `json:"name"`
-	RepoURL       string `json:"repo_url"`
-	DefaultBranch string `json:"default_branch"`
-	Status        string `json:"status"`
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	RepoURL       string   `json:"repo_url"`
+	DefaultBranch string   `json:"default_branch"`
+	Status        string   `json:"status"`
+	Notes         string   `json:"notes"`
+	Protected     bool     `json:"protected"`
+	Profiles      []string `json:"profiles"`
 }
 
 type projectDetail struct {
@@ -34,7 +37,7 @@ type environment struct {
 
 func runProjects(args []string) {
 	if len(args) < 1 {
-		fmt.Fprintln(os.Stderr, "usage: envm projects <list|onboard|show|delete> [...]")
+		fmt.Fprintln(os.Stderr, "usage: envm projects <list|onboard|show|delete|protect|unprotect|reconcile> [...]")
 		os.Exit(2)
 	}
 	switch args[0] {
@@ -46,6 +49,12 @@ func runProjects(args []string) {
 		projectsShow(args[1:])
 	case "delete":
 		projectsDelete(args[1:])
+	case "protect":
+		projectsSetProtected(args[1:], true)
+	case "unprotect":
+		projectsSetProtected(args[1:], false)
+	case "reconcile":
+		projectsReconcile(args[1:])
 	default:
 		fmt.Fprintf(os.Stderr, "unknown projects subcommand %q\n", args[0])
 		os.Exit(2)
@@ -69,24 +78,37 @@ func projectsList(_ []string) {
 
 func projectsOnboard(args []string) {
 	if len(args) < 1 {
-		fmt.Fprintln(os.Stderr, "usage: envm projects onboard <git-url> [--token PAT]")
+		fmt.Fprintln(os.Stderr, "usage: envm projects onboard <git-url> [--token PAT] [--build] [--dry-run]")
 		os.Exit(2)
 	}
 	repoURL := args[0]
 	var token string
+	var build, dryRun bool
 	for i := 1; i < len(args); i++ {
-		if args[i] == "--token" && i+1 < len(args) {
+		switch {
+		case args[i] == "--token" && i+1 < len(args):
 			token = args[i+1]
 			i++
+		case args[i] == "--build":
+			build = true
+		case args[i] == "--dry-run":
+			dryRun = true
 		}
 	}
-	body := map[string]string{"repo_url": repoURL}
+	body := map[string]any{"repo_url": repoURL}
 	if token != "" {
 		body["token"] = token
 	}
+	if build {
+		body["build"] = true
+	}
 	c := mustClient()
+	path := "/api/v1/projects"
+	if dryRun {
+		path += "?dry_run=true"
+	}
 	var resp json.RawMessage
-	if err := c.Do("POST", "/api/v1/projects", body, &resp); err != nil {
+	if err := c.Do("POST", path, body, &resp); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
@@ -110,6 +132,15 @@ func projectsShow(args []string) {
 	fmt.Printf("Repo:           %s\n", detail.Project.RepoURL)
 	fmt.Printf("Default branch: %s\n", detail.Project.DefaultBranch)
 	fmt.Printf("Status:         %s\n", detail.Project.Status)
+	if detail.Project.Protected {
+		fmt.Println("Protected:      yes (delete needs --force)")
+	}
+	if len(detail.Project.Profiles) > 0 {
+		fmt.Printf("Profiles:       %s\n", strings.Join(detail.Project.Profiles, ", "))
+	}
+	if detail.Project.Notes != "" {
+		fmt.Printf("Notes:          %s\n", detail.Project.Notes)
+	}
 	fmt.Println()
 	fmt.Println("Environments:")
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
@@ -122,14 +153,17 @@ func projectsShow(args []string) {
 
 func projectsDelete(args []string) {
 	if len(args) < 1 {
-		fmt.Fprintln(os.Stderr, "usage: envm projects delete <project-id> [--yes]")
+		fmt.Fprintln(os.Stderr, "usage: envm projects delete <project-id> [--yes] [--force]")
 		os.Exit(2)
 	}
 	id := args[0]
-	yes := false
+	yes, force := false, false
 	for _, a := range args[1:] {
-		if a == "--yes" {
+		switch a {
+		case "--yes":
 			yes = true
+		case "--force":
+			force = true
 		}
 	}
 	if !yes {
@@ -144,9 +178,49 @@ func projectsDelete(args []string) {
 	}
 	c := mustClient()
 	var resp json.RawMessage
-	if err := c.Do("DELETE", "/api/v1/projects/"+url.PathEscape(id), nil, &resp); err != nil {
+	path := "/api/v1/projects/" + url.PathEscape(id)
+	if force {
+		// Overrides the protected flag; the typed confirmation above (or
+		// --yes) doubles as the server-side confirm value.
+		path += "?force=true&confirm=" + url.QueryEscape(id)
+	}
+	if err := c.Do("DELETE", path, nil, &resp); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 	fmt.Println(string(resp))
 }
+
+func projectsSetProtected(args []string, protected bool) {
+	verb := "protect"
+	if !protected {
+		verb = "unprotect"
+	}
+	id := mustProjectArg(args, "envm projects "+verb+" <project-id>")
+	c := mustClient()
+	body := map[string]bool{"protected": protected}
+	if err := c.Do("PUT", "/api/v1/projects/"+url.PathEscape(id)+"/protection", body, nil); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("project %s %sed\n", id, verb)
+}
+
+func projectsReconcile(args []string) {
+	id := mustProjectArg(args, "envm projects reconcile <project-id>")
+	c := mustClient()
+	var resp struct {
+		Changes []string `json:"changes"`
+	}
+	if err := c.Do("POST", "/api/v1/projects/"+url.PathEscape(id)+"/reconcile", nil, &resp); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if len(resp.Changes) == 0 {
+		fmt.Printf("project %s already in sync\n", id)
+		return
+	}
+	for _, ch := range resp.Changes {
+		fmt.Println(ch)
+	}
+}
@@ -6,20 +6,26 @@ import (
 )
 
 type serviceStatus struct {
-	Container string `json:"container"`
-	Image     string `json:"image"`
-	Running   bool   `json:"running"`
-	Exists    bool   `json:"exists"`
+	Container   string `json:"container"`
+	Image       string `json:"image"`
+	Running     bool   `json:"running"`
+	Exists      bool   `json:"exists"`
+	State       string `json:"state"`
+	ExitCode    int    `json:"exit_code"`
+	OOMKilled   bool   `json:"oom_killed"`
+	ImageDigest string `json:"image_digest"`
 }
 
 func runServices(args []string) {
 	if len(args) < 1 {
-		fmt.Fprintln(os.Stderr, "usage: envm services status")
+		fmt.Fprintln(os.Stderr, "usage: envm services <status|restart>")
 		os.Exit(2)
 	}
 	switch args[0] {
 	case "status":
 		servicesStatus()
+	case "restart":
+		servicesRestart()
 	default:
 		fmt.Fprintf(os.Stderr, "unknown services subcommand %q\n", args[0])
 		os.Exit(2)
@@ -36,6 +42,45 @@ func servicesStatus() {
 		fmt.Fprintln(os.Stderr, "redis:", err)
 	}
 	for _, s := range []serviceStatus{pg, rd} {
-		fmt.Printf("%-15s  image=%-13s  exists=%v  running=%v\n", s.Container, s.Image, s.Exists, s.Running)
+		printServiceStatus(s)
+		fmt.Println()
+	}
+}
+
+func servicesRestart() {
+	c := mustClient()
+	var results []struct {
+		Status serviceStatus `json:"status"`
+		Stop   struct {
+			WasRunning bool `json:"was_running"`
+			Graceful   bool `json:"graceful"`
+			ExitCode   int  `json:"exit_code"`
+		} `json:"stop"`
+		Error string `json:"error"`
+	}
+	err := c.Do("POST", "/api/v1/services/restart", nil, &results)
+	for _, r := range results {
+		printServiceStatus(r.Status)
+		if r.Stop.WasRunning && !r.Stop.Graceful {
+			fmt.Printf("  killed: ignored SIGTERM (exit_code=%d)", r.Stop.ExitCode)
+		}
+		if r.Error != "" {
+			fmt.Printf("  error=%s", r.Error)
+		}
+		fmt.Println()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func printServiceStatus(s serviceStatus) {
+	fmt.Printf("%-15s  image=%-13s  exists=%v  running=%v", s.Container, s.Image, s.Exists, s.Running)
+	if s.Exists && !s.Running {
+		fmt.Printf("  exit_code=%d  oom_killed=%v", s.ExitCode, s.OOMKilled)
+	}
+	if s.ImageDigest != "" {
+		fmt.Printf("  digest=%s", s.ImageDigest)
 	}
 }
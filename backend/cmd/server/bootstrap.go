@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Service-plane bootstrap retry. The server and the docker daemon often
+// start together, so the first EnsureService can hit a daemon that isn't
+// accepting connections yet or a registry that isn't reachable. Retrying
+// with backoff lets that self-correct instead of leaving paas-postgres /
+// paas-redis down until the next restart.
+const (
+	bootstrapAttempts = 5
+	bootstrapMaxDelay = 30 * time.Second
+)
+
+// bootstrapBaseDelay is the wait before the second attempt; it doubles per
+// attempt up to bootstrapMaxDelay. A var so tests can shrink it.
+var bootstrapBaseDelay = 2 * time.Second
+
+// bootstrapDelay returns the wait before attempt n+1 (n >= 1): exponential
+// backoff with ±50% jitter so several servers on one host don't retry in
+// lockstep.
+func bootstrapDelay(n int) time.Duration {
+	d := bootstrapBaseDelay << (n - 1)
+	if d <= 0 || d > bootstrapMaxDelay {
+		d = bootstrapMaxDelay
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d)))
+}
+
+// ensureWithRetry calls ensure up to bootstrapAttempts times, stopping early
+// when ctx is done. Returns the last error.
+func ensureWithRetry(ctx context.Context, name string, ensure func(context.Context) error, logger *zap.Logger) error {
+	var err error
+	for n := 1; ; n++ {
+		if err = ensure(ctx); err == nil {
+			return nil
+		}
+		if n == bootstrapAttempts {
+			return fmt.Errorf("%s: giving up after %d attempts: %w", name, n, err)
+		}
+		wait := bootstrapDelay(n)
+		logger.Warn("Service-plane bootstrap failed, retrying",
+			zap.String("service", name), zap.Int("attempt", n),
+			zap.Duration("retry_in", wait), zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s: %w (last error: %v)", name, ctx.Err(), err)
+		case <-time.After(wait):
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestEnsureWithRetry(t *testing.T) {
+	defer func(d time.Duration) { bootstrapBaseDelay = d }(bootstrapBaseDelay)
+	bootstrapBaseDelay = time.Millisecond
+
+	calls := 0
+	flaky := func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errors.New("daemon not ready")
+		}
+		return nil
+	}
+	if err := ensureWithRetry(context.Background(), "postgres", flaky, zap.NewNop()); err != nil || calls != 3 {
+		t.Fatalf("err = %v after %d calls, want success on 3rd", err, calls)
+	}
+
+	calls = 0
+	down := func(context.Context) error { calls++; return errors.New("down") }
+	if err := ensureWithRetry(context.Background(), "redis", down, zap.NewNop()); err == nil || calls != bootstrapAttempts {
+		t.Errorf("err = %v after %d calls, want failure after %d", err, calls, bootstrapAttempts)
+	}
+}
+
+func TestBootstrapDelay_Capped(t *testing.T) {
+	for n := 1; n < 40; n++ {
+		if d := bootstrapDelay(n); d <= 0 || d > bootstrapMaxDelay*3/2 {
+			t.Fatalf("attempt %d: delay %v out of range", n, d)
+		}
+	}
+}
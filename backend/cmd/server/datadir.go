@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// dataDirLayout is the skeleton under DATA_DIR. The stores also create
+// their own directories lazily; laying it out up front means a fresh
+// install has a predictable tree and a read-only or mis-mounted volume
+// fails at boot instead of on the first build.
+var dataDirLayout = []string{
+	"projects", // projects.Store: project / environment / build rows
+	"repos",    // repos.Manager: git clones
+	"envs",     // builder.Runner: rendered compose + .env per environment
+	"builds",   // builder.Runner: build logs + compose snapshots
+}
+
+// initDataDir creates the data directory skeleton and checks that the
+// directory is writable. Idempotent — safe on every boot.
+func initDataDir(dir string) error {
+	for _, sub := range append([]string{""}, dataDirLayout...) {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			return fmt.Errorf("create %s: %w", filepath.Join(dir, sub), err)
+		}
+	}
+	probe, err := os.CreateTemp(dir, ".write-probe-*")
+	if err != nil {
+		return fmt.Errorf("data dir %s is not writable: %w", dir, err)
+	}
+	name := probe.Name()
+	_ = probe.Close()
+	return os.Remove(name)
+}
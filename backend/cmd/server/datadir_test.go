@@ -0,0 +1,27 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInitDataDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "data")
+	if err := initDataDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, sub := range dataDirLayout {
+		if fi, err := os.Stat(filepath.Join(dir, sub)); err != nil || !fi.IsDir() {
+			t.Errorf("%s not created: %v", sub, err)
+		}
+	}
+	// Idempotent, and leaves no probe file behind.
+	if err := initDataDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != len(dataDirLayout) {
+		t.Errorf("unexpected entries in data dir: %v", entries)
+	}
+}
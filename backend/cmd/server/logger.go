@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// newLogger builds the process logger from LOG_LEVEL / LOG_FORMAT. level is
+// one of debug|info|warn|error (default info); format is json (default,
+// production encoding) or console (human-readable, for local dev). An
+// invalid value doesn't stop startup: the defaults are used and the error
+// is returned alongside a working logger, for the caller to report. The
+// returned AtomicLevel lets a config reload change the level in place.
+func newLogger(level, format string) (*zap.Logger, zap.AtomicLevel, error) {
+	zcfg, cfgErr := loggerConfig(level, format)
+	if cfgErr != nil {
+		zcfg, _ = loggerConfig("", "")
+	}
+	logger, err := zcfg.Build()
+	if err != nil {
+		return nil, zap.AtomicLevel{}, err
+	}
+	return logger, zcfg.Level, cfgErr
+}
+
+// loggerConfig maps LOG_LEVEL / LOG_FORMAT onto a zap.Config.
+func loggerConfig(level, format string) (zap.Config, error) {
+	var zcfg zap.Config
+	switch format {
+	case "", "json":
+		zcfg = zap.NewProductionConfig()
+	case "console":
+		zcfg = zap.NewDevelopmentConfig()
+	default:
+		return zap.Config{}, fmt.Errorf("unknown LOG_FORMAT %q (want json or console)", format)
+	}
+	if level != "" {
+		lvl, err := zapcore.ParseLevel(level)
+		if err != nil {
+			return zap.Config{}, fmt.Errorf("invalid LOG_LEVEL %q: %w", level, err)
+		}
+		zcfg.Level = zap.NewAtomicLevelAt(lvl)
+	} else {
+		zcfg.Level = zap.NewAtomicLevelAt(zap.InfoLevel)
+	}
+	return zcfg, nil
+}
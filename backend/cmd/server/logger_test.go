@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNewLogger(t *testing.T) {
+	cases := []struct {
+		level, format string
+		wantLevel     zapcore.Level
+		wantEncoding  string
+		wantErr       bool
+	}{
+		{"", "", zapcore.InfoLevel, "json", false},
+		{"debug", "json", zapcore.DebugLevel, "json", false},
+		{"WARN", "console", zapcore.WarnLevel, "console", false},
+		{"error", "", zapcore.ErrorLevel, "json", false},
+		// Invalid values fall back to info/json and report the error.
+		{"verbose", "console", zapcore.InfoLevel, "json", true},
+		{"debug", "xml", zapcore.InfoLevel, "json", true},
+	}
+	for _, c := range cases {
+		logger, lvl, err := newLogger(c.level, c.format)
+		if (err != nil) != c.wantErr {
+			t.Errorf("newLogger(%q, %q) err = %v, wantErr %v", c.level, c.format, err, c.wantErr)
+		}
+		if logger == nil {
+			t.Fatalf("newLogger(%q, %q) returned no logger", c.level, c.format)
+		}
+		if lvl.Level() != c.wantLevel {
+			t.Errorf("newLogger(%q, %q) level = %v, want %v", c.level, c.format, lvl.Level(), c.wantLevel)
+		}
+		if !logger.Core().Enabled(c.wantLevel) || (c.wantLevel > zapcore.DebugLevel && logger.Core().Enabled(c.wantLevel-1)) {
+			t.Errorf("newLogger(%q, %q) core doesn't follow level %v", c.level, c.format, c.wantLevel)
+		}
+
+		zcfg, cerr := loggerConfig(c.level, c.format)
+		if cerr == nil && zcfg.Encoding != c.wantEncoding {
+			t.Errorf("loggerConfig(%q, %q) encoding = %q, want %q", c.level, c.format, zcfg.Encoding, c.wantEncoding)
+		}
+	}
+
+	// The AtomicLevel is live: changing it retunes the logger in place.
+	logger, lvl, _ := newLogger("info", "json")
+	lvl.SetLevel(zapcore.DebugLevel)
+	if !logger.Core().Enabled(zapcore.DebugLevel) {
+		t.Error("SetLevel(debug) did not reach the logger")
+	}
+}
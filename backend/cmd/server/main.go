@@ -4,16 +4,19 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
+	"github.com/environment-manager/backend/internal/activity"
 	"github.com/environment-manager/backend/internal/api"
 	"github.com/environment-manager/backend/internal/builder"
 	"github.com/environment-manager/backend/internal/config"
@@ -21,6 +24,7 @@ import (
 	"github.com/environment-manager/backend/internal/docker"
 	"github.com/environment-manager/backend/internal/license"
 	"github.com/environment-manager/backend/internal/models"
+	"github.com/environment-manager/backend/internal/monitor"
 	"github.com/environment-manager/backend/internal/projects"
 	"github.com/environment-manager/backend/internal/repos"
 	"github.com/environment-manager/backend/internal/services/postgres"
@@ -33,12 +37,34 @@ import (
 var version = "v2"
 
 func main() {
-	logger, _ := zap.NewProduction()
-	defer logger.Sync()
+	initOnly := flag.Bool("init", false, "create the DATA_DIR skeleton, verify it is writable, and exit")
+	flag.Parse()
 
 	cfg, err := config.Load()
 	if err != nil {
-		logger.Fatal("Failed to load configuration", zap.Error(err))
+		fallback, _ := zap.NewProduction()
+		fallback.Fatal("Failed to load configuration", zap.Error(err))
+	}
+
+	logger, logLevel, err := newLogger(cfg.LogLevel, cfg.LogFormat)
+	if logger == nil {
+		fallback, _ := zap.NewProduction()
+		fallback.Fatal("Failed to build logger", zap.Error(err))
+	}
+	if err != nil {
+		logger.Warn("Invalid log configuration, using defaults", zap.Error(err))
+	}
+	defer logger.Sync()
+	if cfg.ConfigFile != "" {
+		logger.Info("Loaded config file (env vars override it)", zap.String("path", cfg.ConfigFile))
+	}
+
+	if err := initDataDir(cfg.DataDir); err != nil {
+		logger.Fatal("Failed to initialize data directory", zap.Error(err))
+	}
+	if *initOnly {
+		logger.Info("Data directory initialized", zap.String("data_dir", cfg.DataDir))
+		return
 	}
 
 	// Credential store (encrypted with CREDENTIAL_KEY env var; nil = read-only fallback)
@@ -76,6 +102,19 @@ func main() {
 	// Service-plane bootstrap + long-lived provisioners (Flow G + Plan 3b wiring).
 	// dockerCli stays alive for the lifetime of the process so the runner's
 	// provisioners and the services-status handler can reuse it.
+	dockerOpts := docker.Options{
+		Host:       cfg.DockerHost,
+		APIVersion: cfg.DockerAPIVersion,
+		CertPath:   cfg.DockerCertPath,
+		Platform:   cfg.DockerPlatform,
+	}
+	if cfg.DockerHost != "" {
+		logger.Info("Using configured docker host", zap.String("host", cfg.DockerHost),
+			zap.Bool("tls", cfg.DockerCertPath != ""))
+	}
+	if cfg.DockerPlatform != "" {
+		logger.Info("Pulling images for configured platform", zap.String("platform", cfg.DockerPlatform))
+	}
 	var pgProvisioner *postgres.Provisioner
 	var rdProvisioner *redis.Provisioner
 	var dockerCli *docker.Client
@@ -83,22 +122,31 @@ func main() {
 		logger.Warn("Service-plane skipped: credential store unavailable")
 	} else {
 		var derr error
-		dockerCli, derr = docker.NewClient()
+		dockerCli, derr = docker.NewClientWithOptions(dockerOpts)
 		if derr != nil {
 			logger.Error("Service-plane: docker client init failed", zap.Error(derr))
 			dockerCli = nil
 		} else {
 			defer func() { _ = dockerCli.Close() }()
+			dockerCli.SetTimeouts(cfg.DockerOpTimeout, cfg.DockerPullTimeout)
+			dockerCli.SetMaxConcurrentOps(cfg.DockerMaxConcurrentOps)
+			dockerCli.SetMaxConcurrentPulls(cfg.DockerMaxConcurrentPulls)
 			pgProvisioner = postgres.New(realdocker.NewPostgres(dockerCli), credStore, logger)
 			rdProvisioner = redis.New(realdocker.NewRedis(dockerCli), credStore, logger)
+			pgProvisioner.SetInit(cfg.DefaultInit)
+			rdProvisioner.SetInit(cfg.DefaultInit)
 
+			// Each service gets its own deadline so retries on postgres
+			// can't starve redis.
 			bootstrapCtx, bootstrapCancel := context.WithTimeout(context.Background(), 2*time.Minute)
-			if err := pgProvisioner.EnsureService(bootstrapCtx); err != nil {
+			if err := ensureWithRetry(bootstrapCtx, "postgres", pgProvisioner.EnsureService, logger); err != nil {
 				logger.Error("Service-plane bootstrap: postgres failed", zap.Error(err))
 			} else {
 				logger.Info("Service-plane: paas-postgres ready")
 			}
-			if err := rdProvisioner.EnsureService(bootstrapCtx); err != nil {
+			bootstrapCancel()
+			bootstrapCtx, bootstrapCancel = context.WithTimeout(context.Background(), 2*time.Minute)
+			if err := ensureWithRetry(bootstrapCtx, "redis", rdProvisioner.EnsureService, logger); err != nil {
 				logger.Error("Service-plane bootstrap: redis failed", zap.Error(err))
 			} else {
 				logger.Info("Service-plane: paas-redis ready")
@@ -113,6 +161,7 @@ func main() {
 	if err != nil {
 		logger.Fatal("Failed to initialize repos manager", zap.Error(err))
 	}
+	reposManager.SetCloneDepth(cfg.GitCloneDepth)
 
 	// Projects store + reconcile state from previous boot
 	projectsStore, err := projects.NewStore(cfg.DataDir)
@@ -120,6 +169,24 @@ func main() {
 		logger.Fatal("Failed to initialize projects store", zap.Error(err))
 	}
 
+	if migrated, err := projects.MigrateSchema(projectsStore, logger); err != nil {
+		logger.Fatal("Failed to migrate projects store schema", zap.Error(err))
+	} else if migrated > 0 {
+		logger.Info("Migrated store rows to current schema",
+			zap.Int("count", migrated), zap.Int("schema_version", models.SchemaVersion))
+	}
+
+	// Activity feed. Best-effort like the per-env event files: without it
+	// the server still runs, GET /activity just answers 503.
+	activityLog, err := activity.Open(cfg.DataDir + "/activity.jsonl")
+	if err != nil {
+		logger.Warn("Activity log unavailable", zap.Error(err))
+	} else {
+		projectsStore.OnEnvEvent(func(ev models.EnvEvent) {
+			_ = activityLog.Record(activity.FromEnvEvent(ev))
+		})
+	}
+
 	if reconciled, err := projects.MarkStuckBuildsFailed(projectsStore); err != nil {
 		logger.Error("Failed to reconcile stuck builds", zap.Error(err))
 	} else if reconciled > 0 {
@@ -128,7 +195,7 @@ func main() {
 
 	// Build runner
 	buildQueue := builder.NewQueue()
-	buildExec := builder.DockerComposeExecutor{}
+	buildExec := builder.DockerComposeExecutor{Env: dockerOpts.Env()}
 	buildRunner := builder.NewRunner(projectsStore, buildExec, cfg.DataDir, cfg.ProxyNetwork, buildQueue, logger, credStore)
 
 	if pgProvisioner != nil && rdProvisioner != nil {
@@ -142,7 +209,14 @@ func main() {
 		buildRunner.SetServiceProvisioners(nil, &rdRunnerAdapter{p: rdProvisioner})
 	}
 
-	buildRunner.SetLetsencryptEmail(cfg.LetsencryptEmail)
+	if dockerCli != nil {
+		buildRunner.SetPullLimiter(dockerCli)
+		buildRunner.SetContainerLister(dockerCli)
+	}
+
+	applyRunnerSettings(buildRunner, cfg)
+
+	projectsStore.SetFetchRetry(projects.FetchRetry{Attempts: cfg.GitFetchAttempts, Timeout: cfg.GitFetchTimeout})
 
 	// Branch reconcile (fetch origin per project, spawn missing previews, tear down gone branches)
 	spawner := &reconcileSpawner{
@@ -166,6 +240,47 @@ func main() {
 		logger.Info("Reconcile complete", zap.Strings("changes", summaries))
 	}
 
+	// Remote connectivity check. Reconcile's fetch failures are easy to miss
+	// among other boot output; call out unreachable remotes explicitly.
+	go checkProjectRemotes(projectsStore, gitTokenFn, logger)
+
+	// A drain (POST /admin/drain, or STOP_ON_SHUTDOWN on the way down)
+	// stopped envs' containers; bring them back now the host is up.
+	if drained, err := buildRunner.Drained(); err != nil {
+		logger.Warn("Read drain record failed", zap.Error(err))
+	} else if drained != nil {
+		go func() {
+			state, err := buildRunner.Undrain(context.Background())
+			if err != nil {
+				logger.Error("Undrain at startup failed", zap.Error(err))
+				return
+			}
+			logger.Info("Undrained envs stopped before restart",
+				zap.Int("started", len(state.Envs)), zap.Any("failed", state.Failed))
+		}()
+	}
+
+	// Resource alerts: a running env container that stays near its memory
+	// or CPU limit lands in the activity feed (and the log) before it gets
+	// OOM-killed.
+	if dockerCli != nil && cfg.ResourceAlertInterval > 0 {
+		mon := monitor.New(dockerCli, projectsStore, monitor.Thresholds{
+			MemPercent: cfg.ResourceAlertMemPercent,
+			MemBytes:   uint64(cfg.ResourceAlertMemBytes),
+			CPUPercent: cfg.ResourceAlertCPUPercent,
+			For:        cfg.ResourceAlertFor,
+		}, func(a monitor.Alert) {
+			logger.Warn("Container near resource limit", zap.String("env", a.EnvID),
+				zap.String("service", a.Service), zap.String("resource", a.Resource), zap.String("detail", a.Message))
+			if activityLog != nil {
+				_ = activityLog.Record(activity.Entry{Type: activity.TypeAlert, Subject: a.EnvID, Message: a.Message})
+			}
+		}, logger)
+		monitorCtx, monitorCancel := context.WithCancel(context.Background())
+		defer monitorCancel()
+		go mon.Run(monitorCtx, cfg.ResourceAlertInterval)
+	}
+
 	// License watcher. Enforce=false (default) makes this a no-op that
 	// always reports valid. Enforce=true reads + verifies cfg.LicenseFile
 	// at boot and re-checks it hourly.
@@ -175,6 +290,17 @@ func main() {
 	defer licenseCancel()
 	go licenseWatcher.Run(licenseCtx, time.Hour)
 
+	// Config file hot reload. Fields in config.Reloadable apply live; any
+	// other change is logged as needing a restart.
+	if cfg.ConfigFile != "" {
+		reloadCtx, reloadCancel := context.WithCancel(context.Background())
+		defer reloadCancel()
+		reloader := &configReloader{current: cfg, level: logLevel, runner: buildRunner, logger: logger}
+		go config.Watch(reloadCtx, cfg.ConfigFile, 5*time.Second, reloader.apply, func(err error) {
+			logger.Error("Config reload failed; keeping previous settings", zap.Error(err))
+		})
+	}
+
 	// Router
 	router := api.NewRouter(api.RouterConfig{
 		ReposManager:     reposManager,
@@ -188,9 +314,19 @@ func main() {
 		Logger:           logger,
 		DockerClient:     dockerCli,
 		DockerLogStream:  dockerCli,
-		LetsencryptEmail: cfg.LetsencryptEmail,
+		DockerContainers: dockerCli,
+		DockerTop:        dockerCli,
+		DockerRestarter:  dockerCli,
+		DockerNetworks:   dockerCli,
 		Version:          version,
 		License:          licenseWatcher,
+		RateLimit:        cfg.APIRateLimit,
+		RateBurst:        cfg.APIRateBurst,
+		TrustedProxies:   cfg.TrustedProxies,
+		MaxStreams:       cfg.WSMaxConnections,
+		Activity:         activityLog,
+		Spawner:          spawner,
+		BasePath:         cfg.BasePath,
 	})
 
 	server := &http.Server{
@@ -202,7 +338,7 @@ func main() {
 	}
 
 	go func() {
-		logger.Info("Starting server", zap.Int("port", cfg.Port))
+		logger.Info("Starting server", zap.Int("port", cfg.Port), zap.String("base_path", cfg.BasePath))
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			logger.Fatal("Server failed", zap.Error(err))
 		}
@@ -221,9 +357,42 @@ func main() {
 		logger.Fatal("Server forced to shutdown", zap.Error(err))
 	}
 
+	if cfg.StopOnShutdown {
+		drainCtx, drainCancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		if state, err := buildRunner.Drain(drainCtx); err != nil {
+			logger.Error("STOP_ON_SHUTDOWN: drain failed", zap.Error(err))
+		} else {
+			logger.Info("STOP_ON_SHUTDOWN: envs stopped",
+				zap.Int("stopped", len(state.Envs)), zap.Any("failed", state.Failed))
+		}
+		drainCancel()
+	}
+
 	logger.Info("Server stopped")
 }
 
+// checkProjectRemotes runs `git ls-remote` against every project's origin
+// and warns about any that can't be reached, so a broken remote or revoked
+// token surfaces at boot rather than on the next webhook.
+func checkProjectRemotes(store *projects.Store, gitToken func() string, logger *zap.Logger) {
+	list, err := store.ListProjects()
+	if err != nil {
+		return
+	}
+	for _, p := range list {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		out, err := projects.CheckRemote(ctx, p.LocalPath, gitToken())
+		cancel()
+		if err != nil {
+			logger.Warn("git remote unreachable",
+				zap.String("project", p.ID),
+				zap.String("repo_url", p.RepoURL),
+				zap.String("out", strings.TrimSpace(string(out))),
+				zap.Error(err))
+		}
+	}
+}
+
 // reconcileSpawner wires projects.ReconcileBranches to the actual Store +
 // Runner. Lives in main rather than projects to keep the projects package
 // import-free of builder.
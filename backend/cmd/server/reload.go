@@ -0,0 +1,81 @@
+package main
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/environment-manager/backend/internal/builder"
+	"github.com/environment-manager/backend/internal/config"
+)
+
+// applyRunnerSettings pushes the runner's host-wide settings from cfg. Used
+// at boot and again on every config reload.
+func applyRunnerSettings(r *builder.Runner, cfg *config.Config) {
+	r.SetLetsencryptEmail(cfg.LetsencryptEmail)
+	r.SetTraefikEntrypoints(cfg.TraefikWebEntrypoint, cfg.TraefikWebsecureEntrypoint)
+	var logOpts map[string]string
+	if builder.LogDriverRotates(cfg.ContainerLogDriver) {
+		logOpts = map[string]string{
+			"max-size": cfg.ContainerLogMaxSize,
+			"max-file": cfg.ContainerLogMaxFile,
+		}
+	}
+	r.SetComposeDefaults(builder.ComposeDefaults{
+		LogDriver:  cfg.ContainerLogDriver,
+		LogOptions: logOpts,
+		Restart:    cfg.DefaultRestartPolicy,
+		MemLimit:   cfg.DefaultMemoryLimit,
+		Init:       cfg.DefaultInit,
+		TZ:         cfg.DefaultTZ,
+	})
+}
+
+// configReloader applies a re-read config to the running server. Only
+// called from the single config.Watch goroutine, so current needs no lock.
+type configReloader struct {
+	current *config.Config
+	level   zap.AtomicLevel
+	runner  *builder.Runner
+	logger  *zap.Logger
+}
+
+// apply diffs next against the running config, hot-applies the reloadable
+// fields, and warns about the rest. config.Reloadable is the one list of
+// what's hot-applied: only those fields are adopted from next, and the
+// runner settings are pushed from the merged config, so a field missing
+// from the list can't reach the runner. Non-reloadable fields keep their
+// boot values in current, so later reloads keep listing them until a
+// restart.
+func (c *configReloader) apply(next *config.Config) {
+	changed := config.Changed(c.current, next)
+	if len(changed) == 0 {
+		return
+	}
+	var applied, restart []string
+	for _, f := range changed {
+		if config.Reloadable[f] {
+			applied = append(applied, f)
+		} else {
+			restart = append(restart, f)
+		}
+	}
+
+	if _, err := zapcore.ParseLevel(next.LogLevel); err != nil {
+		c.logger.Warn("Config reload: invalid LOG_LEVEL, keeping current level",
+			zap.String("log_level", next.LogLevel))
+		next.LogLevel = c.current.LogLevel
+	}
+	merged := config.Adopt(c.current, next)
+	if len(applied) > 0 {
+		if lvl, err := zapcore.ParseLevel(merged.LogLevel); err == nil {
+			c.level.SetLevel(lvl)
+		}
+		applyRunnerSettings(c.runner, merged)
+		c.logger.Info("Config reloaded", zap.Strings("applied", applied))
+	}
+	if len(restart) > 0 {
+		c.logger.Warn("Config reload: these settings change only after a restart",
+			zap.Strings("fields", restart))
+	}
+	c.current = merged
+}
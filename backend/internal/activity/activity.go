@@ -0,0 +1,220 @@
+// Package activity keeps the global operation log: one chronological,
+// append-only feed of what the system did — API actions, webhook
+// deliveries and environment status changes — so "what happened in the
+// last hour" has a single answer.
+package activity
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/environment-manager/backend/internal/models"
+)
+
+// Entry types.
+const (
+	TypeUser    = "user"    // an authenticated mutating or admin API call
+	TypeWebhook = "webhook" // an inbound GitHub webhook delivery
+	TypeEnv     = "env"     // an environment status transition
+	TypeAlert   = "alert"   // a container near its resource limits
+)
+
+// maxEntries caps how many entries are retained. Like the per-env event
+// files, the log is compacted back to this size once it grows past twice
+// the cap, so appends stay O(1) in the common case.
+const maxEntries = 5000
+
+// Entry is one line of the feed. Seq increases monotonically and doubles as
+// the pagination cursor.
+type Entry struct {
+	Seq     int64     `json:"seq"`
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`
+	Subject string    `json:"subject"`          // env id, request path, ...
+	Message string    `json:"message"`          // human-readable summary
+	Status  int       `json:"status,omitempty"` // HTTP status for user/webhook entries
+	Remote  string    `json:"remote,omitempty"` // client IP for user/webhook entries
+}
+
+// FromEnvEvent converts a recorded environment transition into an entry.
+func FromEnvEvent(ev models.EnvEvent) Entry {
+	msg := string(ev.To)
+	if ev.From != "" {
+		msg = string(ev.From) + " → " + msg
+	}
+	if ev.BuildID != "" {
+		msg += " (build " + ev.BuildID + ")"
+	}
+	if ev.OOMKilled {
+		msg += ", OOM-killed"
+	}
+	if ev.ExitCode != 0 {
+		msg += ", exit " + strconv.Itoa(ev.ExitCode)
+	}
+	return Entry{Time: ev.Time, Type: TypeEnv, Subject: ev.EnvID, Message: msg}
+}
+
+// Log is the persistent feed, stored as JSONL at a single path.
+type Log struct {
+	path string
+	mu   sync.Mutex
+	seq  int64
+	size int // lines currently in the file
+	now  func() time.Time
+}
+
+// Open returns a Log backed by path, creating the parent directory if
+// needed. An existing file is read once to recover the sequence counter.
+func Open(path string) (*Log, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	l := &Log{path: path, now: time.Now}
+	entries, err := readEntries(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if n := len(entries); n > 0 {
+		l.seq = entries[n-1].Seq
+		l.size = n
+	}
+	return l, nil
+}
+
+// Record appends e, stamping Seq and Time (when unset). Errors are returned
+// but callers treat the feed as best-effort: a failed append must never
+// fail the operation being recorded.
+func (l *Log) Record(e Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.seq++
+	e.Seq = l.seq
+	if e.Time.IsZero() {
+		e.Time = l.now().UTC()
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	l.size++
+
+	if l.size <= 2*maxEntries {
+		return nil
+	}
+	entries, err := readEntries(l.path)
+	if err != nil {
+		return err
+	}
+	if len(entries) > maxEntries {
+		entries = entries[len(entries)-maxEntries:]
+	}
+	if err := writeEntries(l.path, entries); err != nil {
+		return err
+	}
+	l.size = len(entries)
+	return nil
+}
+
+// Query selects a page of the feed.
+type Query struct {
+	Types  []string  // empty = every type
+	Before int64     // only entries with Seq < Before; 0 = from the newest
+	Since  time.Time // only entries at or after Since; zero = no bound
+	Limit  int       // page size; <= 0 = 50
+}
+
+// List returns matching entries newest first. next is the cursor for the
+// following (older) page — pass it as Query.Before — or 0 when there are no
+// more.
+func (l *Log) List(q Query) (entries []Entry, next int64, err error) {
+	l.mu.Lock()
+	all, err := readEntries(l.path)
+	l.mu.Unlock()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Entry{}, 0, nil
+		}
+		return nil, 0, err
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	types := make(map[string]bool, len(q.Types))
+	for _, t := range q.Types {
+		types[t] = true
+	}
+
+	out := []Entry{}
+	for i := len(all) - 1; i >= 0; i-- {
+		e := all[i]
+		if q.Before > 0 && e.Seq >= q.Before {
+			continue
+		}
+		if !q.Since.IsZero() && e.Time.Before(q.Since) {
+			break // file is chronological; everything older is out too
+		}
+		if len(types) > 0 && !types[e.Type] {
+			continue
+		}
+		if len(out) == limit {
+			return out, out[len(out)-1].Seq, nil
+		}
+		out = append(out, e)
+	}
+	return out, 0, nil
+}
+
+func readEntries(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	out := []Entry{}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var e Entry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			continue // skip a torn trailing line from a crash mid-write
+		}
+		out = append(out, e)
+	}
+	return out, sc.Err()
+}
+
+func writeEntries(path string, entries []Entry) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,98 @@
+package activity
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/environment-manager/backend/internal/models"
+)
+
+func TestLog_RecordAndList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "activity.jsonl")
+	l, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, typ := range []string{TypeUser, TypeEnv, TypeWebhook, TypeEnv, TypeUser} {
+		if err := l.Record(Entry{Type: typ, Subject: "x"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, next, err := l.List(Query{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Seq != 5 || got[1].Seq != 4 {
+		t.Fatalf("page 1 = %+v, want seq 5,4", got)
+	}
+	if next != 4 {
+		t.Fatalf("next = %d, want 4", next)
+	}
+	got, next, _ = l.List(Query{Limit: 2, Before: next})
+	if len(got) != 2 || got[0].Seq != 3 || got[1].Seq != 2 || next != 2 {
+		t.Fatalf("page 2 = %+v next=%d, want seq 3,2 next=2", got, next)
+	}
+	got, next, _ = l.List(Query{Limit: 2, Before: next})
+	if len(got) != 1 || got[0].Seq != 1 || next != 0 {
+		t.Fatalf("page 3 = %+v next=%d, want seq 1 and no cursor", got, next)
+	}
+
+	got, _, _ = l.List(Query{Types: []string{TypeEnv}})
+	if len(got) != 2 || got[0].Seq != 4 || got[1].Seq != 2 {
+		t.Errorf("type filter = %+v, want env entries 4,2", got)
+	}
+
+	// Reopening resumes the sequence.
+	l2, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = l2.Record(Entry{Type: TypeUser})
+	got, _, _ = l2.List(Query{Limit: 1})
+	if got[0].Seq != 6 {
+		t.Errorf("seq after reopen = %d, want 6", got[0].Seq)
+	}
+}
+
+func TestLog_Since(t *testing.T) {
+	l, _ := Open(filepath.Join(t.TempDir(), "activity.jsonl"))
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		_ = l.Record(Entry{Type: TypeUser, Time: base.Add(time.Duration(i) * time.Hour)})
+	}
+	got, _, _ := l.List(Query{Since: base.Add(2 * time.Hour)})
+	if len(got) != 2 {
+		t.Errorf("since filter returned %d entries, want 2", len(got))
+	}
+}
+
+func TestLog_Compacts(t *testing.T) {
+	l, _ := Open(filepath.Join(t.TempDir(), "activity.jsonl"))
+	for i := 0; i < 2*maxEntries+1; i++ {
+		_ = l.Record(Entry{Type: TypeUser})
+	}
+	if l.size != maxEntries {
+		t.Fatalf("size after compaction = %d, want %d", l.size, maxEntries)
+	}
+	got, _, _ := l.List(Query{Limit: 1})
+	if got[0].Seq != 2*maxEntries+1 {
+		t.Errorf("newest seq = %d, want %d", got[0].Seq, 2*maxEntries+1)
+	}
+}
+
+func TestFromEnvEvent(t *testing.T) {
+	e := FromEnvEvent(models.EnvEvent{EnvID: "p1--main", From: models.EnvStatusBuilding, To: models.EnvStatusRunning, BuildID: "b1"})
+	if e.Type != TypeEnv || e.Subject != "p1--main" {
+		t.Errorf("entry = %+v", e)
+	}
+	if e.Message != "building → running (build b1)" {
+		t.Errorf("message = %q", e.Message)
+	}
+
+	e = FromEnvEvent(models.EnvEvent{EnvID: "p1--main", From: models.EnvStatusBuilding, To: models.EnvStatusFailed, ExitCode: 137, OOMKilled: true})
+	if e.Message != "building → failed, OOM-killed, exit 137" {
+		t.Errorf("message = %q", e.Message)
+	}
+}
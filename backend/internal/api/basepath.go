@@ -0,0 +1,47 @@
+package api
+
+import (
+	"html"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// withBasePath serves h under base (e.g. "/envmgr"), stripping the prefix
+// so routes and static paths match as if mounted at the root. A bare
+// request for base redirects to base + "/". Requests outside base 404.
+// An empty base returns h unchanged.
+func withBasePath(base string, h http.Handler) http.Handler {
+	if base == "" {
+		return h
+	}
+	mux := http.NewServeMux()
+	mux.Handle(base+"/", http.StripPrefix(base, h))
+	mux.Handle(base, http.RedirectHandler(base+"/", http.StatusMovedPermanently))
+	return mux
+}
+
+var headTag = regexp.MustCompile(`(?i)<head[^>]*>`)
+
+// serveIndex writes the SPA shell with a <base href> for base injected
+// right after <head>. The frontend is built with relative asset URLs and
+// reads the base element to prefix its API calls and client-side routes,
+// so the same build works at the domain root and under any BASE_PATH.
+func serveIndex(w http.ResponseWriter, r *http.Request, staticDir, base string) {
+	data, err := os.ReadFile(filepath.Join(staticDir, "index.html"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	tag := `<base href="` + html.EscapeString(base+"/") + `">`
+	if loc := headTag.FindIndex(data); loc != nil {
+		out := make([]byte, 0, len(data)+len(tag))
+		out = append(out, data[:loc[1]]...)
+		out = append(out, tag...)
+		data = append(out, data[loc[1]:]...)
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	_, _ = w.Write(data)
+}
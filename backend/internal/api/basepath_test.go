@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWithBasePath(t *testing.T) {
+	static := t.TempDir()
+	if err := os.WriteFile(filepath.Join(static, "index.html"), []byte("<html><head><title>x</title></head></html>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	inner := http.NewServeMux()
+	inner.HandleFunc("/api/v1/health", func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write([]byte("ok")) })
+	inner.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) { serveIndex(w, r, static, "/envmgr") })
+	h := withBasePath("/envmgr", inner)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		return rec
+	}
+	if rec := get("/envmgr/api/v1/health"); rec.Code != http.StatusOK || rec.Body.String() != "ok" {
+		t.Errorf("prefixed API route: %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := get("/api/v1/health"); rec.Code != http.StatusNotFound {
+		t.Errorf("unprefixed route: status = %d, want 404", rec.Code)
+	}
+	if rec := get("/envmgr"); rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "/envmgr/" {
+		t.Errorf("bare base: %d -> %q", rec.Code, rec.Header().Get("Location"))
+	}
+	rec := get("/envmgr/projects/p1")
+	if !strings.Contains(rec.Body.String(), `<head><base href="/envmgr/">`) {
+		t.Errorf("SPA shell missing base href: %s", rec.Body.String())
+	}
+
+	if withBasePath("", inner) != http.Handler(inner) {
+		t.Error("empty base should return the handler unchanged")
+	}
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/environment-manager/backend/internal/activity"
+)
+
+// maxActivityLimit bounds one page of GET /activity.
+const maxActivityLimit = 500
+
+// ActivityHandler serves the global operation log.
+type ActivityHandler struct {
+	log *activity.Log // nil = List returns 503
+}
+
+// NewActivityHandler wires the handler. log may be nil.
+func NewActivityHandler(log *activity.Log) *ActivityHandler {
+	return &ActivityHandler{log: log}
+}
+
+// ActivityPage is the GET /api/v1/activity response.
+type ActivityPage struct {
+	Entries []activity.Entry `json:"entries"`
+	// NextBefore is the cursor for the next (older) page; 0 = no more.
+	NextBefore int64 `json:"next_before,omitempty"`
+}
+
+// List handles GET /api/v1/activity?type=user,env&since=1h&before=<seq>&limit=N.
+//
+// Returns the feed newest first. type filters by a comma-separated list of
+// entry types; since accepts a Go duration ("1h") or RFC 3339; before is
+// the next_before cursor from the previous page.
+func (h *ActivityHandler) List(w http.ResponseWriter, r *http.Request) {
+	if h.log == nil {
+		respondError(w, http.StatusServiceUnavailable, "ACTIVITY_UNAVAILABLE", "activity log unavailable")
+		return
+	}
+	qv := r.URL.Query()
+	var q activity.Query
+	if v := qv.Get("type"); v != "" {
+		for _, t := range strings.Split(v, ",") {
+			t = strings.TrimSpace(t)
+			switch t {
+			case activity.TypeUser, activity.TypeWebhook, activity.TypeEnv, activity.TypeAlert:
+				q.Types = append(q.Types, t)
+			default:
+				respondError(w, http.StatusBadRequest, "INVALID_TYPE", "unknown activity type: "+t)
+				return
+			}
+		}
+	}
+	if v := qv.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxActivityLimit {
+			respondError(w, http.StatusBadRequest, "INVALID_LIMIT", "limit must be between 1 and "+strconv.Itoa(maxActivityLimit))
+			return
+		}
+		q.Limit = n
+	}
+	if v := qv.Get("before"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 1 {
+			respondError(w, http.StatusBadRequest, "INVALID_CURSOR", "before must be a positive integer")
+			return
+		}
+		q.Before = n
+	}
+	since, err := parseLogTime(qv.Get("since"), time.Now())
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_SINCE", "since must be a duration (1h) or RFC 3339 time")
+		return
+	}
+	q.Since = since
+
+	entries, next, err := h.log.List(q)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "ACTIVITY_ERROR", err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(ActivityPage{Entries: entries, NextBefore: next})
+}
+
+// RecordActivity returns a middleware that appends one entry of type typ
+// per request to log, after the handler has run, with the method, path and
+// response status. Recording is best-effort. log nil disables it.
+func RecordActivity(log *activity.Log, typ string) func(http.Handler) http.Handler {
+	if log == nil {
+		return func(h http.Handler) http.Handler { return h }
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			msg := r.Method + " " + r.URL.Path
+			if typ == activity.TypeWebhook {
+				if ev := r.Header.Get("X-GitHub-Event"); ev != "" {
+					msg = "github " + ev + " delivery " + r.Header.Get("X-GitHub-Delivery")
+				}
+			}
+			_ = log.Record(activity.Entry{
+				Type:    typ,
+				Subject: r.URL.Path,
+				Message: strings.TrimSpace(msg),
+				Status:  status,
+				Remote:  hostOnly(r.RemoteAddr),
+			})
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/environment-manager/backend/internal/activity"
+)
+
+func TestRecordActivity_RecordsRequests(t *testing.T) {
+	log, err := activity.Open(filepath.Join(t.TempDir(), "activity.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mw := RecordActivity(log, activity.TypeUser)
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		respondError(w, http.StatusConflict, "PROTECTED", "nope")
+	}))
+	req := httptest.NewRequest("DELETE", "/api/v1/projects/p1", nil)
+	req.RemoteAddr = "10.0.0.5:4242"
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	got, _, _ := log.List(activity.Query{})
+	if len(got) != 1 {
+		t.Fatalf("entries = %d, want 1", len(got))
+	}
+	e := got[0]
+	if e.Type != activity.TypeUser || e.Message != "DELETE /api/v1/projects/p1" || e.Status != http.StatusConflict || e.Remote != "10.0.0.5" {
+		t.Errorf("entry = %+v", e)
+	}
+}
+
+func TestActivityHandler_List(t *testing.T) {
+	log, _ := activity.Open(filepath.Join(t.TempDir(), "activity.jsonl"))
+	for _, typ := range []string{activity.TypeUser, activity.TypeEnv, activity.TypeEnv} {
+		_ = log.Record(activity.Entry{Type: typ, Subject: "p1--main"})
+	}
+	h := NewActivityHandler(log)
+
+	rec := httptest.NewRecorder()
+	h.List(rec, httptest.NewRequest("GET", "/api/v1/activity?type=env&limit=1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body=%s", rec.Code, rec.Body.String())
+	}
+	var page ActivityPage
+	if err := json.NewDecoder(rec.Body).Decode(&page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Entries) != 1 || page.Entries[0].Seq != 3 || page.NextBefore != 3 {
+		t.Errorf("page = %+v, want seq 3 with cursor 3", page)
+	}
+
+	for _, q := range []string{"type=bogus", "limit=0", "before=x", "since=yesterday"} {
+		rec := httptest.NewRecorder()
+		h.List(rec, httptest.NewRequest("GET", "/api/v1/activity?"+q, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", q, rec.Code)
+		}
+	}
+}
+
+func TestActivityHandler_Unavailable(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewActivityHandler(nil).List(rec, httptest.NewRequest("GET", "/api/v1/activity", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", rec.Code)
+	}
+}
@@ -60,6 +60,11 @@ type TriggerBuildResponse struct {
 
 // Trigger handles POST /api/v1/envs/{id}/build. Build runs asynchronously;
 // the response returns 202 Accepted with the build ID.
+//
+// An optional body {"profiles": ["debug", ...]} sets the compose profiles
+// to activate. They're saved on the project, so later builds and reconciles
+// bring up the same set; send "profiles": [] to clear them. Omitting the
+// field (or the body) keeps whatever is stored.
 func (h *BuildsHandler) Trigger(w http.ResponseWriter, r *http.Request) {
 	envID := chi.URLParam(r, "id")
 	projectID, branchSlug, ok := splitEnvID(envID)
@@ -77,6 +82,35 @@ func (h *BuildsHandler) Trigger(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var body struct {
+		Profiles []string `json:"profiles"` // nil = absent, keep stored set
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		respondError(w, http.StatusBadRequest, "INVALID_BODY", err.Error())
+		return
+	}
+	if body.Profiles != nil {
+		for _, p := range body.Profiles {
+			if !builder.ValidProfile(p) {
+				respondError(w, http.StatusBadRequest, "INVALID_PROFILE", "invalid compose profile: "+p)
+				return
+			}
+		}
+		project, err := h.store.GetProject(projectID)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "STORE_ERROR", err.Error())
+			return
+		}
+		project.Profiles = body.Profiles
+		if len(project.Profiles) == 0 {
+			project.Profiles = nil
+		}
+		if err := h.store.SaveProject(project); err != nil {
+			respondError(w, http.StatusInternalServerError, "STORE_ERROR", err.Error())
+			return
+		}
+	}
+
 	now := time.Now().UTC()
 	build := &models.Build{
 		ID:          uuid.NewString(),
@@ -112,6 +146,31 @@ func (h *BuildsHandler) runBuild(env *models.Environment, b *models.Build) {
 	}
 }
 
+// Cancel handles POST /api/v1/builds/{id}/cancel. Aborts a queued or
+// running build — including an in-flight image pull or `docker compose
+// build` — and returns 202; the build record flips to "cancelled" once the
+// pipeline unwinds. 409 when the build isn't active.
+func (h *BuildsHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	buildID := chi.URLParam(r, "id")
+	if buildID == "" {
+		respondError(w, http.StatusBadRequest, "INVALID_BUILD_ID", "build id required")
+		return
+	}
+	if h.runner == nil {
+		respondError(w, http.StatusServiceUnavailable, "RUNNER_UNAVAILABLE", "build runner unavailable")
+		return
+	}
+	if !h.runner.Cancel(buildID) {
+		respondError(w, http.StatusConflict, "BUILD_NOT_RUNNING", "build is not queued or running")
+		return
+	}
+	respondJSON(w, http.StatusAccepted, Response{
+		Success: true,
+		Data:    map[string]string{"build_id": buildID, "status": "cancelling"},
+		Meta:    &Meta{Timestamp: time.Now()},
+	})
+}
+
 // List handles GET /api/v1/envs/{id}/builds — returns the env's build history,
 // most-recent first. Build records include status, SHA, timestamps, log path.
 func (h *BuildsHandler) List(w http.ResponseWriter, r *http.Request) {
@@ -191,18 +250,25 @@ func (h *BuildsHandler) StreamLogs(w http.ResponseWriter, r *http.Request) {
 	}
 	defer f.Close()
 
+	gone, stop := wsKeepalive(conn)
+	defer stop()
+
 	buf := make([]byte, 4096)
 	for {
 		n, err := f.Read(buf)
 		if n > 0 {
-			if werr := conn.WriteMessage(websocket.TextMessage, buf[:n]); werr != nil {
+			if werr := wsWrite(conn, buf[:n]); werr != nil {
 				return
 			}
 		}
 		if err == io.EOF {
 			cur, _ := h.store.GetEnvironment(env.ProjectID, env.BranchSlug)
 			if cur != nil && cur.Status == models.EnvStatusBuilding {
-				time.Sleep(200 * time.Millisecond)
+				select {
+				case <-gone:
+					return
+				case <-time.After(200 * time.Millisecond):
+				}
 				continue
 			}
 			return
@@ -245,39 +311,66 @@ func (h *BuildsHandler) latestBuildLogPath(env *models.Environment) string {
 // Open GET (no Bearer required) — matches the v2 design where read-only
 // project endpoints are LAN-anonymous.
 func (h *BuildsHandler) GetLog(w http.ResponseWriter, r *http.Request) {
+	b, ok := h.lookupBuild(w, r)
+	if !ok {
+		return
+	}
+	h.serveBuildFile(w, b.LogPath, "build has no log path", "log file unavailable: ")
+}
+
+// GetCompose handles GET /api/v1/builds/{id}/compose. Returns the compose
+// config as `docker compose` resolved it for that build, secrets included.
+// Builds from before snapshots existed return 404.
+func (h *BuildsHandler) GetCompose(w http.ResponseWriter, r *http.Request) {
+	b, ok := h.lookupBuild(w, r)
+	if !ok {
+		return
+	}
+	h.serveBuildFile(w, b.ComposePath, "build has no compose snapshot", "compose snapshot unavailable: ")
+}
+
+// lookupBuild resolves the {id} URL param to a build record, writing the
+// error response itself when it can't. Walks the store to find the build
+// by ID — O(N) over all builds across all projects, which is acceptable
+// for low-volume home-lab use.
+func (h *BuildsHandler) lookupBuild(w http.ResponseWriter, r *http.Request) (*models.Build, bool) {
 	buildID := chi.URLParam(r, "id")
 	if buildID == "" {
 		http.Error(w, "build id required", http.StatusBadRequest)
-		return
+		return nil, false
 	}
 	allProjects, err := h.store.ListProjects()
 	if err != nil {
 		http.Error(w, "store error: "+err.Error(), http.StatusInternalServerError)
-		return
+		return nil, false
 	}
 	for _, p := range allProjects {
 		envs, _ := h.store.ListEnvironments(p.ID)
 		for _, e := range envs {
 			builds, _ := h.store.ListBuildsForEnv(p.ID, e.ID)
 			for _, b := range builds {
-				if b.ID != buildID {
-					continue
-				}
-				path := b.LogPath
-				if path == "" {
-					http.Error(w, "build has no log path", http.StatusNotFound)
-					return
+				if b.ID == buildID {
+					return b, true
 				}
-				data, ferr := os.ReadFile(path)
-				if ferr != nil {
-					http.Error(w, "log file unavailable: "+ferr.Error(), http.StatusInternalServerError)
-					return
-				}
-				w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-				_, _ = w.Write(data)
-				return
 			}
 		}
 	}
 	http.Error(w, "build not found", http.StatusNotFound)
+	return nil, false
+}
+
+// serveBuildFile writes the file at path as text/plain. 404 when the build
+// never recorded one, 500 when it's missing on disk.
+func (h *BuildsHandler) serveBuildFile(w http.ResponseWriter, path, noPathMsg, unavailablePrefix string) {
+	if path == "" {
+		http.Error(w, noPathMsg, http.StatusNotFound)
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		http.Error(w, unavailablePrefix+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write(data)
 }
@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -128,6 +129,53 @@ func TestBuildsHandler_Trigger_InvalidEnvID(t *testing.T) {
 	}
 }
 
+func TestBuildsHandler_Trigger_Profiles(t *testing.T) {
+	h, store, dataDir := newBuildsHandlerTest(t)
+	_ = store.SaveProject(&models.Project{ID: "p1", Name: "myapp", LocalPath: filepath.Join(dataDir, "repo")})
+	_ = store.SaveEnvironment(&models.Environment{
+		ID: "p1--main", ProjectID: "p1", Branch: "main", BranchSlug: "main",
+		Kind: models.EnvKindProd, ComposeFile: ".dev/docker-compose.prod.yml",
+	})
+	trigger := func(body string) *httptest.ResponseRecorder {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "p1--main")
+		req := httptest.NewRequest("POST", "/api/v1/envs/p1--main/build", strings.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		rec := httptest.NewRecorder()
+		h.Trigger(rec, req)
+		return rec
+	}
+
+	if rec := trigger(`{"profiles":["debug","--rm"]}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid profile: status = %d, want 400", rec.Code)
+	}
+	if p, _ := store.GetProject("p1"); len(p.Profiles) != 0 {
+		t.Fatalf("rejected request stored profiles: %v", p.Profiles)
+	}
+
+	rec := trigger(`{"profiles":["debug"]}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202; body=%s", rec.Code, rec.Body.String())
+	}
+	if p, _ := store.GetProject("p1"); len(p.Profiles) != 1 || p.Profiles[0] != "debug" {
+		t.Errorf("stored profiles = %v, want [debug]", p.Profiles)
+	}
+	// Let the async build (it fails: no compose file) finish before the
+	// temp dir is removed.
+	var body struct {
+		Data TriggerBuildResponse `json:"data"`
+	}
+	_ = json.NewDecoder(rec.Body).Decode(&body)
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if b, err := store.GetBuild("p1", body.Data.BuildID); err == nil && b.Status != models.BuildStatusRunning {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatal("timeout waiting for build to finish")
+}
+
 func TestBuildsHandler_List(t *testing.T) {
 	dir := t.TempDir()
 	store, _ := projects.NewStore(dir)
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// DataDirHandler exposes a read-only view of the data directory so state
+// issues can be debugged without shell access to the host. Admin-only: the
+// tree reveals every project and env on the box.
+//
+// Excluded from both endpoints: the encrypted credential store, the
+// license file, and repos/ (full git clones — use the project endpoints).
+type DataDirHandler struct {
+	dataDir string
+}
+
+// NewDataDirHandler wires the handler.
+func NewDataDirHandler(dataDir string) *DataDirHandler {
+	return &DataDirHandler{dataDir: dataDir}
+}
+
+// DataDirEntry is one node of the GET /admin/data/tree response.
+type DataDirEntry struct {
+	Path  string `json:"path"` // slash-separated, relative to dataDir
+	Dir   bool   `json:"dir"`
+	Size  int64  `json:"size,omitempty"`
+	MTime string `json:"mtime"`
+}
+
+// dataDirViewableExt lists the extensions GET /admin/data/file will serve.
+var dataDirViewableExt = map[string]bool{
+	".yaml": true, ".yml": true, ".json": true, ".jsonl": true, ".log": true,
+}
+
+// dataDirSecretLine matches `key: value` / `"key": value` lines whose key
+// looks sensitive, for redaction in served files.
+var dataDirSecretLine = regexp.MustCompile(`(?i)^(\s*"?[\w.-]*(password|secret|token|private_key)[\w.-]*"?\s*:\s*)(.+)$`)
+
+// hiddenFromDataView reports whether rel (slash-separated, relative to
+// dataDir) must never be listed or served.
+func hiddenFromDataView(rel string) bool {
+	first := strings.SplitN(rel, "/", 2)[0]
+	switch {
+	case first == "repos":
+		return true
+	case strings.HasPrefix(first, ".credentials"):
+		return true
+	case strings.HasSuffix(first, ".lic"):
+		return true
+	case strings.HasSuffix(rel, ".tar.gz") || strings.HasSuffix(rel, ".tar.gz.partial"):
+		return true // backups left in dataDir by the operator
+	}
+	return false
+}
+
+// Tree handles GET /api/v1/admin/data/tree.
+func (h *DataDirHandler) Tree(w http.ResponseWriter, r *http.Request) {
+	entries := []DataDirEntry{}
+	err := filepath.Walk(h.dataDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil // unreadable subtree — skip rather than fail the listing
+		}
+		rel, rerr := filepath.Rel(h.dataDir, path)
+		if rerr != nil || rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if hiddenFromDataView(rel) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		e := DataDirEntry{Path: rel, Dir: info.IsDir(), MTime: info.ModTime().UTC().Format("2006-01-02T15:04:05Z")}
+		if !info.IsDir() {
+			e.Size = info.Size()
+		}
+		entries = append(entries, e)
+		return nil
+	})
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "WALK_FAILED", err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(entries)
+}
+
+// File handles GET /api/v1/admin/data/file?path=<rel>. Serves one state
+// file as text/plain with sensitive-looking values redacted. Rejects paths
+// escaping dataDir, hidden paths, and non-text extensions.
+func (h *DataDirHandler) File(w http.ResponseWriter, r *http.Request) {
+	raw := r.URL.Query().Get("path")
+	if raw == "" {
+		respondError(w, http.StatusBadRequest, "MISSING_PATH", "path query param required")
+		return
+	}
+	rel := filepath.ToSlash(filepath.Clean(raw))
+	if filepath.IsAbs(raw) || rel == ".." || strings.HasPrefix(rel, "../") {
+		respondError(w, http.StatusBadRequest, "INVALID_PATH", "path must be relative to the data directory")
+		return
+	}
+	if hiddenFromDataView(rel) || !dataDirViewableExt[filepath.Ext(rel)] {
+		respondError(w, http.StatusForbidden, "FORBIDDEN_PATH", "file not viewable")
+		return
+	}
+	full := filepath.Join(h.dataDir, filepath.FromSlash(rel))
+	// Resolve symlinks so a link inside dataDir can't point outside it.
+	resolved, err := filepath.EvalSymlinks(full)
+	if err != nil {
+		if os.IsNotExist(err) {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "file not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "READ_FAILED", err.Error())
+		return
+	}
+	root, err := filepath.EvalSymlinks(h.dataDir)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "READ_FAILED", err.Error())
+		return
+	}
+	if inside, _ := filepath.Rel(root, resolved); inside == ".." || strings.HasPrefix(inside, ".."+string(filepath.Separator)) {
+		respondError(w, http.StatusBadRequest, "INVALID_PATH", "path must be relative to the data directory")
+		return
+	}
+	data, err := os.ReadFile(resolved)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "READ_FAILED", err.Error())
+		return
+	}
+	lines := strings.Split(string(data), "\n")
+	for i, l := range lines {
+		lines[i] = dataDirSecretLine.ReplaceAllString(l, "${1}<redacted>")
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte(strings.Join(lines, "\n")))
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newDataDirFixture(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for path, content := range map[string]string{
+		"projects/p1/project.yaml": "id: p1\nname: myapp\n",
+		"projects/p1/notes.yaml":   "db_password: hunter2\ntoken: abc\nname: ok\n",
+		".credentials":             "encrypted-blob",
+		"license.lic":              "signed",
+		"repos/p1/README.md":       "clone",
+	} {
+		full := filepath.Join(dir, path)
+		_ = os.MkdirAll(filepath.Dir(full), 0o755)
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestDataDir_TreeExcludesSensitive(t *testing.T) {
+	h := NewDataDirHandler(newDataDirFixture(t))
+	rec := httptest.NewRecorder()
+	h.Tree(rec, httptest.NewRequest("GET", "/api/v1/admin/data/tree", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	var entries []DataDirEntry
+	_ = json.Unmarshal(rec.Body.Bytes(), &entries)
+	seen := map[string]bool{}
+	for _, e := range entries {
+		seen[e.Path] = true
+	}
+	if !seen["projects/p1/project.yaml"] {
+		t.Errorf("expected project.yaml in tree: %v", entries)
+	}
+	for _, hidden := range []string{".credentials", "license.lic", "repos", "repos/p1/README.md"} {
+		if seen[hidden] {
+			t.Errorf("%s must not be listed", hidden)
+		}
+	}
+}
+
+func TestDataDir_File(t *testing.T) {
+	h := NewDataDirHandler(newDataDirFixture(t))
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.File(rec, httptest.NewRequest("GET", "/api/v1/admin/data/file?path="+path, nil))
+		return rec
+	}
+
+	rec := get("projects/p1/notes.yaml")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d; body = %s", rec.Code, rec.Body.String())
+	}
+	body := rec.Body.String()
+	if strings.Contains(body, "hunter2") || strings.Contains(body, "abc") {
+		t.Errorf("secrets not redacted:\n%s", body)
+	}
+	if !strings.Contains(body, "name: ok") {
+		t.Errorf("non-sensitive line altered:\n%s", body)
+	}
+
+	for path, want := range map[string]int{
+		"../etc/passwd.yaml":      http.StatusBadRequest,
+		"/etc/passwd":             http.StatusBadRequest,
+		".credentials":            http.StatusForbidden,
+		"repos/p1/README.md":      http.StatusForbidden,
+		"projects/p1/missing.yml": http.StatusNotFound,
+	} {
+		if rec := get(path); rec.Code != want {
+			t.Errorf("%s: status = %d, want %d", path, rec.Code, want)
+		}
+	}
+}
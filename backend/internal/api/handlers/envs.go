@@ -1,9 +1,16 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
@@ -14,13 +21,28 @@ import (
 	"github.com/environment-manager/backend/internal/projects"
 )
 
-// EnvsHandler exposes per-environment endpoints. Currently: destroy.
-// Build trigger lives on BuildsHandler for legacy continuity.
+// ComposeContainerLister is the docker subset needed to list an env's
+// containers. Implemented by *docker.Client.
+type ComposeContainerLister interface {
+	ListComposeContainers(ctx context.Context, project string) ([]models.ComposeContainer, error)
+}
+
+// ContainerTopper is the docker subset needed to list a container's
+// processes. Implemented by *docker.Client.
+type ContainerTopper interface {
+	ContainerTop(ctx context.Context, name string) ([]models.ContainerProcess, error)
+}
+
+// EnvsHandler exposes per-environment endpoints: destroy, event history, and
+// container listing. Build trigger lives on BuildsHandler for legacy
+// continuity.
 type EnvsHandler struct {
-	store     *projects.Store
-	runner    *builder.Runner
-	credStore *credentials.Store
-	logger    *zap.Logger
+	store      *projects.Store
+	runner     *builder.Runner
+	credStore  *credentials.Store
+	logger     *zap.Logger
+	containers ComposeContainerLister // nil = Containers returns 503
+	top        ContainerTopper        // nil = Top returns 503
 }
 
 // NewEnvsHandler wires the dependencies. runner may be nil — Destroy will
@@ -29,11 +51,33 @@ func NewEnvsHandler(store *projects.Store, runner *builder.Runner, credStore *cr
 	return &EnvsHandler{store: store, runner: runner, credStore: credStore, logger: logger}
 }
 
-// Destroy handles POST /api/v1/envs/{id}/destroy.
+// SetContainerLister wires the docker client used by Containers.
+func (h *EnvsHandler) SetContainerLister(l ComposeContainerLister) {
+	h.containers = l
+}
+
+// SetProcessLister wires the docker client used by Top.
+func (h *EnvsHandler) SetProcessLister(t ContainerTopper) {
+	h.top = t
+}
+
+// EnvDestroyResult is the POST /envs/{id}/destroy response. Volumes lists
+// the env's named volumes; VolumesRemoved says whether they went with it.
+type EnvDestroyResult struct {
+	Destroyed      string   `json:"destroyed"`
+	Volumes        []string `json:"volumes"`
+	VolumesRemoved bool     `json:"volumes_removed"`
+}
+
+// Destroy handles POST /api/v1/envs/{id}/destroy[?volumes=false].
 //
 // Preview environments only — reject prod with 400 ("use project delete to
 // remove a prod env"). Per-env teardown via runner.Teardown, then remove the
 // env row.
+//
+// Named volumes are removed by default (compose down -v); ?volumes=false
+// keeps them. Removing the volumes of an env in a protected project also
+// needs ?confirm=<env id>, else 409 PROTECTED.
 func (h *EnvsHandler) Destroy(w http.ResponseWriter, r *http.Request) {
 	envID := chi.URLParam(r, "id")
 	projectID, branchSlug, ok := splitEnvID(envID)
@@ -54,16 +98,572 @@ func (h *EnvsHandler) Destroy(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusBadRequest, "PROD_ENV", "prod environments cannot be destroyed standalone — use DELETE /projects/{id} to remove the whole project")
 		return
 	}
+	removeVolumes := true
+	if v := r.URL.Query().Get("volumes"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "INVALID_VOLUMES", "volumes must be true or false")
+			return
+		}
+		removeVolumes = b
+	}
+	if removeVolumes {
+		if project, perr := h.store.GetProject(projectID); perr == nil && project.Protected {
+			if r.URL.Query().Get("confirm") != env.ID {
+				respondError(w, http.StatusConflict, "PROTECTED", "project is protected — pass ?volumes=false to keep the env's volumes, or ?confirm="+env.ID+" to remove them")
+				return
+			}
+			h.logger.Warn("removing volumes of a protected project's env (confirmed)", zap.String("env_id", env.ID))
+		}
+	}
+	res := EnvDestroyResult{Destroyed: env.ID, Volumes: []string{}}
 	if h.runner != nil {
-		if terr := h.runner.Teardown(r.Context(), env); terr != nil {
+		vols, terr := h.runner.TeardownWith(r.Context(), env, builder.TeardownOptions{KeepVolumes: !removeVolumes})
+		if terr != nil {
 			h.logger.Warn("env destroy: teardown failed",
 				zap.String("env_id", env.ID), zap.Error(terr))
 		}
+		// Only claim the volumes are gone when `compose down -v` succeeded.
+		res.VolumesRemoved = removeVolumes && terr == nil
+		if vols != nil {
+			res.Volumes = vols
+		}
 	}
 	if derr := h.store.DeleteEnvironment(projectID, branchSlug); derr != nil {
 		respondError(w, http.StatusInternalServerError, "STORE_ERROR", derr.Error())
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]string{"destroyed": env.ID})
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+// Events handles GET /api/v1/envs/{id}/events?limit=N.
+//
+// Returns the env's recorded status transitions, oldest first. Works for
+// environments that have since been destroyed — the history is kept so a
+// failure that happened while nobody was watching stays discoverable.
+func (h *EnvsHandler) Events(w http.ResponseWriter, r *http.Request) {
+	envID := chi.URLParam(r, "id")
+	projectID, branchSlug, ok := splitEnvID(envID)
+	if !ok {
+		respondError(w, http.StatusBadRequest, "INVALID_ENV_ID", "env id must be <project>--<slug>")
+		return
+	}
+	limit := 50
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			respondError(w, http.StatusBadRequest, "INVALID_LIMIT", "limit must be a non-negative integer")
+			return
+		}
+		limit = n
+	}
+	events, err := h.store.ListEnvEvents(projectID, branchSlug, limit)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "STORE_ERROR", err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(events)
+}
+
+// Containers handles GET /api/v1/envs/{id}/containers.
+//
+// Lists the containers docker associates with the env's compose project
+// (com.docker.compose.project=<env_id>), each tagged with its compose
+// service — the same set `docker compose -p <env_id> ps -a` would show.
+func (h *EnvsHandler) Containers(w http.ResponseWriter, r *http.Request) {
+	envID := chi.URLParam(r, "id")
+	projectID, branchSlug, ok := splitEnvID(envID)
+	if !ok {
+		respondError(w, http.StatusBadRequest, "INVALID_ENV_ID", "env id must be <project>--<slug>")
+		return
+	}
+	if _, err := h.store.GetEnvironment(projectID, branchSlug); err != nil {
+		if errors.Is(err, projects.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "ENV_NOT_FOUND", "environment not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "STORE_ERROR", err.Error())
+		return
+	}
+	if h.containers == nil {
+		respondError(w, http.StatusServiceUnavailable, "DOCKER_UNAVAILABLE", "docker client unavailable")
+		return
+	}
+	list, err := h.containers.ListComposeContainers(r.Context(), envID)
+	if errors.Is(err, context.DeadlineExceeded) {
+		respondError(w, http.StatusGatewayTimeout, "TIMEOUT", err.Error())
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "DOCKER_ERROR", err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(list)
+}
+
+// EnvState is the GET /envs/{id}/state response: the stored status next
+// to what docker is actually running.
+type EnvState struct {
+	EnvID string `json:"env_id"`
+	// Desired is the env's stored status (what the last build left).
+	Desired models.EnvironmentStatus `json:"desired"`
+	// Observed aggregates the live containers — see observedState.
+	Observed   string                    `json:"observed"`
+	Containers []models.ComposeContainer `json:"containers"`
+	// Drained is set while a host drain has the env's containers stopped.
+	Drained bool `json:"drained,omitempty"`
+}
+
+// Observed env states, from live container state.
+const (
+	ObservedRunning = "running" // every long-running container is up
+	ObservedPartial = "partial" // some up, some created/paused/stopped
+	ObservedStopped = "stopped" // nothing up (or no containers at all)
+	ObservedError   = "error"   // a container is dead, restarting, or exited non-zero
+)
+
+// observedState folds per-container states into one. Containers that
+// exited 0 are one-shot jobs (migrations, seeders) that finished, so they
+// count neither for nor against the env being up. When drained, the env's
+// containers were stopped on purpose (`compose stop`, exit 137/143), so a
+// non-zero exit counts as stopped rather than as an error.
+func observedState(list []models.ComposeContainer, drained bool) string {
+	var running, other int
+	for _, c := range list {
+		switch c.State {
+		case "running":
+			running++
+		case "dead", "restarting":
+			return ObservedError
+		case "exited":
+			if c.ExitCode != 0 || c.OOMKilled {
+				if !drained {
+					return ObservedError
+				}
+				other++
+			}
+		default:
+			other++
+		}
+	}
+	switch {
+	case running > 0 && other == 0:
+		return ObservedRunning
+	case running > 0:
+		return ObservedPartial
+	default:
+		return ObservedStopped
+	}
+}
+
+// State handles GET /api/v1/envs/{id}/state.
+//
+// The stored status says "running" once a deploy succeeds and never
+// notices a service crashing afterwards; this compares it to the live
+// containers so a half-up env is visible at a glance.
+func (h *EnvsHandler) State(w http.ResponseWriter, r *http.Request) {
+	envID := chi.URLParam(r, "id")
+	projectID, branchSlug, ok := splitEnvID(envID)
+	if !ok {
+		respondError(w, http.StatusBadRequest, "INVALID_ENV_ID", "env id must be <project>--<slug>")
+		return
+	}
+	env, err := h.store.GetEnvironment(projectID, branchSlug)
+	if err != nil {
+		if errors.Is(err, projects.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "ENV_NOT_FOUND", "environment not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "STORE_ERROR", err.Error())
+		return
+	}
+	if h.containers == nil {
+		respondError(w, http.StatusServiceUnavailable, "DOCKER_UNAVAILABLE", "docker client unavailable")
+		return
+	}
+	list, err := h.containers.ListComposeContainers(r.Context(), envID)
+	if errors.Is(err, context.DeadlineExceeded) {
+		respondError(w, http.StatusGatewayTimeout, "TIMEOUT", err.Error())
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "DOCKER_ERROR", err.Error())
+		return
+	}
+	drained := false
+	if h.runner != nil {
+		if state, err := h.runner.Drained(); err == nil && state != nil {
+			drained = slices.Contains(state.Envs, env.ID)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(EnvState{
+		EnvID:      env.ID,
+		Desired:    env.Status,
+		Observed:   observedState(list, drained),
+		Containers: list,
+		Drained:    drained,
+	})
+}
+
+// Labels handles GET /api/v1/envs/{id}/labels.
+//
+// Previews the Traefik routing labels the env's next build would inject —
+// router names, Host rules, entrypoints, service port — without building.
+func (h *EnvsHandler) Labels(w http.ResponseWriter, r *http.Request) {
+	envID := chi.URLParam(r, "id")
+	projectID, branchSlug, ok := splitEnvID(envID)
+	if !ok {
+		respondError(w, http.StatusBadRequest, "INVALID_ENV_ID", "env id must be <project>--<slug>")
+		return
+	}
+	env, err := h.store.GetEnvironment(projectID, branchSlug)
+	if err != nil {
+		if errors.Is(err, projects.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "ENV_NOT_FOUND", "environment not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "STORE_ERROR", err.Error())
+		return
+	}
+	if h.runner == nil {
+		respondError(w, http.StatusServiceUnavailable, "RUNNER_UNAVAILABLE", "build runner unavailable")
+		return
+	}
+	preview, err := h.runner.PreviewLabels(env)
+	if err != nil {
+		respondError(w, http.StatusUnprocessableEntity, "LABELS_FAILED", err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(preview)
+}
+
+// EnvDivergence is the GET /envs/{id}/divergence response. Ahead counts
+// deployed commits missing from the branch, Behind counts branch commits
+// not yet deployed — see projects.Divergence.
+type EnvDivergence struct {
+	EnvID       string `json:"env_id"`
+	Branch      string `json:"branch"`
+	DeployedSHA string `json:"deployed_sha"`
+	Ahead       int    `json:"ahead"`
+	Behind      int    `json:"behind"`
+}
+
+// Divergence handles GET /api/v1/envs/{id}/divergence.
+//
+// Compares the env's last deployed commit to origin/<branch> in the
+// project's clone, as of the last fetch. Non-zero behind with no build
+// running means pushes aren't reaching the env (a missed or failing
+// webhook). 409 NOT_DEPLOYED until the env has a successful webhook build.
+func (h *EnvsHandler) Divergence(w http.ResponseWriter, r *http.Request) {
+	envID := chi.URLParam(r, "id")
+	projectID, branchSlug, ok := splitEnvID(envID)
+	if !ok {
+		respondError(w, http.StatusBadRequest, "INVALID_ENV_ID", "env id must be <project>--<slug>")
+		return
+	}
+	env, err := h.store.GetEnvironment(projectID, branchSlug)
+	if err != nil {
+		if errors.Is(err, projects.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "ENV_NOT_FOUND", "environment not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "STORE_ERROR", err.Error())
+		return
+	}
+	if env.LastDeployedSHA == "" {
+		respondError(w, http.StatusConflict, "NOT_DEPLOYED", "environment has no deployed commit yet")
+		return
+	}
+	project, err := h.store.GetProject(projectID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "STORE_ERROR", err.Error())
+		return
+	}
+	ahead, behind, err := projects.Divergence(project.LocalPath, env.LastDeployedSHA, env.Branch)
+	if err != nil {
+		respondError(w, http.StatusUnprocessableEntity, "DIVERGENCE_FAILED", err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(EnvDivergence{
+		EnvID:       env.ID,
+		Branch:      env.Branch,
+		DeployedSHA: env.LastDeployedSHA,
+		Ahead:       ahead,
+		Behind:      behind,
+	})
+}
+
+// EnvComposeHistory is the GET /envs/{id}/compose/history response.
+type EnvComposeHistory struct {
+	EnvID       string                  `json:"env_id"`
+	Branch      string                  `json:"branch"`
+	ComposeFile string                  `json:"compose_file"`
+	Revisions   []projects.FileRevision `json:"revisions"`
+}
+
+// ComposeHistory handles GET /api/v1/envs/{id}/compose/history.
+//
+// Lists the commits on the env's branch that touched its compose file,
+// newest first (?limit=N, default 50, 0 = all), as of the last fetch.
+// Fetch a revision's content with ComposeAt.
+func (h *EnvsHandler) ComposeHistory(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			respondError(w, http.StatusBadRequest, "INVALID_LIMIT", "limit must be a non-negative integer")
+			return
+		}
+		limit = n
+	}
+	env, project, ok := h.lookupEnvProject(w, r)
+	if !ok {
+		return
+	}
+	revs, err := projects.FileHistory(project.LocalPath, env.Branch, env.ComposeFile, limit)
+	if err != nil {
+		respondError(w, http.StatusUnprocessableEntity, "HISTORY_FAILED", err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, EnvComposeHistory{
+		EnvID:       env.ID,
+		Branch:      env.Branch,
+		ComposeFile: env.ComposeFile,
+		Revisions:   revs,
+	})
+}
+
+// ComposeAt handles GET /api/v1/envs/{id}/compose/history/{sha}.
+//
+// Returns the env's compose file as it was at commit sha (full or
+// abbreviated), as text/plain. 404 REVISION_NOT_FOUND when the commit or
+// the file at that commit doesn't exist in the clone.
+func (h *EnvsHandler) ComposeAt(w http.ResponseWriter, r *http.Request) {
+	env, project, ok := h.lookupEnvProject(w, r)
+	if !ok {
+		return
+	}
+	data, err := projects.FileAtRevision(project.LocalPath, chi.URLParam(r, "sha"), env.ComposeFile)
+	if err != nil {
+		if errors.Is(err, projects.ErrRevisionNotFound) {
+			respondError(w, http.StatusNotFound, "REVISION_NOT_FOUND", err.Error())
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "GIT_ERROR", err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write(data)
+}
+
+// lookupEnvProject resolves the {id} URL param to an env with a compose
+// file and its project, writing the error response itself when it can't.
+func (h *EnvsHandler) lookupEnvProject(w http.ResponseWriter, r *http.Request) (*models.Environment, *models.Project, bool) {
+	projectID, branchSlug, ok := splitEnvID(chi.URLParam(r, "id"))
+	if !ok {
+		respondError(w, http.StatusBadRequest, "INVALID_ENV_ID", "env id must be <project>--<slug>")
+		return nil, nil, false
+	}
+	env, err := h.store.GetEnvironment(projectID, branchSlug)
+	if err != nil {
+		if errors.Is(err, projects.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "ENV_NOT_FOUND", "environment not found")
+			return nil, nil, false
+		}
+		respondError(w, http.StatusInternalServerError, "STORE_ERROR", err.Error())
+		return nil, nil, false
+	}
+	if env.ComposeFile == "" {
+		respondError(w, http.StatusConflict, "NO_COMPOSE_FILE", "environment has no compose file")
+		return nil, nil, false
+	}
+	project, err := h.store.GetProject(projectID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "STORE_ERROR", err.Error())
+		return nil, nil, false
+	}
+	return env, project, true
+}
+
+// Pull handles POST /api/v1/envs/{id}/pull.
+//
+// Pre-pulls every service image in the env's rendered compose file and
+// reports a result per service, so a bad tag fails here instead of midway
+// through the next deploy. Returns 200 even when some pulls fail — check
+// each result's ok flag.
+//
+// With ?stream=true the response is NDJSON: one {"message"} object per
+// line of pull progress as it happens, then a final {"env_id","results"}
+// object (or {"error"} if the pull was cut short).
+func (h *EnvsHandler) Pull(w http.ResponseWriter, r *http.Request) {
+	envID := chi.URLParam(r, "id")
+	projectID, branchSlug, ok := splitEnvID(envID)
+	if !ok {
+		respondError(w, http.StatusBadRequest, "INVALID_ENV_ID", "env id must be <project>--<slug>")
+		return
+	}
+	stream := false
+	if v := r.URL.Query().Get("stream"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "INVALID_STREAM", "stream must be true or false")
+			return
+		}
+		stream = b
+	}
+	env, err := h.store.GetEnvironment(projectID, branchSlug)
+	if err != nil {
+		if errors.Is(err, projects.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "ENV_NOT_FOUND", "environment not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "STORE_ERROR", err.Error())
+		return
+	}
+	if h.runner == nil {
+		respondError(w, http.StatusServiceUnavailable, "RUNNER_UNAVAILABLE", "build runner unavailable")
+		return
+	}
+	var progress *pullProgressWriter
+	if stream {
+		progress = &pullProgressWriter{w: w}
+	}
+	var log io.Writer
+	if progress != nil {
+		log = progress
+	}
+	results, err := h.runner.Pull(r.Context(), env, log)
+	if progress != nil && progress.started {
+		// Headers are out; report the outcome as the last line.
+		progress.flush()
+		if err != nil {
+			progress.encode(map[string]string{"error": err.Error()})
+			return
+		}
+		progress.encode(map[string]any{"env_id": env.ID, "results": results})
+		return
+	}
+	if errors.Is(err, builder.ErrNotBuilt) {
+		respondError(w, http.StatusConflict, "ENV_NOT_BUILT", "environment has no rendered compose file yet — trigger a build first")
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "PULL_FAILED", err.Error())
+		return
+	}
+	if progress != nil {
+		progress.encode(map[string]any{"env_id": env.ID, "results": results})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"env_id": env.ID, "results": results})
+}
+
+// pullProgressWriter turns compose pull output into NDJSON {"message"}
+// lines, flushing each one. Headers go out with the first line, so an
+// error before any output can still be a plain JSON error response.
+// Compose writes stdout and stderr from separate goroutines, hence mu.
+type pullProgressWriter struct {
+	w       http.ResponseWriter
+	mu      sync.Mutex
+	started bool
+	partial []byte
+}
+
+func (p *pullProgressWriter) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.partial = append(p.partial, b...)
+	for {
+		i := bytes.IndexByte(p.partial, '\n')
+		if i < 0 {
+			return len(b), nil
+		}
+		if line := strings.TrimSpace(string(p.partial[:i])); line != "" {
+			p.encodeLocked(map[string]string{"message": line})
+		}
+		p.partial = p.partial[i+1:]
+	}
+}
+
+// flush emits a trailing line that had no newline.
+func (p *pullProgressWriter) flush() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if line := strings.TrimSpace(string(p.partial)); line != "" {
+		p.encodeLocked(map[string]string{"message": line})
+	}
+	p.partial = nil
+}
+
+func (p *pullProgressWriter) encode(v any) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.encodeLocked(v)
+}
+
+func (p *pullProgressWriter) encodeLocked(v any) {
+	if !p.started {
+		p.w.Header().Set("Content-Type", "application/x-ndjson")
+		p.w.WriteHeader(http.StatusOK)
+		p.started = true
+	}
+	_ = json.NewEncoder(p.w).Encode(v)
+	if f, ok := p.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Top handles GET /api/v1/envs/{id}/top?service=<name>.
+//
+// Lists the processes running in one of the env's service containers
+// (`docker top`), resolved like the runtime-log stream: compose's
+// <env_id>-<service>-1 naming, service defaulting to the project's
+// expose.service. A read-only alternative to granting exec.
+func (h *EnvsHandler) Top(w http.ResponseWriter, r *http.Request) {
+	envID := chi.URLParam(r, "id")
+	projectID, branchSlug, ok := splitEnvID(envID)
+	if !ok {
+		respondError(w, http.StatusBadRequest, "INVALID_ENV_ID", "env id must be <project>--<slug>")
+		return
+	}
+	if _, err := h.store.GetEnvironment(projectID, branchSlug); err != nil {
+		if errors.Is(err, projects.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "ENV_NOT_FOUND", "environment not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "STORE_ERROR", err.Error())
+		return
+	}
+	service := strings.TrimSpace(r.URL.Query().Get("service"))
+	if service == "" {
+		if p, err := h.store.GetProject(projectID); err == nil && p.Expose != nil {
+			service = p.Expose.Service
+		}
+	}
+	if service == "" || strings.ContainsAny(service, "/\\") || service == ".." {
+		respondError(w, http.StatusBadRequest, "INVALID_SERVICE", "service query param required")
+		return
+	}
+	if h.top == nil {
+		respondError(w, http.StatusServiceUnavailable, "DOCKER_UNAVAILABLE", "docker client unavailable")
+		return
+	}
+	procs, err := h.top.ContainerTop(r.Context(), envID+"-"+service+"-1")
+	if errors.Is(err, context.DeadlineExceeded) {
+		respondError(w, http.StatusGatewayTimeout, "TIMEOUT", err.Error())
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusBadGateway, "DOCKER_ERROR", err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(procs)
 }
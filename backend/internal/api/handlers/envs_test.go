@@ -2,11 +2,15 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"go.uber.org/zap"
@@ -44,6 +48,83 @@ func TestEnvsHandler_Destroy_Preview(t *testing.T) {
 	}
 }
 
+func TestEnvsHandler_Destroy_Volumes(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := projects.NewStore(dir)
+	creds, _ := credentials.NewStore(filepath.Join(dir, "c.json"), make([]byte, 32))
+	_ = store.SaveProject(&models.Project{ID: "p1", Name: "myapp", Protected: true})
+	runner := builder.NewRunner(store, envsFakeExec{}, dir, "", builder.NewQueue(), zap.NewNop(), creds)
+	h := NewEnvsHandler(store, runner, creds, zap.NewNop())
+
+	destroy := func(query string) *httptest.ResponseRecorder {
+		_ = store.SaveEnvironment(&models.Environment{ID: "p1--feature-x", ProjectID: "p1", BranchSlug: "feature-x", Kind: models.EnvKindPreview})
+		envDir := filepath.Join(dir, "envs", "p1--feature-x")
+		_ = os.MkdirAll(envDir, 0755)
+		_ = os.WriteFile(filepath.Join(envDir, "docker-compose.yaml"), []byte("services:\n  db:\n    image: postgres\nvolumes:\n  pgdata: {}\n"), 0644)
+		req := httptest.NewRequest("POST", "/api/v1/envs/p1--feature-x/destroy"+query, nil)
+		req = withChiURLParams(req, map[string]string{"id": "p1--feature-x"})
+		rec := httptest.NewRecorder()
+		h.Destroy(rec, req)
+		return rec
+	}
+
+	if rec := destroy(""); rec.Code != http.StatusConflict {
+		t.Errorf("protected, no confirm: status = %d, want 409", rec.Code)
+	}
+	if rec := destroy("?volumes=maybe"); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad volumes flag: status = %d, want 400", rec.Code)
+	}
+	for _, c := range []struct {
+		query   string
+		removed bool
+	}{
+		{"?volumes=false", false},
+		{"?confirm=p1--feature-x", true},
+	} {
+		rec := destroy(c.query)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d; body = %s", c.query, rec.Code, rec.Body.String())
+		}
+		var got EnvDestroyResult
+		_ = json.NewDecoder(rec.Body).Decode(&got)
+		if got.VolumesRemoved != c.removed || len(got.Volumes) != 1 || got.Volumes[0] != "p1--feature-x_pgdata" {
+			t.Errorf("%s: got %+v", c.query, got)
+		}
+	}
+}
+
+type envsFailingExec struct{}
+
+func (envsFailingExec) Compose(ctx context.Context, _, _ string, _ []string, _ io.Writer, _ io.Writer) error {
+	return errors.New("exit status 1")
+}
+
+func TestEnvsHandler_Destroy_DownFailedKeepsVolumes(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := projects.NewStore(dir)
+	creds, _ := credentials.NewStore(filepath.Join(dir, "c.json"), make([]byte, 32))
+	_ = store.SaveProject(&models.Project{ID: "p1", Name: "myapp"})
+	_ = store.SaveEnvironment(&models.Environment{ID: "p1--feature-x", ProjectID: "p1", BranchSlug: "feature-x", Kind: models.EnvKindPreview})
+	envDir := filepath.Join(dir, "envs", "p1--feature-x")
+	_ = os.MkdirAll(envDir, 0755)
+	_ = os.WriteFile(filepath.Join(envDir, "docker-compose.yaml"), []byte("services:\n  db:\n    image: postgres\nvolumes:\n  pgdata: {}\n"), 0644)
+	runner := builder.NewRunner(store, envsFailingExec{}, dir, "", builder.NewQueue(), zap.NewNop(), creds)
+	h := NewEnvsHandler(store, runner, creds, zap.NewNop())
+
+	req := httptest.NewRequest("POST", "/api/v1/envs/p1--feature-x/destroy", nil)
+	req = withChiURLParams(req, map[string]string{"id": "p1--feature-x"})
+	rec := httptest.NewRecorder()
+	h.Destroy(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d; body = %s", rec.Code, rec.Body.String())
+	}
+	var got EnvDestroyResult
+	_ = json.NewDecoder(rec.Body).Decode(&got)
+	if got.VolumesRemoved {
+		t.Errorf("volumes_removed = true after a failed compose down: %+v", got)
+	}
+}
+
 func TestEnvsHandler_Destroy_RejectsProd(t *testing.T) {
 	dir := t.TempDir()
 	store, _ := projects.NewStore(dir)
@@ -59,3 +140,380 @@ func TestEnvsHandler_Destroy_RejectsProd(t *testing.T) {
 		t.Errorf("status = %d, want 400", rec.Code)
 	}
 }
+
+func TestEnvsHandler_Events(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := projects.NewStore(dir)
+	env := &models.Environment{ID: "p1--main", ProjectID: "p1", BranchSlug: "main", Status: models.EnvStatusBuilding}
+	_ = store.SaveEnvironment(env)
+	env.Status = models.EnvStatusRunning
+	_ = store.SaveEnvironment(env)
+	h := NewEnvsHandler(store, nil, nil, zap.NewNop())
+
+	req := httptest.NewRequest("GET", "/api/v1/envs/p1--main/events", nil)
+	req = withChiURLParams(req, map[string]string{"id": "p1--main"})
+	rec := httptest.NewRecorder()
+	h.Events(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d; body = %s", rec.Code, rec.Body.String())
+	}
+	var got []models.EnvEvent
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[1].To != models.EnvStatusRunning {
+		t.Errorf("got %+v", got)
+	}
+}
+
+type fakeContainerLister struct {
+	gotProject string
+	list       []models.ComposeContainer
+}
+
+func (f *fakeContainerLister) ListComposeContainers(_ context.Context, project string) ([]models.ComposeContainer, error) {
+	f.gotProject = project
+	return f.list, nil
+}
+
+func TestEnvsHandler_Containers(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := projects.NewStore(dir)
+	_ = store.SaveProject(&models.Project{ID: "p1", Name: "myapp"})
+	_ = store.SaveEnvironment(&models.Environment{ID: "p1--main", ProjectID: "p1", BranchSlug: "main", Kind: models.EnvKindProd})
+	h := NewEnvsHandler(store, nil, nil, zap.NewNop())
+
+	// Without a docker client wired the endpoint reports 503.
+	req := withChiURLParams(httptest.NewRequest("GET", "/api/v1/envs/p1--main/containers", nil), map[string]string{"id": "p1--main"})
+	rec := httptest.NewRecorder()
+	h.Containers(rec, req)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", rec.Code)
+	}
+
+	fake := &fakeContainerLister{list: []models.ComposeContainer{
+		{ID: "abc", Name: "p1--main-web-1", Service: "web", State: "running"},
+	}}
+	h.SetContainerLister(fake)
+	rec = httptest.NewRecorder()
+	h.Containers(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+	if fake.gotProject != "p1--main" {
+		t.Errorf("compose project = %q, want p1--main", fake.gotProject)
+	}
+	var got []models.ComposeContainer
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Service != "web" {
+		t.Errorf("got %+v", got)
+	}
+
+	// Unknown env → 404.
+	req = withChiURLParams(httptest.NewRequest("GET", "/api/v1/envs/p1--nope/containers", nil), map[string]string{"id": "p1--nope"})
+	rec = httptest.NewRecorder()
+	h.Containers(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+}
+
+func TestEnvsHandler_Labels(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := projects.NewStore(dir)
+	repo := filepath.Join(dir, "repo")
+	_ = os.MkdirAll(filepath.Join(repo, ".dev"), 0755)
+	_ = os.WriteFile(filepath.Join(repo, ".dev", "docker-compose.prod.yml"), []byte("services:\n  web:\n    image: nginx\n"), 0644)
+	_ = store.SaveProject(&models.Project{ID: "p1", Name: "myapp", LocalPath: repo, Expose: &models.ExposeSpec{Service: "web", Port: 80}})
+	_ = store.SaveEnvironment(&models.Environment{ID: "p1--main", ProjectID: "p1", BranchSlug: "main", Kind: models.EnvKindProd, ComposeFile: ".dev/docker-compose.prod.yml", URL: "myapp.home"})
+	runner := builder.NewRunner(store, envsFakeExec{}, dir, "proxy-net", builder.NewQueue(), zap.NewNop(), nil)
+	h := NewEnvsHandler(store, runner, nil, zap.NewNop())
+
+	req := withChiURLParams(httptest.NewRequest("GET", "/api/v1/envs/p1--main/labels", nil), map[string]string{"id": "p1--main"})
+	rec := httptest.NewRecorder()
+	h.Labels(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body = %s", rec.Code, rec.Body.String())
+	}
+	var got builder.LabelPreview
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Service != "web" || got.Network != "proxy-net" {
+		t.Errorf("got service=%q network=%q", got.Service, got.Network)
+	}
+	if got.Labels["traefik.http.routers.p1--main.rule"] != "Host(`myapp.home`)" {
+		t.Errorf("unexpected labels: %v", got.Labels)
+	}
+}
+
+func TestEnvsHandler_Pull_NotBuilt(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := projects.NewStore(dir)
+	_ = store.SaveEnvironment(&models.Environment{ID: "p1--main", ProjectID: "p1", BranchSlug: "main"})
+	runner := builder.NewRunner(store, envsFakeExec{}, dir, "", builder.NewQueue(), zap.NewNop(), nil)
+	h := NewEnvsHandler(store, runner, nil, zap.NewNop())
+
+	req := httptest.NewRequest("POST", "/api/v1/envs/p1--main/pull", nil)
+	req = withChiURLParams(req, map[string]string{"id": "p1--main"})
+	rec := httptest.NewRecorder()
+	h.Pull(rec, req)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("status = %d, want 409; body = %s", rec.Code, rec.Body.String())
+	}
+}
+
+// envsPullExec prints pull progress for each service it pulls.
+type envsPullExec struct{}
+
+func (envsPullExec) Compose(_ context.Context, _, _ string, args []string, stdout io.Writer, _ io.Writer) error {
+	_, _ = stdout.Write([]byte(args[len(args)-1] + " Pulled\n"))
+	return nil
+}
+
+func TestEnvsHandler_Pull_Stream(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := projects.NewStore(dir)
+	_ = store.SaveEnvironment(&models.Environment{ID: "p1--main", ProjectID: "p1", BranchSlug: "main"})
+	envDir := filepath.Join(dir, "envs", "p1--main")
+	_ = os.MkdirAll(envDir, 0755)
+	_ = os.WriteFile(filepath.Join(envDir, "docker-compose.yaml"), []byte("services:\n  db:\n    image: postgres:16\n  app:\n    build: .\n"), 0644)
+	runner := builder.NewRunner(store, envsPullExec{}, dir, "", builder.NewQueue(), zap.NewNop(), nil)
+	h := NewEnvsHandler(store, runner, nil, zap.NewNop())
+
+	req := httptest.NewRequest("POST", "/api/v1/envs/p1--main/pull?stream=true", nil)
+	req = withChiURLParams(req, map[string]string{"id": "p1--main"})
+	rec := httptest.NewRecorder()
+	h.Pull(rec, req)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/x-ndjson" {
+		t.Fatalf("status = %d, content-type = %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	var messages []string
+	for _, l := range lines[:len(lines)-1] {
+		var m struct{ Message string }
+		if err := json.Unmarshal([]byte(l), &m); err != nil {
+			t.Fatalf("line %q: %v", l, err)
+		}
+		messages = append(messages, m.Message)
+	}
+	if strings.Join(messages, "|") != "==> docker compose pull db|db Pulled" {
+		t.Errorf("progress = %q", messages)
+	}
+	var final struct {
+		EnvID   string               `json:"env_id"`
+		Results []builder.PullResult `json:"results"`
+	}
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &final); err != nil {
+		t.Fatal(err)
+	}
+	if final.EnvID != "p1--main" || len(final.Results) != 2 || !final.Results[0].OK || !final.Results[1].Skipped {
+		t.Errorf("final = %+v", final)
+	}
+
+	req = httptest.NewRequest("POST", "/api/v1/envs/p1--main/pull?stream=maybe", nil)
+	req = withChiURLParams(req, map[string]string{"id": "p1--main"})
+	rec = httptest.NewRecorder()
+	h.Pull(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("stream=maybe: status = %d, want 400", rec.Code)
+	}
+}
+
+type fakeTopper struct{ gotName string }
+
+func (f *fakeTopper) ContainerTop(_ context.Context, name string) ([]models.ContainerProcess, error) {
+	f.gotName = name
+	return []models.ContainerProcess{{PID: "1", User: "root", Command: "nginx"}}, nil
+}
+
+func TestEnvsHandler_Top(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := projects.NewStore(dir)
+	_ = store.SaveProject(&models.Project{ID: "p1", Name: "myapp", Expose: &models.ExposeSpec{Service: "web", Port: 80}})
+	_ = store.SaveEnvironment(&models.Environment{ID: "p1--main", ProjectID: "p1", BranchSlug: "main"})
+	h := NewEnvsHandler(store, nil, nil, zap.NewNop())
+	fake := &fakeTopper{}
+	h.SetProcessLister(fake)
+
+	// No ?service= → falls back to the project's expose.service.
+	req := httptest.NewRequest("GET", "/api/v1/envs/p1--main/top", nil)
+	req = withChiURLParams(req, map[string]string{"id": "p1--main"})
+	rec := httptest.NewRecorder()
+	h.Top(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d; body = %s", rec.Code, rec.Body.String())
+	}
+	if fake.gotName != "p1--main-web-1" {
+		t.Errorf("container = %q, want p1--main-web-1", fake.gotName)
+	}
+	var got []models.ContainerProcess
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil || len(got) != 1 || got[0].Command != "nginx" {
+		t.Errorf("got %+v (err %v)", got, err)
+	}
+}
+
+func TestEnvsHandler_Divergence(t *testing.T) {
+	store, _, project := makeProjectFixture(t)
+	h := NewEnvsHandler(store, nil, nil, zap.NewNop())
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = project.LocalPath
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	get := func() *httptest.ResponseRecorder {
+		req := withChiURLParams(httptest.NewRequest("GET", "/api/v1/envs/p1--main/divergence", nil), map[string]string{"id": "p1--main"})
+		rec := httptest.NewRecorder()
+		h.Divergence(rec, req)
+		return rec
+	}
+
+	if rec := get(); rec.Code != http.StatusConflict {
+		t.Fatalf("never deployed: status = %d, want 409", rec.Code)
+	}
+
+	env, _ := store.GetEnvironment("p1", "main")
+	env.LastDeployedSHA = git("rev-parse", "HEAD")
+	_ = store.SaveEnvironment(env)
+	git("commit", "--allow-empty", "-m", "not deployed yet")
+	git("push", "origin", "main")
+
+	rec := get()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d; body=%s", rec.Code, rec.Body.String())
+	}
+	var got EnvDivergence
+	_ = json.NewDecoder(rec.Body).Decode(&got)
+	if got.Ahead != 0 || got.Behind != 1 || got.DeployedSHA != env.LastDeployedSHA {
+		t.Errorf("got %+v, want 1 behind", got)
+	}
+}
+
+func TestEnvsHandler_ComposeHistory(t *testing.T) {
+	store, _, project := makeProjectFixture(t)
+	h := NewEnvsHandler(store, nil, nil, zap.NewNop())
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = project.LocalPath
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	initial := git("rev-parse", "HEAD")
+	if err := os.WriteFile(filepath.Join(project.LocalPath, ".dev/docker-compose.prod.yml"), []byte("services:\n  app:\n    image: nginx:1.27\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("commit", "-am", "pin nginx")
+	git("push", "origin", "main")
+
+	req := withChiURLParams(httptest.NewRequest("GET", "/api/v1/envs/p1--main/compose/history", nil), map[string]string{"id": "p1--main"})
+	rec := httptest.NewRecorder()
+	h.ComposeHistory(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d; body=%s", rec.Code, rec.Body.String())
+	}
+	var got EnvComposeHistory
+	_ = json.NewDecoder(rec.Body).Decode(&got)
+	if len(got.Revisions) != 2 || got.Revisions[0].Subject != "pin nginx" || got.Revisions[1].SHA != initial {
+		t.Fatalf("revisions = %+v", got.Revisions)
+	}
+
+	at := func(sha string) *httptest.ResponseRecorder {
+		req := withChiURLParams(httptest.NewRequest("GET", "/api/v1/envs/p1--main/compose/history/"+sha, nil), map[string]string{"id": "p1--main", "sha": sha})
+		rec := httptest.NewRecorder()
+		h.ComposeAt(rec, req)
+		return rec
+	}
+	if rec := at(initial); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "hello-world") {
+		t.Errorf("at initial: %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := at("deadbeef"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown sha: status = %d, want 404", rec.Code)
+	}
+}
+
+func TestObservedState(t *testing.T) {
+	up := models.ComposeContainer{State: "running", Status: "Up 3 hours"}
+	migrated := models.ComposeContainer{State: "exited", Status: "Exited (0) 2 hours ago"}
+	crashed := models.ComposeContainer{State: "exited", Status: "Exited (1) 5 minutes ago", ExitCode: 1}
+	// Status text alone isn't trusted: the exit code comes from inspect.
+	oom := models.ComposeContainer{State: "exited", Status: "Exited (0) 1 minute ago", OOMKilled: true}
+	stopped := models.ComposeContainer{State: "exited", Status: "Exited (143) 1 minute ago", ExitCode: 143}
+	created := models.ComposeContainer{State: "created", Status: "Created"}
+	cases := []struct {
+		name    string
+		list    []models.ComposeContainer
+		drained bool
+		want    string
+	}{
+		{"none", nil, false, ObservedStopped},
+		{"all up", []models.ComposeContainer{up, up}, false, ObservedRunning},
+		{"finished job ignored", []models.ComposeContainer{up, migrated}, false, ObservedRunning},
+		{"half up", []models.ComposeContainer{up, created}, false, ObservedPartial},
+		{"crashed", []models.ComposeContainer{up, crashed}, false, ObservedError},
+		{"oom killed", []models.ComposeContainer{up, oom}, false, ObservedError},
+		{"restarting", []models.ComposeContainer{up, {State: "restarting"}}, false, ObservedError},
+		{"only created", []models.ComposeContainer{created}, false, ObservedStopped},
+		{"stopped undrained", []models.ComposeContainer{stopped, stopped}, false, ObservedError},
+		{"drained", []models.ComposeContainer{stopped, stopped, migrated}, true, ObservedStopped},
+	}
+	for _, c := range cases {
+		if got := observedState(c.list, c.drained); got != c.want {
+			t.Errorf("%s: got %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestEnvsHandler_State(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := projects.NewStore(dir)
+	_ = store.SaveEnvironment(&models.Environment{ID: "p1--main", ProjectID: "p1", BranchSlug: "main", Status: models.EnvStatusRunning})
+	h := NewEnvsHandler(store, nil, nil, zap.NewNop())
+	h.SetContainerLister(&fakeContainerLister{list: []models.ComposeContainer{
+		{Service: "web", State: "running"},
+		{Service: "worker", State: "exited", Status: "Exited (137) 1 minute ago", ExitCode: 137},
+	}})
+
+	req := withChiURLParams(httptest.NewRequest("GET", "/api/v1/envs/p1--main/state", nil), map[string]string{"id": "p1--main"})
+	rec := httptest.NewRecorder()
+	h.State(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d; body = %s", rec.Code, rec.Body.String())
+	}
+	var got EnvState
+	_ = json.NewDecoder(rec.Body).Decode(&got)
+	if got.Desired != models.EnvStatusRunning || got.Observed != ObservedError || len(got.Containers) != 2 {
+		t.Errorf("got %+v, want desired running / observed error", got)
+	}
+}
+
+func TestEnvsHandler_State_Drained(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := projects.NewStore(dir)
+	_ = store.SaveEnvironment(&models.Environment{ID: "p1--main", ProjectID: "p1", BranchSlug: "main", Status: models.EnvStatusRunning})
+	if err := os.WriteFile(filepath.Join(dir, "drained.json"), []byte(`{"envs":["p1--main"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runner := builder.NewRunner(store, envsFakeExec{}, dir, "", builder.NewQueue(), zap.NewNop(), nil)
+	h := NewEnvsHandler(store, runner, nil, zap.NewNop())
+	h.SetContainerLister(&fakeContainerLister{list: []models.ComposeContainer{
+		{Service: "web", State: "exited", Status: "Exited (137) 1 minute ago", ExitCode: 137},
+	}})
+
+	req := withChiURLParams(httptest.NewRequest("GET", "/api/v1/envs/p1--main/state", nil), map[string]string{"id": "p1--main"})
+	rec := httptest.NewRecorder()
+	h.State(rec, req)
+	var got EnvState
+	_ = json.NewDecoder(rec.Body).Decode(&got)
+	if got.Observed != ObservedStopped || !got.Drained {
+		t.Errorf("got %+v, want observed stopped while drained", got)
+	}
+}
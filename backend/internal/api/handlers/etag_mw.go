@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxETagBody bounds how much of a response ETag buffers to hash. Larger
+// responses are streamed through untagged.
+const maxETagBody = 1 << 20
+
+// ETag returns a middleware that tags 200 JSON responses to GET and HEAD
+// with a strong ETag (a hash of the body) and answers a matching
+// If-None-Match with 304 and no body, so pollers and caches can revalidate
+// cheaply. Together with chi's GetHead, HEAD on any GET resource returns
+// the same status and headers as GET without the body.
+//
+// Anything else — other methods, non-JSON bodies (log downloads), error
+// statuses, bodies over maxETagBody — passes through untouched.
+func ETag(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ew := &etagWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(ew, r)
+		if ew.mode != etagBuffering {
+			if ew.mode == etagUndecided { // handler wrote nothing
+				ew.spill()
+			}
+			return
+		}
+		sum := sha256.Sum256(ew.buf.Bytes())
+		tag := `"` + hex.EncodeToString(sum[:16]) + `"`
+		w.Header().Set("ETag", tag)
+		if etagMatches(r.Header.Get("If-None-Match"), tag) {
+			w.Header().Del("Content-Type")
+			w.Header().Del("Content-Length")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(ew.buf.Len()))
+		w.WriteHeader(ew.status)
+		_, _ = w.Write(ew.buf.Bytes())
+	})
+}
+
+// etagMatches reports whether an If-None-Match header value names tag.
+func etagMatches(header, tag string) bool {
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimPrefix(strings.TrimSpace(t), "W/")
+		if t == tag || t == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+type etagMode int
+
+const (
+	etagUndecided etagMode = iota
+	etagBuffering
+	etagPassthrough
+)
+
+// etagWriter holds back a taggable response until the handler returns and
+// forwards everything else as it's written.
+type etagWriter struct {
+	http.ResponseWriter
+	status int
+	mode   etagMode
+	buf    bytes.Buffer
+}
+
+func (e *etagWriter) WriteHeader(status int) {
+	if e.mode != etagUndecided {
+		if e.mode == etagPassthrough {
+			e.ResponseWriter.WriteHeader(status)
+		}
+		return
+	}
+	e.status = status
+	e.decide()
+}
+
+func (e *etagWriter) Write(p []byte) (int, error) {
+	if e.mode == etagUndecided {
+		e.decide()
+	}
+	if e.mode == etagBuffering {
+		if e.buf.Len()+len(p) <= maxETagBody {
+			return e.buf.Write(p)
+		}
+		e.spill()
+	}
+	return e.ResponseWriter.Write(p)
+}
+
+// Flush lets streaming handlers push data: a buffered response is spilled
+// and the rest passes straight through.
+func (e *etagWriter) Flush() {
+	if e.mode != etagPassthrough {
+		e.spill()
+	}
+	if f, ok := e.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (e *etagWriter) decide() {
+	if e.status == http.StatusOK && strings.HasPrefix(e.Header().Get("Content-Type"), "application/json") {
+		e.mode = etagBuffering
+		return
+	}
+	e.mode = etagPassthrough
+	e.ResponseWriter.WriteHeader(e.status)
+}
+
+// spill writes the status and anything buffered so far, then switches to
+// passthrough.
+func (e *etagWriter) spill() {
+	wasBuffering := e.mode == etagBuffering
+	e.mode = etagPassthrough
+	e.ResponseWriter.WriteHeader(e.status)
+	if wasBuffering {
+		_, _ = e.ResponseWriter.Write(e.buf.Bytes())
+		e.buf.Reset()
+	}
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+func newETagRouter() http.Handler {
+	r := chi.NewRouter()
+	r.Use(middleware.GetHead)
+	r.Use(ETag)
+	r.Get("/projects", func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, http.StatusOK, []string{"p1", "p2"})
+	})
+	r.Get("/missing", func(w http.ResponseWriter, r *http.Request) {
+		respondError(w, http.StatusNotFound, "not_found", "no such project")
+	})
+	r.Get("/logs", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("line 1\n"))
+	})
+	return r
+}
+
+func TestETag_RevalidatesJSON(t *testing.T) {
+	h := newETagRouter()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/projects", nil))
+	tag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || tag == "" || !strings.Contains(rec.Body.String(), "p2") {
+		t.Fatalf("first GET: %d etag=%q body=%q", rec.Code, tag, rec.Body.String())
+	}
+
+	req := httptest.NewRequest("GET", "/projects", nil)
+	req.Header.Set("If-None-Match", tag)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("revalidate: %d body=%q, want 304 and no body", rec.Code, rec.Body.String())
+	}
+
+	req.Header.Set("If-None-Match", `"stale"`)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != tag {
+		t.Errorf("stale tag: %d etag=%q", rec.Code, rec.Header().Get("ETag"))
+	}
+}
+
+func TestETag_HeadMirrorsGet(t *testing.T) {
+	h := newETagRouter()
+	get := httptest.NewRecorder()
+	h.ServeHTTP(get, httptest.NewRequest("GET", "/projects", nil))
+	head := httptest.NewRecorder()
+	h.ServeHTTP(head, httptest.NewRequest("HEAD", "/projects", nil))
+	if head.Code != http.StatusOK || head.Header().Get("ETag") != get.Header().Get("ETag") {
+		t.Errorf("HEAD: %d etag=%q, want 200 and GET's etag %q", head.Code, head.Header().Get("ETag"), get.Header().Get("ETag"))
+	}
+	if head.Header().Get("Content-Length") != get.Header().Get("Content-Length") {
+		t.Errorf("HEAD Content-Length = %q, GET = %q", head.Header().Get("Content-Length"), get.Header().Get("Content-Length"))
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("HEAD", "/missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("HEAD on missing resource: %d, want 404", rec.Code)
+	}
+}
+
+func TestETag_PassesThroughNonJSON(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newETagRouter().ServeHTTP(rec, httptest.NewRequest("GET", "/logs", nil))
+	if rec.Header().Get("ETag") != "" || rec.Body.String() != "line 1\n" {
+		t.Errorf("text response: etag=%q body=%q", rec.Header().Get("ETag"), rec.Body.String())
+	}
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// IdempotencyTTL is how long a completed response is replayable under its
+// Idempotency-Key.
+const IdempotencyTTL = 24 * time.Hour
+
+// maxIdempotencyKeyLen bounds the header value; keys are meant to be UUIDs.
+const maxIdempotencyKeyLen = 255
+
+// Idempotency returns a middleware that makes POST requests carrying an
+// Idempotency-Key header safe to retry: the first request runs normally and
+// its response is remembered for ttl; a repeat with the same key (and the
+// same method, path and body) gets that response replayed, with an
+// Idempotent-Replayed: true header, instead of creating a second project or
+// queueing a second build.
+//
+//   - Same key, different request → 422 IDEMPOTENCY_KEY_REUSED.
+//   - Same key while the first request is still running → 409
+//     IDEMPOTENCY_IN_PROGRESS.
+//   - 5xx responses aren't remembered, so the client's retry runs again.
+//
+// Keys live in memory only; a restart forgets them. Requests without the
+// header, and non-POST requests, pass straight through.
+func Idempotency(ttl time.Duration) func(http.Handler) http.Handler {
+	s := &idempotencyStore{ttl: ttl, entries: map[string]*idempotencyEntry{}, now: time.Now}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get("Idempotency-Key")
+			if key == "" || r.Method != http.MethodPost {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLen {
+				respondError(w, http.StatusBadRequest, "INVALID_IDEMPOTENCY_KEY", "Idempotency-Key is too long")
+				return
+			}
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				respondError(w, http.StatusBadRequest, "INVALID_BODY", err.Error())
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			fp := sha256.Sum256(append([]byte(r.Method+" "+r.URL.RequestURI()+"\n"), body...))
+
+			e, fresh := s.begin(key, fp)
+			switch {
+			case !fresh && e.fingerprint != fp:
+				respondError(w, http.StatusUnprocessableEntity, "IDEMPOTENCY_KEY_REUSED", "Idempotency-Key was already used for a different request")
+				return
+			case !fresh && !e.done:
+				respondError(w, http.StatusConflict, "IDEMPOTENCY_IN_PROGRESS", "a request with this Idempotency-Key is still in progress")
+				return
+			case !fresh:
+				if e.contentType != "" {
+					w.Header().Set("Content-Type", e.contentType)
+				}
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(e.status)
+				_, _ = w.Write(e.body)
+				return
+			}
+
+			rec := &capturingWriter{ResponseWriter: w, status: http.StatusOK}
+			finished := false
+			defer func() {
+				if !finished { // handler panicked: don't wedge the key
+					s.forget(key)
+				}
+			}()
+			next.ServeHTTP(rec, r)
+			s.finish(key, rec)
+			finished = true
+		})
+	}
+}
+
+// idempotencyEntry is one key's state: in flight until done, then the
+// remembered response.
+type idempotencyEntry struct {
+	fingerprint [32]byte
+	done        bool
+	status      int
+	contentType string
+	body        []byte
+	expires     time.Time
+}
+
+type idempotencyStore struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	entries   map[string]*idempotencyEntry
+	lastSweep time.Time
+	now       func() time.Time // injectable for tests
+}
+
+// begin returns key's entry. fresh is true when no live entry existed and a
+// new in-flight one was registered — the caller must run the request and
+// call finish.
+func (s *idempotencyStore) begin(key string, fp [32]byte) (e *idempotencyEntry, fresh bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if now.Sub(s.lastSweep) > time.Minute {
+		for k, e := range s.entries {
+			if e.done && now.After(e.expires) {
+				delete(s.entries, k)
+			}
+		}
+		s.lastSweep = now
+	}
+	if e, ok := s.entries[key]; ok && !(e.done && now.After(e.expires)) {
+		return e, false
+	}
+	e = &idempotencyEntry{fingerprint: fp}
+	s.entries[key] = e
+	return e, true
+}
+
+// finish records the response, or forgets the key when it was a server
+// error so a retry runs the request again.
+func (s *idempotencyStore) finish(key string, rec *capturingWriter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec.status >= 500 {
+		delete(s.entries, key)
+		return
+	}
+	e := s.entries[key]
+	e.done = true
+	e.status = rec.status
+	e.contentType = rec.Header().Get("Content-Type")
+	e.body = rec.buf.Bytes()
+	e.expires = s.now().Add(s.ttl)
+}
+
+func (s *idempotencyStore) forget(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+}
+
+// capturingWriter passes the response through while keeping a copy.
+type capturingWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	buf         bytes.Buffer
+}
+
+func (c *capturingWriter) WriteHeader(status int) {
+	if !c.wroteHeader {
+		c.status, c.wroteHeader = status, true
+	}
+	c.ResponseWriter.WriteHeader(status)
+}
+
+func (c *capturingWriter) Write(p []byte) (int, error) {
+	c.wroteHeader = true
+	c.buf.Write(p)
+	return c.ResponseWriter.Write(p)
+}
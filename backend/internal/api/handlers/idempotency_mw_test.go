@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestIdempotency_ReplaysResponse(t *testing.T) {
+	calls := 0
+	h := Idempotency(IdempotencyTTL)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"n":` + strconv.Itoa(calls) + `}`))
+	}))
+	do := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/projects", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := do("k1", `{"repo_url":"x"}`)
+	again := do("k1", `{"repo_url":"x"}`)
+	if calls != 1 {
+		t.Fatalf("handler ran %d times, want 1", calls)
+	}
+	if again.Code != http.StatusCreated || again.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %q, want %d %q", again.Code, again.Body.String(), first.Code, first.Body.String())
+	}
+	if again.Header().Get("Idempotent-Replayed") != "true" || again.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("replay headers = %v", again.Header())
+	}
+
+	if rec := do("k1", `{"repo_url":"y"}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("reused key with new body: status = %d, want 422", rec.Code)
+	}
+	do("", `{"repo_url":"x"}`)
+	do("k2", `{"repo_url":"x"}`)
+	if calls != 3 {
+		t.Errorf("handler ran %d times, want 3 (no key and new key both run)", calls)
+	}
+}
+
+func TestIdempotency_ServerErrorNotRemembered(t *testing.T) {
+	calls := 0
+	h := Idempotency(IdempotencyTTL)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			respondError(w, http.StatusInternalServerError, "STORE_ERROR", "disk full")
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("POST", "/api/v1/envs/p1--main/build", nil)
+		req.Header.Set("Idempotency-Key", "k")
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if calls != 2 {
+		t.Errorf("handler ran %d times, want 2 (5xx must be retryable)", calls)
+	}
+}
+
+func TestIdempotency_InProgress(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	h := Idempotency(IdempotencyTTL)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}))
+	newReq := func() *http.Request {
+		req := httptest.NewRequest("POST", "/api/v1/projects", nil)
+		req.Header.Set("Idempotency-Key", "k")
+		return req
+	}
+	done := make(chan struct{})
+	go func() {
+		h.ServeHTTP(httptest.NewRecorder(), newReq())
+		close(done)
+	}()
+	<-started
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newReq())
+	if rec.Code != http.StatusConflict {
+		t.Errorf("concurrent duplicate: status = %d, want 409", rec.Code)
+	}
+	close(release)
+	<-done
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/environment-manager/backend/internal/builder"
+)
+
+// maxLintBody bounds the compose file LintCompose accepts.
+const maxLintBody = 1 << 20
+
+// LintCompose handles POST /api/v1/compose/lint. The request body is a
+// compose file (YAML); the response lists non-fatal advisories — root
+// user, no resource limits, no healthcheck, floating image tag, privileged
+// mode, sensitive host binds, restart "no". An empty list means nothing
+// to flag. Unparseable YAML is a 400; nothing is stored or run.
+func LintCompose(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxLintBody))
+	if err != nil {
+		var tooBig *http.MaxBytesError
+		if errors.As(err, &tooBig) {
+			respondError(w, http.StatusRequestEntityTooLarge, "BODY_TOO_LARGE", "compose file exceeds 1 MiB")
+			return
+		}
+		respondError(w, http.StatusBadRequest, "INVALID_BODY", err.Error())
+		return
+	}
+	if len(data) == 0 {
+		respondError(w, http.StatusBadRequest, "INVALID_BODY", "request body must be a compose file")
+		return
+	}
+	advisories, err := builder.LintCompose(data)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_COMPOSE", err.Error())
+		return
+	}
+	respondJSON(w, http.StatusOK, advisories)
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/environment-manager/backend/internal/builder"
+)
+
+// MaintenanceHandler exposes admin housekeeping endpoints.
+type MaintenanceHandler struct {
+	runner *builder.Runner
+	logger *zap.Logger
+}
+
+// NewMaintenanceHandler wires the dependencies. runner may be nil — every
+// endpoint then returns 503.
+func NewMaintenanceHandler(runner *builder.Runner, logger *zap.Logger) *MaintenanceHandler {
+	return &MaintenanceHandler{runner: runner, logger: logger}
+}
+
+// pruneResponse is the POST /admin/prune body.
+type pruneResponse struct {
+	DryRun  bool     `json:"dry_run"`
+	Orphans []string `json:"orphans"`
+}
+
+// Prune handles POST /api/v1/admin/prune?dry_run=true|false.
+//
+// Removes env runtime dirs (rendered compose, build logs) whose env no
+// longer exists and whose compose project has no containers left. dry_run
+// defaults to true so a bare call only reports.
+func (h *MaintenanceHandler) Prune(w http.ResponseWriter, r *http.Request) {
+	if h.runner == nil {
+		respondError(w, http.StatusServiceUnavailable, "RUNNER_UNAVAILABLE", "build runner unavailable")
+		return
+	}
+	dryRun := true
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "INVALID_DRY_RUN", "dry_run must be true or false")
+			return
+		}
+		dryRun = b
+	}
+	orphans, err := h.runner.PruneOrphans(r.Context(), dryRun)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "PRUNE_FAILED", err.Error())
+		return
+	}
+	if !dryRun && len(orphans) > 0 {
+		h.logger.Info("pruned orphaned env dirs", zap.Strings("paths", orphans))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(pruneResponse{DryRun: dryRun, Orphans: orphans})
+}
+
+// drainTimeout bounds a drain or undrain; each env's compose stop/start
+// runs inside it.
+const drainTimeout = 5 * time.Minute
+
+// Drain handles POST /api/v1/admin/drain.
+//
+// Stops every running env's containers ahead of host maintenance and
+// records which ones, so POST /admin/undrain — or the next server start —
+// brings them back. Stored env status is left as running. Returns the
+// recorded DrainState; per-env failures are listed under "failed".
+func (h *MaintenanceHandler) Drain(w http.ResponseWriter, r *http.Request) {
+	if h.runner == nil {
+		respondError(w, http.StatusServiceUnavailable, "RUNNER_UNAVAILABLE", "build runner unavailable")
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), drainTimeout)
+	defer cancel()
+	state, err := h.runner.Drain(ctx)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "DRAIN_FAILED", err.Error())
+		return
+	}
+	h.logger.Info("host drained", zap.Strings("envs", state.Envs), zap.Any("failed", state.Failed))
+	respondJSON(w, http.StatusOK, state)
+}
+
+// Undrain handles POST /api/v1/admin/undrain: starts the envs the last
+// drain stopped and clears the record once all of them are up. Envs that
+// fail to start stay recorded for the next undrain. 409 NOT_DRAINED when
+// there's no drain to undo.
+func (h *MaintenanceHandler) Undrain(w http.ResponseWriter, r *http.Request) {
+	if h.runner == nil {
+		respondError(w, http.StatusServiceUnavailable, "RUNNER_UNAVAILABLE", "build runner unavailable")
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), drainTimeout)
+	defer cancel()
+	state, err := h.runner.Undrain(ctx)
+	if errors.Is(err, builder.ErrNotDrained) {
+		respondError(w, http.StatusConflict, "NOT_DRAINED", err.Error())
+		return
+	}
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "UNDRAIN_FAILED", err.Error())
+		return
+	}
+	h.logger.Info("host undrained", zap.Strings("envs", state.Envs), zap.Any("failed", state.Failed))
+	respondJSON(w, http.StatusOK, state)
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/environment-manager/backend/internal/models"
+	"github.com/environment-manager/backend/internal/projects"
+)
+
+// NetworkManager is the docker subset needed to list and prune networks.
+// Implemented by *docker.Client.
+type NetworkManager interface {
+	ListManagedNetworks(ctx context.Context) ([]models.ManagedNetwork, error)
+	RemoveNetwork(ctx context.Context, id string) error
+}
+
+// protectedNetworks are never pruned, attached containers or not: the
+// service-plane network every provisioned env joins as external.
+var protectedNetworks = map[string]bool{
+	"paas-net": true,
+}
+
+// NetworksHandler lists and prunes the docker networks env-manager owns.
+type NetworksHandler struct {
+	docker NetworkManager
+	store  *projects.Store
+	logger *zap.Logger
+}
+
+// NewNetworksHandler wires the dependencies. docker may be nil — every
+// endpoint then returns 503.
+func NewNetworksHandler(docker NetworkManager, store *projects.Store, logger *zap.Logger) *NetworksHandler {
+	return &NetworksHandler{docker: docker, store: store, logger: logger}
+}
+
+// NetworkEntry is one network in GET /networks. Prunable is true when
+// POST /admin/networks/prune would remove it.
+type NetworkEntry struct {
+	models.ManagedNetwork
+	Prunable bool `json:"prunable"`
+}
+
+// List handles GET /api/v1/networks.
+func (h *NetworksHandler) List(w http.ResponseWriter, r *http.Request) {
+	entries, ok := h.entries(w, r)
+	if !ok {
+		return
+	}
+	respondJSON(w, http.StatusOK, entries)
+}
+
+// networkPruneResponse is the POST /admin/networks/prune body.
+type networkPruneResponse struct {
+	DryRun  bool              `json:"dry_run"`
+	Removed []string          `json:"removed"`
+	Failed  map[string]string `json:"failed,omitempty"`
+}
+
+// Prune handles POST /api/v1/admin/networks/prune?dry_run=true|false.
+//
+// Removes managed networks with no containers attached. paas-net is never
+// removed, and neither is the network of an env that still exists — its
+// containers may only be stopped (a drain), and compose start needs the
+// network back. dry_run defaults to true so a bare call only reports.
+func (h *NetworksHandler) Prune(w http.ResponseWriter, r *http.Request) {
+	dryRun := true
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "INVALID_DRY_RUN", "dry_run must be true or false")
+			return
+		}
+		dryRun = b
+	}
+	entries, ok := h.entries(w, r)
+	if !ok {
+		return
+	}
+	resp := networkPruneResponse{DryRun: dryRun, Removed: []string{}}
+	for _, e := range entries {
+		if !e.Prunable {
+			continue
+		}
+		if !dryRun {
+			if err := h.docker.RemoveNetwork(r.Context(), e.ID); err != nil {
+				if resp.Failed == nil {
+					resp.Failed = map[string]string{}
+				}
+				resp.Failed[e.Name] = err.Error()
+				continue
+			}
+		}
+		resp.Removed = append(resp.Removed, e.Name)
+	}
+	if !dryRun && len(resp.Removed) > 0 {
+		h.logger.Info("pruned unused networks", zap.Strings("networks", resp.Removed))
+	}
+	respondJSON(w, http.StatusOK, resp)
+}
+
+// entries lists the networks that belong to env-manager, writing an HTTP
+// error and returning false when docker can't be queried.
+func (h *NetworksHandler) entries(w http.ResponseWriter, r *http.Request) ([]NetworkEntry, bool) {
+	if h.docker == nil {
+		respondError(w, http.StatusServiceUnavailable, "DOCKER_UNAVAILABLE", "docker client unavailable")
+		return nil, false
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+	list, err := h.docker.ListManagedNetworks(ctx)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			respondError(w, http.StatusGatewayTimeout, "TIMEOUT", "docker: "+err.Error())
+		} else {
+			respondError(w, http.StatusInternalServerError, "DOCKER_ERROR", err.Error())
+		}
+		return nil, false
+	}
+	out := []NetworkEntry{}
+	for _, n := range list {
+		e := NetworkEntry{ManagedNetwork: n}
+		if n.ComposeProject == "" {
+			e.Prunable = n.Containers == 0 && !protectedNetworks[n.Name]
+			out = append(out, e)
+			continue
+		}
+		// Compose networks are ours only when the project is an env ID of
+		// a known project; anything else is someone else's stack.
+		projectID, slug, ok := splitEnvID(n.ComposeProject)
+		if !ok {
+			continue
+		}
+		if _, err := h.store.GetProject(projectID); err != nil {
+			continue
+		}
+		_, err := h.store.GetEnvironment(projectID, slug)
+		e.Prunable = n.Containers == 0 && errors.Is(err, projects.ErrNotFound)
+		out = append(out, e)
+	}
+	return out, true
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/environment-manager/backend/internal/models"
+	"github.com/environment-manager/backend/internal/projects"
+)
+
+type fakeNetworks struct {
+	list    []models.ManagedNetwork
+	removed []string
+}
+
+func (f *fakeNetworks) ListManagedNetworks(context.Context) ([]models.ManagedNetwork, error) {
+	return f.list, nil
+}
+
+func (f *fakeNetworks) RemoveNetwork(_ context.Context, id string) error {
+	f.removed = append(f.removed, id)
+	return nil
+}
+
+func TestNetworksHandler_ListAndPrune(t *testing.T) {
+	store, _ := projects.NewStore(t.TempDir())
+	_ = store.SaveProject(&models.Project{ID: "p1", Name: "app"})
+	_ = store.SaveEnvironment(&models.Environment{ID: "p1--main", ProjectID: "p1", BranchSlug: "main"})
+	docker := &fakeNetworks{list: []models.ManagedNetwork{
+		{ID: "n1", Name: "paas-net"},                                     // protected
+		{ID: "n2", Name: "p1--main_default", ComposeProject: "p1--main"}, // live env, drained
+		{ID: "n3", Name: "p1--gone_default", ComposeProject: "p1--gone"}, // destroyed env
+		{ID: "n4", Name: "p1--busy_default", ComposeProject: "p1--busy", Containers: 1},
+		{ID: "n5", Name: "blog_default", ComposeProject: "blog"}, // not ours
+		{ID: "n6", Name: "old-net"},                              // managed, unused
+	}}
+	h := NewNetworksHandler(docker, store, zap.NewNop())
+
+	rec := httptest.NewRecorder()
+	h.List(rec, httptest.NewRequest("GET", "/api/v1/networks", nil))
+	var entries []NetworkEntry
+	_ = json.NewDecoder(rec.Body).Decode(&entries)
+	prunable := map[string]bool{}
+	for _, e := range entries {
+		prunable[e.Name] = e.Prunable
+	}
+	want := map[string]bool{
+		"paas-net": false, "p1--main_default": false, "p1--gone_default": true,
+		"p1--busy_default": false, "old-net": true,
+	}
+	if !reflect.DeepEqual(prunable, want) {
+		t.Errorf("prunable = %v, want %v", prunable, want)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Prune(rec, httptest.NewRequest("POST", "/api/v1/admin/networks/prune", nil))
+	if len(docker.removed) != 0 {
+		t.Fatalf("dry run removed %v", docker.removed)
+	}
+
+	rec = httptest.NewRecorder()
+	h.Prune(rec, httptest.NewRequest("POST", "/api/v1/admin/networks/prune?dry_run=false", nil))
+	var resp networkPruneResponse
+	_ = json.NewDecoder(rec.Body).Decode(&resp)
+	if !reflect.DeepEqual(docker.removed, []string{"n3", "n6"}) || len(resp.Removed) != 2 {
+		t.Errorf("removed = %v, response %+v", docker.removed, resp)
+	}
+}
+
+func TestNetworksHandler_NoDocker(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewNetworksHandler(nil, nil, zap.NewNop()).List(rec, httptest.NewRequest("GET", "/api/v1/networks", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", rec.Code)
+	}
+}
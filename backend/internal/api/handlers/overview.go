@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/environment-manager/backend/internal/models"
+	"github.com/environment-manager/backend/internal/projects"
+)
+
+// OverviewResponse is the GET /api/v1/overview body: everything the
+// dashboard header needs in one round trip.
+type OverviewResponse struct {
+	Projects     int               `json:"projects"`
+	Environments EnvCounts         `json:"environments"`
+	Builds       BuildCounts       `json:"builds"`
+	Services     map[string]string `json:"services"` // container → running | stopped | absent
+}
+
+// EnvCounts aggregates environments across all projects.
+type EnvCounts struct {
+	Total    int            `json:"total"`
+	ByStatus map[string]int `json:"by_status"`
+	ByKind   map[string]int `json:"by_kind"`
+}
+
+// BuildCounts aggregates the latest build of every environment.
+type BuildCounts struct {
+	Running    int `json:"running"`     // builds currently in flight
+	LastFailed int `json:"last_failed"` // envs whose most recent build failed
+}
+
+// OverviewHandler serves the aggregate dashboard summary.
+type OverviewHandler struct {
+	store  *projects.Store
+	docker ContainerInspector // nil-safe; services report "absent"
+}
+
+// NewOverviewHandler wires the dependencies.
+func NewOverviewHandler(store *projects.Store, docker ContainerInspector) *OverviewHandler {
+	return &OverviewHandler{store: store, docker: docker}
+}
+
+// Get handles GET /api/v1/overview.
+func (h *OverviewHandler) Get(w http.ResponseWriter, r *http.Request) {
+	resp := OverviewResponse{
+		Environments: EnvCounts{ByStatus: map[string]int{}, ByKind: map[string]int{}},
+		Services: map[string]string{
+			"paas-postgres": singletonStatus(h.docker, "paas-postgres"),
+			"paas-redis":    singletonStatus(h.docker, "paas-redis"),
+		},
+	}
+	allProjects, err := h.store.ListProjects()
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "STORE_ERROR", err.Error())
+		return
+	}
+	resp.Projects = len(allProjects)
+	for _, p := range allProjects {
+		envs, _ := h.store.ListEnvironments(p.ID)
+		for _, e := range envs {
+			resp.Environments.Total++
+			resp.Environments.ByStatus[string(e.Status)]++
+			resp.Environments.ByKind[string(e.Kind)]++
+
+			builds, _ := h.store.ListBuildsForEnv(p.ID, e.ID)
+			var latest *models.Build
+			for _, b := range builds {
+				if b.Status == models.BuildStatusRunning {
+					resp.Builds.Running++
+				}
+				if latest == nil || b.StartedAt.After(latest.StartedAt) {
+					latest = b
+				}
+			}
+			if latest != nil && latest.Status == models.BuildStatusFailed {
+				resp.Builds.LastFailed++
+			}
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/environment-manager/backend/internal/models"
+	"github.com/environment-manager/backend/internal/projects"
+)
+
+func TestOverviewHandler_Get(t *testing.T) {
+	store, _ := projects.NewStore(t.TempDir())
+	_ = store.SaveProject(&models.Project{ID: "p1", Name: "myapp"})
+	_ = store.SaveEnvironment(&models.Environment{ID: "p1--main", ProjectID: "p1", BranchSlug: "main", Kind: models.EnvKindProd, Status: models.EnvStatusRunning})
+	_ = store.SaveEnvironment(&models.Environment{ID: "p1--feat", ProjectID: "p1", BranchSlug: "feat", Kind: models.EnvKindPreview, Status: models.EnvStatusFailed})
+	now := time.Now()
+	_ = store.SaveBuild("p1", &models.Build{ID: "b1", EnvID: "p1--feat", StartedAt: now.Add(-time.Hour), Status: models.BuildStatusSuccess})
+	_ = store.SaveBuild("p1", &models.Build{ID: "b2", EnvID: "p1--feat", StartedAt: now, Status: models.BuildStatusFailed})
+	_ = store.SaveBuild("p1", &models.Build{ID: "b3", EnvID: "p1--main", StartedAt: now, Status: models.BuildStatusRunning})
+
+	h := NewOverviewHandler(store, &fakeInspector{exists: true, running: true})
+	rec := httptest.NewRecorder()
+	h.Get(rec, httptest.NewRequest("GET", "/api/v1/overview", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	var got OverviewResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Projects != 1 || got.Environments.Total != 2 {
+		t.Errorf("counts = %+v", got)
+	}
+	if got.Environments.ByStatus["failed"] != 1 || got.Environments.ByKind["preview"] != 1 {
+		t.Errorf("env breakdown = %+v", got.Environments)
+	}
+	if got.Builds.Running != 1 || got.Builds.LastFailed != 1 {
+		t.Errorf("builds = %+v", got.Builds)
+	}
+	if got.Services["paas-postgres"] != "running" {
+		t.Errorf("services = %v", got.Services)
+	}
+}
@@ -1,19 +1,23 @@
 package handlers
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 
 	"github.com/environment-manager/backend/internal/builder"
@@ -34,6 +38,7 @@ type ProjectsHandler struct {
 	logger       *zap.Logger
 	baseDomain   string
 	runner       *builder.Runner
+	spawner      projects.EnvSpawner // nil = Reconcile returns 503
 }
 
 // NewProjectsHandler wires the dependencies. baseDomain is the fallback
@@ -55,10 +60,20 @@ func NewProjectsHandler(store *projects.Store, reposManager *repos.Manager, cred
 	}
 }
 
+// SetSpawner wires the env spawner used by Reconcile — the same one the
+// boot-time branch reconcile uses.
+func (h *ProjectsHandler) SetSpawner(sp projects.EnvSpawner) {
+	h.spawner = sp
+}
+
 // CreateProjectRequest is the POST /api/v1/projects body.
 type CreateProjectRequest struct {
 	RepoURL string `json:"repo_url"`
 	Token   string `json:"token,omitempty"`
+	// Build kicks off the prod env's first build right after onboarding.
+	// Default false: the env stays pending until a build is triggered, so
+	// secrets can be set before the first deploy.
+	Build bool `json:"build,omitempty"`
 }
 
 // CreateProjectResponse is returned on successful creation.
@@ -66,13 +81,28 @@ type CreateProjectResponse struct {
 	Project         *models.Project     `json:"project"`
 	Environment     *models.Environment `json:"environment"`
 	RequiredSecrets []string            `json:"required_secrets"`
+	BuildID         string              `json:"build_id,omitempty"` // set when the request asked for build=true
 }
 
 // Create handles POST /api/v1/projects. Clones the repo, validates its
 // .dev/ directory, parses config, persists Project + prod Environment.
-// Does NOT enqueue a build — that's step 3's responsibility. The env
-// is left at Status=pending.
+// Does NOT enqueue a build unless the request sets build=true — by default
+// the env is left at Status=pending.
+//
+// With ?dry_run=true the same validation runs (clone, .dev/ parse, domain
+// conflicts) but nothing is persisted: the clone is removed again and the
+// response (200) shows the project, env, URL and Traefik labels that would
+// be created.
 func (h *ProjectsHandler) Create(w http.ResponseWriter, r *http.Request) {
+	dryRun := false
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "INVALID_DRY_RUN", "dry_run must be true or false")
+			return
+		}
+		dryRun = b
+	}
 	var req CreateProjectRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		respondError(w, http.StatusBadRequest, "invalid_body", err.Error())
@@ -142,15 +172,9 @@ func (h *ProjectsHandler) Create(w http.ResponseWriter, r *http.Request) {
 		Status:    models.ProjectStatusActive,
 		CreatedAt: now,
 	}
-	if err := h.store.SaveProject(project); err != nil {
-		_ = h.reposManager.Delete(repo.ID)
-		respondError(w, http.StatusInternalServerError, "save_project_failed", err.Error())
-		return
-	}
 
 	prodSlug, err := projects.BranchSlug(defaultBranch)
 	if err != nil {
-		_ = h.store.DeleteProject(project.ID)
 		_ = h.reposManager.Delete(repo.ID)
 		respondError(w, http.StatusInternalServerError, "slug_failed", err.Error())
 		return
@@ -166,6 +190,17 @@ func (h *ProjectsHandler) Create(w http.ResponseWriter, r *http.Request) {
 		CreatedAt:   now,
 	}
 	env.URL = projects.ComposeURL(project, env, h.baseDomain)
+
+	if dryRun {
+		h.createDryRun(w, repo, project, env, devInfo.SecretKeys)
+		return
+	}
+
+	if err := h.store.SaveProject(project); err != nil {
+		_ = h.reposManager.Delete(repo.ID)
+		respondError(w, http.StatusInternalServerError, "save_project_failed", err.Error())
+		return
+	}
 	if err := h.store.SaveEnvironment(env); err != nil {
 		_ = h.store.DeleteProject(project.ID)
 		_ = h.reposManager.Delete(repo.ID)
@@ -174,21 +209,11 @@ func (h *ProjectsHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Plan 8: cross-project domain conflict check.
-	// Best-effort: try to parse the dev config as v2; if it parses, walk all
-	// other projects' v2 configs and reject conflicting domains. v1-only
-	// projects skip this check entirely (no Domains block to conflict on).
-	if iacBytes, rerr := os.ReadFile(filepath.Join(repo.LocalPath, ".dev", "config.yaml")); rerr == nil {
-		if iacCfg, perr := iac.Parse(iacBytes); perr == nil {
-			others, oerr := h.collectIacConfigs(project.ID)
-			if oerr == nil {
-				if cerr := iac.CheckDomainConflict(iacCfg, project.ID, others); cerr != nil {
-					_ = h.store.DeleteProject(project.ID)
-					_ = h.reposManager.Delete(repo.ID)
-					respondError(w, http.StatusConflict, "DOMAIN_CONFLICT", cerr.Error())
-					return
-				}
-			}
-		}
+	if cerr := h.domainConflict(repo, project.ID); cerr != nil {
+		_ = h.store.DeleteProject(project.ID)
+		_ = h.reposManager.Delete(repo.ID)
+		respondError(w, http.StatusConflict, "DOMAIN_CONFLICT", cerr.Error())
+		return
 	}
 
 	requiredSecrets := devInfo.SecretKeys
@@ -203,13 +228,95 @@ func (h *ProjectsHandler) Create(w http.ResponseWriter, r *http.Request) {
 		zap.String("default_branch", project.DefaultBranch),
 	)
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	_ = json.NewEncoder(w).Encode(CreateProjectResponse{
+	resp := CreateProjectResponse{
 		Project:         project,
 		Environment:     env,
 		RequiredSecrets: requiredSecrets,
-	})
+	}
+	if req.Build && h.runner != nil {
+		build := &models.Build{
+			ID:          uuid.NewString(),
+			EnvID:       env.ID,
+			TriggeredBy: models.BuildTriggerClone,
+			StartedAt:   time.Now().UTC(),
+			Status:      models.BuildStatusRunning,
+		}
+		if err := h.store.SaveBuild(project.ID, build); err != nil {
+			h.logger.Warn("project created but first build not enqueued",
+				zap.String("id", project.ID), zap.Error(err))
+		} else {
+			go func() {
+				if err := h.runner.Build(context.Background(), env, build); err != nil {
+					h.logger.Warn("first build after onboarding failed",
+						zap.String("env_id", env.ID),
+						zap.String("build_id", build.ID),
+						zap.Error(err),
+					)
+				}
+			}()
+			resp.BuildID = build.ID
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// CreateProjectDryRun is the POST /api/v1/projects?dry_run=true response.
+type CreateProjectDryRun struct {
+	DryRun          bool                  `json:"dry_run"`
+	Project         *models.Project       `json:"project"`
+	Environment     *models.Environment   `json:"environment"`
+	RequiredSecrets []string              `json:"required_secrets"`
+	Labels          *builder.LabelPreview `json:"labels,omitempty"` // nil when no runner is wired
+}
+
+// createDryRun finishes a dry-run Create: checks domain conflicts against
+// the stored projects, previews the labels, then removes the clone. Nothing
+// touches the store.
+func (h *ProjectsHandler) createDryRun(w http.ResponseWriter, repo *models.Repository, project *models.Project, env *models.Environment, secrets []string) {
+	defer func() { _ = h.reposManager.Delete(repo.ID) }()
+
+	if cerr := h.domainConflict(repo, project.ID); cerr != nil {
+		respondError(w, http.StatusConflict, "DOMAIN_CONFLICT", cerr.Error())
+		return
+	}
+
+	resp := CreateProjectDryRun{DryRun: true, Project: project, Environment: env, RequiredSecrets: secrets}
+	if resp.RequiredSecrets == nil {
+		resp.RequiredSecrets = []string{}
+	}
+	if h.runner != nil {
+		labels, err := h.runner.PreviewProjectLabels(project, env)
+		if err != nil {
+			respondError(w, http.StatusUnprocessableEntity, "LABELS_FAILED", err.Error())
+			return
+		}
+		resp.Labels = labels
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// domainConflict checks the cloned repo's .dev/config.yaml against every
+// other project's domains. Best-effort: a missing or unparseable (v1-only)
+// config, or a failure listing the other projects, skips the check — only a
+// real conflict is returned.
+func (h *ProjectsHandler) domainConflict(repo *models.Repository, projectID string) error {
+	iacBytes, err := os.ReadFile(filepath.Join(repo.LocalPath, ".dev", "config.yaml"))
+	if err != nil {
+		return nil
+	}
+	iacCfg, err := iac.Parse(iacBytes)
+	if err != nil {
+		return nil
+	}
+	others, err := h.collectIacConfigs(projectID)
+	if err != nil {
+		return nil
+	}
+	return iac.CheckDomainConflict(iacCfg, projectID, others)
 }
 
 // resolveCloneURL returns the URL (or local path) that should be passed to
@@ -285,6 +392,171 @@ func (h *ProjectsHandler) Get(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(ProjectDetail{Project: p, Environments: envs})
 }
 
+// maxNotesLen bounds Project.Notes. Notes are a sticky label, not a wiki.
+const maxNotesLen = 4096
+
+// SetNotes handles PUT /api/v1/projects/{id}/notes with body
+// {"notes": "..."}. An empty string clears the notes. Returns the updated
+// project.
+func (h *ProjectsHandler) SetNotes(w http.ResponseWriter, r *http.Request) {
+	id := h.urlID(r)
+	var body struct {
+		Notes string `json:"notes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_BODY", err.Error())
+		return
+	}
+	if len(body.Notes) > maxNotesLen {
+		respondError(w, http.StatusBadRequest, "NOTES_TOO_LONG", fmt.Sprintf("notes must be at most %d bytes", maxNotesLen))
+		return
+	}
+	p, err := h.store.GetProject(id)
+	if err != nil {
+		if errors.Is(err, projects.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "project not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "STORE_ERROR", err.Error())
+		return
+	}
+	p.Notes = body.Notes
+	if err := h.store.SaveProject(p); err != nil {
+		respondError(w, http.StatusInternalServerError, "STORE_ERROR", err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// RemoteStatus is the GET /api/v1/projects/{id}/remote response.
+type RemoteStatus struct {
+	Reachable bool      `json:"reachable"`
+	Error     string    `json:"error,omitempty"` // git's output when unreachable
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Remote handles GET /api/v1/projects/{id}/remote. Runs a live
+// `git ls-remote` against the project's origin with the stored GitHub
+// token, so operators can tell a dead remote or a revoked PAT apart from
+// "no new commits".
+func (h *ProjectsHandler) Remote(w http.ResponseWriter, r *http.Request) {
+	id := h.urlID(r)
+	p, err := h.store.GetProject(id)
+	if err != nil {
+		if errors.Is(err, projects.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "not_found", "project not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "store_error", err.Error())
+		return
+	}
+	var token string
+	if h.credStore != nil {
+		token, _ = h.credStore.GetGlobalToken("github")
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	status := RemoteStatus{Reachable: true, CheckedAt: time.Now().UTC()}
+	if out, cerr := projects.CheckRemote(ctx, p.LocalPath, token); cerr != nil {
+		status.Reachable = false
+		status.Error = strings.TrimSpace(string(out))
+		if status.Error == "" {
+			status.Error = cerr.Error()
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(status)
+}
+
+// ReconcileResult is the POST /projects/{id}/reconcile response: one line
+// per env spawned or torn down. Empty when the project was already in sync.
+type ReconcileResult struct {
+	ProjectID string   `json:"project_id"`
+	Changes   []string `json:"changes"`
+}
+
+// Reconcile handles POST /api/v1/projects/{id}/reconcile. Runs the
+// boot-time branch reconcile for this one project on demand: fetch origin,
+// spawn previews for new .dev/ branches, tear down envs whose branch is
+// gone. Useful after a missed webhook without restarting the server.
+func (h *ProjectsHandler) Reconcile(w http.ResponseWriter, r *http.Request) {
+	if h.spawner == nil {
+		respondError(w, http.StatusServiceUnavailable, "RECONCILE_UNAVAILABLE", "reconcile not configured")
+		return
+	}
+	id := h.urlID(r)
+	p, err := h.store.GetProject(id)
+	if err != nil {
+		if errors.Is(err, projects.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "not_found", "project not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "store_error", err.Error())
+		return
+	}
+	if p.LocalPath == "" || p.RepoURL == "" {
+		respondError(w, http.StatusBadRequest, "no_repo", "project has no git repository to reconcile against")
+		return
+	}
+	var token string
+	if h.credStore != nil {
+		token, _ = h.credStore.GetGlobalToken("github")
+	}
+	changes := projects.ReconcileProject(r.Context(), h.store, h.spawner, p, h.logger, token)
+	if changes == nil {
+		changes = []string{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(ReconcileResult{ProjectID: p.ID, Changes: changes})
+}
+
+// RepoGCResult is the POST /admin/projects/{id}/gc response. Sizes are of
+// the clone's .git directory, in bytes.
+type RepoGCResult struct {
+	ProjectID  string `json:"project_id"`
+	SizeBefore int64  `json:"size_before"`
+	SizeAfter  int64  `json:"size_after"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// GC handles POST /api/v1/admin/projects/{id}/gc. Runs `git gc` on the
+// project's local clone to repack the objects that every webhook fetch
+// leaves behind. Synchronous — on a large repo this takes a while, so the
+// timeout is generous.
+func (h *ProjectsHandler) GC(w http.ResponseWriter, r *http.Request) {
+	id := h.urlID(r)
+	p, err := h.store.GetProject(id)
+	if err != nil {
+		if errors.Is(err, projects.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "not_found", "project not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "store_error", err.Error())
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Minute)
+	defer cancel()
+	start := time.Now()
+	res := RepoGCResult{ProjectID: p.ID, SizeBefore: projects.GitDirSize(p.LocalPath)}
+	if out, gerr := projects.GCRepo(ctx, p.LocalPath); gerr != nil {
+		msg := strings.TrimSpace(string(out))
+		if msg == "" {
+			msg = gerr.Error()
+		}
+		respondError(w, http.StatusInternalServerError, "gc_failed", msg)
+		return
+	}
+	res.SizeAfter = projects.GitDirSize(p.LocalPath)
+	res.DurationMs = time.Since(start).Milliseconds()
+	h.logger.Info("git gc finished",
+		zap.String("project", p.ID),
+		zap.Int64("size_before", res.SizeBefore),
+		zap.Int64("size_after", res.SizeAfter))
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(res)
+}
+
 // ListSecrets handles GET /api/v1/projects/{id}/secrets — returns key names only.
 func (h *ProjectsHandler) ListSecrets(w http.ResponseWriter, r *http.Request) {
 	id := h.urlID(r)
@@ -400,12 +672,50 @@ func (h *ProjectsHandler) GetSecret(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// SetProtected handles PUT /api/v1/projects/{id}/protection with body
+// {"protected": true|false}. Returns the updated project.
+func (h *ProjectsHandler) SetProtected(w http.ResponseWriter, r *http.Request) {
+	id := h.urlID(r)
+	var body struct {
+		Protected *bool `json:"protected"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		respondError(w, http.StatusBadRequest, "INVALID_BODY", err.Error())
+		return
+	}
+	if body.Protected == nil {
+		respondError(w, http.StatusBadRequest, "INVALID_BODY", "protected is required")
+		return
+	}
+	p, err := h.store.GetProject(id)
+	if err != nil {
+		if errors.Is(err, projects.ErrNotFound) {
+			respondError(w, http.StatusNotFound, "NOT_FOUND", "project not found")
+			return
+		}
+		respondError(w, http.StatusInternalServerError, "STORE_ERROR", err.Error())
+		return
+	}
+	p.Protected = *body.Protected
+	if err := h.store.SaveProject(p); err != nil {
+		respondError(w, http.StatusInternalServerError, "STORE_ERROR", err.Error())
+		return
+	}
+	h.logger.Info("project protection changed",
+		zap.String("project_id", p.ID), zap.Bool("protected", p.Protected))
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(p)
+}
+
 // Delete handles DELETE /api/v1/projects/{id}.
 //
 // Tears down each environment via the runner (compose down -v + drop services
 // + cred cleanup), removes the project's repo dir, deletes the project's
 // credential-store entries, and finally removes the project row. Failures
 // during per-env teardown are logged but don't abort the rest of the cascade.
+//
+// A protected project is refused with 409 PROTECTED unless the request
+// carries ?force=true&confirm=<project id>.
 func (h *ProjectsHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	if id == "" {
@@ -421,6 +731,14 @@ func (h *ProjectsHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		respondError(w, http.StatusInternalServerError, "STORE_ERROR", err.Error())
 		return
 	}
+	if project.Protected {
+		q := r.URL.Query()
+		if q.Get("force") != "true" || q.Get("confirm") != project.ID {
+			respondError(w, http.StatusConflict, "PROTECTED", "project is protected — pass ?force=true&confirm="+project.ID+" to delete it anyway")
+			return
+		}
+		h.logger.Warn("deleting protected project (forced)", zap.String("project_id", project.ID))
+	}
 	envs, err := h.store.ListEnvironments(project.ID)
 	if err != nil {
 		respondError(w, http.StatusInternalServerError, "STORE_ERROR", err.Error())
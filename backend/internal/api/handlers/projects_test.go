@@ -14,6 +14,7 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/environment-manager/backend/internal/builder"
 	"github.com/environment-manager/backend/internal/credentials"
@@ -119,6 +120,52 @@ func TestProjectsHandler_Create_Success(t *testing.T) {
 	}
 }
 
+func TestProjectsHandler_Create_DryRun(t *testing.T) {
+	h, dataDir := newTestProjectsHandler(t)
+	repoPath := makeFixtureRepo(t)
+
+	bodyBytes, _ := json.Marshal(map[string]string{"repo_url": fileURL(repoPath)})
+	req := httptest.NewRequest("POST", "/api/v1/projects?dry_run=true", bytes.NewReader(bodyBytes))
+	rec := httptest.NewRecorder()
+	h.Create(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body=%s", rec.Code, rec.Body.String())
+	}
+	var got CreateProjectDryRun
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.DryRun || got.Project.Name != "fixture" || got.Environment.URL == "" {
+		t.Errorf("dry run response = %+v", got)
+	}
+	if list, _ := h.store.ListProjects(); len(list) != 0 {
+		t.Errorf("dry run persisted %d projects", len(list))
+	}
+	if entries, _ := os.ReadDir(filepath.Join(dataDir, "repos")); len(entries) != 0 {
+		t.Errorf("dry run left %d entries in repos dir", len(entries))
+	}
+
+	// A typo in the flag must not fall through to a real create.
+	req = httptest.NewRequest("POST", "/api/v1/projects?dry_run=yes", bytes.NewReader(bodyBytes))
+	rec = httptest.NewRecorder()
+	h.Create(rec, req)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "INVALID_DRY_RUN") {
+		t.Errorf("dry_run=yes: status = %d, body = %s; want 400 INVALID_DRY_RUN", rec.Code, rec.Body.String())
+	}
+	if list, _ := h.store.ListProjects(); len(list) != 0 {
+		t.Errorf("invalid dry_run persisted %d projects", len(list))
+	}
+
+	// A real create afterwards isn't blocked by the dry run.
+	req = httptest.NewRequest("POST", "/api/v1/projects", bytes.NewReader(bodyBytes))
+	rec = httptest.NewRecorder()
+	h.Create(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create after dry run: status = %d; body=%s", rec.Code, rec.Body.String())
+	}
+}
+
 func TestProjectsHandler_Create_RepoMissingDevDir(t *testing.T) {
 	h, _ := newTestProjectsHandler(t)
 	if _, err := exec.LookPath("git"); err != nil {
@@ -349,3 +396,230 @@ func withChiURLParams(req *http.Request, params map[string]string) *http.Request
 	}
 	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
 }
+
+func TestProjectsHandler_Create_WithBuild(t *testing.T) {
+	h, dataDir := newTestProjectsHandler(t)
+	h.runner = builder.NewRunner(h.store, &fakeComposeExec{}, dataDir, "", builder.NewQueue(), zap.NewNop(), nil)
+	repoPath := makeFixtureRepo(t)
+
+	bodyBytes, _ := json.Marshal(map[string]any{"repo_url": fileURL(repoPath), "build": true})
+	req := httptest.NewRequest("POST", "/api/v1/projects", bytes.NewReader(bodyBytes))
+	rec := httptest.NewRecorder()
+	h.Create(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201; body=%s", rec.Code, rec.Body.String())
+	}
+	var got CreateProjectResponse
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.BuildID == "" {
+		t.Fatal("expected build_id when build=true")
+	}
+	// Wait for the async build to finish so TempDir cleanup doesn't race it.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		b, err := h.store.GetBuild(got.Project.ID, got.BuildID)
+		if err != nil {
+			t.Fatalf("GetBuild: %v", err)
+		}
+		if b.TriggeredBy != models.BuildTriggerClone {
+			t.Errorf("triggered_by = %q, want clone", b.TriggeredBy)
+		}
+		if b.Status != models.BuildStatusRunning {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("build did not finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestProjectsHandler_Remote(t *testing.T) {
+	h, _ := newTestProjectsHandler(t)
+	repoPath := makeFixtureRepo(t)
+
+	bodyBytes, _ := json.Marshal(map[string]string{"repo_url": fileURL(repoPath)})
+	rec := httptest.NewRecorder()
+	h.Create(rec, httptest.NewRequest("POST", "/api/v1/projects", bytes.NewReader(bodyBytes)))
+	var created CreateProjectResponse
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	id := created.Project.ID
+
+	check := func() RemoteStatus {
+		t.Helper()
+		req := withChiURLParams(httptest.NewRequest("GET", "/api/v1/projects/"+id+"/remote", nil), map[string]string{"id": id})
+		rec := httptest.NewRecorder()
+		h.Remote(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200; body=%s", rec.Code, rec.Body.String())
+		}
+		var got RemoteStatus
+		_ = json.NewDecoder(rec.Body).Decode(&got)
+		return got
+	}
+
+	if got := check(); !got.Reachable {
+		t.Errorf("expected reachable remote, got %+v", got)
+	}
+	// Remove the origin repo — the clone's remote now points nowhere.
+	if err := os.RemoveAll(repoPath); err != nil {
+		t.Fatal(err)
+	}
+	if got := check(); got.Reachable || got.Error == "" {
+		t.Errorf("expected unreachable remote with error, got %+v", got)
+	}
+}
+
+func TestProjectsHandler_ShallowCloneAndGC(t *testing.T) {
+	h, _ := newTestProjectsHandler(t)
+	h.reposManager.SetCloneDepth(1)
+	repoPath := makeFixtureRepo(t)
+
+	bodyBytes, _ := json.Marshal(map[string]string{"repo_url": fileURL(repoPath)})
+	rec := httptest.NewRecorder()
+	h.Create(rec, httptest.NewRequest("POST", "/api/v1/projects", bytes.NewReader(bodyBytes)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d; body=%s", rec.Code, rec.Body.String())
+	}
+	var created CreateProjectResponse
+	_ = json.NewDecoder(rec.Body).Decode(&created)
+	id := created.Project.ID
+	if _, err := os.Stat(filepath.Join(created.Project.LocalPath, ".git", "shallow")); err != nil {
+		t.Errorf("expected a shallow clone: %v", err)
+	}
+
+	req := withChiURLParams(httptest.NewRequest("POST", "/api/v1/admin/projects/"+id+"/gc", nil), map[string]string{"id": id})
+	rec = httptest.NewRecorder()
+	h.GC(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("gc status = %d; body=%s", rec.Code, rec.Body.String())
+	}
+	var got RepoGCResult
+	_ = json.NewDecoder(rec.Body).Decode(&got)
+	if got.ProjectID != id || got.SizeBefore == 0 || got.SizeAfter == 0 {
+		t.Errorf("unexpected gc result %+v", got)
+	}
+
+	req = withChiURLParams(httptest.NewRequest("POST", "/api/v1/admin/projects/nope/gc", nil), map[string]string{"id": "nope"})
+	rec = httptest.NewRecorder()
+	h.GC(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown project: status = %d, want 404", rec.Code)
+	}
+}
+
+// recordingSpawner is an EnvSpawner that only records what it was asked.
+type recordingSpawner struct {
+	spawned []string
+}
+
+func (s *recordingSpawner) SpawnPreview(_ context.Context, _ *models.Project, branch, _ string) error {
+	s.spawned = append(s.spawned, branch)
+	return nil
+}
+
+func (s *recordingSpawner) Teardown(context.Context, *models.Environment) error { return nil }
+
+func TestProjectsHandler_Reconcile(t *testing.T) {
+	h, _ := newTestProjectsHandler(t)
+	repoPath := makeFixtureRepo(t)
+
+	bodyBytes, _ := json.Marshal(map[string]string{"repo_url": fileURL(repoPath)})
+	rec := httptest.NewRecorder()
+	h.Create(rec, httptest.NewRequest("POST", "/api/v1/projects", bytes.NewReader(bodyBytes)))
+	var created CreateProjectResponse
+	_ = json.NewDecoder(rec.Body).Decode(&created)
+	id := created.Project.ID
+
+	reconcile := func() *httptest.ResponseRecorder {
+		req := withChiURLParams(httptest.NewRequest("POST", "/api/v1/projects/"+id+"/reconcile", nil), map[string]string{"id": id})
+		rec := httptest.NewRecorder()
+		h.Reconcile(rec, req)
+		return rec
+	}
+	if rec := reconcile(); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("no spawner: status = %d, want 503", rec.Code)
+	}
+
+	sp := &recordingSpawner{}
+	h.SetSpawner(sp)
+	// A branch pushed while nobody was listening for webhooks.
+	if out, err := exec.Command("git", "-C", repoPath, "branch", "feature-y").CombinedOutput(); err != nil {
+		t.Fatalf("git branch: %v\n%s", err, out)
+	}
+	rec = reconcile()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d; body=%s", rec.Code, rec.Body.String())
+	}
+	var got ReconcileResult
+	_ = json.NewDecoder(rec.Body).Decode(&got)
+	if len(sp.spawned) != 1 || sp.spawned[0] != "feature-y" || len(got.Changes) != 1 {
+		t.Errorf("spawned = %v, changes = %v; want feature-y only", sp.spawned, got.Changes)
+	}
+}
+
+func TestProjectsHandler_SetNotes(t *testing.T) {
+	h, _ := newTestProjectsHandler(t)
+	_ = h.store.SaveProject(&models.Project{ID: "p1", Name: "myapp"})
+
+	put := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PUT", "/api/v1/projects/p1/notes", strings.NewReader(body))
+		req = withChiURLParams(req, map[string]string{"id": "p1"})
+		rec := httptest.NewRecorder()
+		h.SetNotes(rec, req)
+		return rec
+	}
+	if rec := put(`{"notes":"prod DB — do not delete"}`); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d; body = %s", rec.Code, rec.Body.String())
+	}
+	p, _ := h.store.GetProject("p1")
+	if p.Notes != "prod DB — do not delete" {
+		t.Errorf("notes = %q", p.Notes)
+	}
+	if rec := put(`{"notes":"` + strings.Repeat("x", maxNotesLen+1) + `"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("oversized notes: status = %d, want 400", rec.Code)
+	}
+	if rec := put(`{"notes":""}`); rec.Code != http.StatusOK {
+		t.Fatalf("clear: status = %d", rec.Code)
+	}
+	if p, _ := h.store.GetProject("p1"); p.Notes != "" {
+		t.Errorf("notes not cleared: %q", p.Notes)
+	}
+}
+
+func TestProjectsHandler_Delete_Protected(t *testing.T) {
+	h, _ := newTestProjectsHandler(t)
+	_ = h.store.SaveProject(&models.Project{ID: "p1", Name: "myapp"})
+
+	req := withChiURLParams(httptest.NewRequest("PUT", "/api/v1/projects/p1/protection", strings.NewReader(`{"protected":true}`)), map[string]string{"id": "p1"})
+	rec := httptest.NewRecorder()
+	h.SetProtected(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("protect: status = %d; body = %s", rec.Code, rec.Body.String())
+	}
+
+	del := func(query string) int {
+		req := withChiURLParams(httptest.NewRequest("DELETE", "/api/v1/projects/p1"+query, nil), map[string]string{"id": "p1"})
+		rec := httptest.NewRecorder()
+		h.Delete(rec, req)
+		return rec.Code
+	}
+	for _, q := range []string{"", "?force=true", "?force=true&confirm=other"} {
+		if code := del(q); code != http.StatusConflict {
+			t.Errorf("DELETE%s: status = %d, want 409", q, code)
+		}
+	}
+	if _, err := h.store.GetProject("p1"); err != nil {
+		t.Fatalf("protected project was deleted: %v", err)
+	}
+	if code := del("?force=true&confirm=p1"); code != http.StatusOK {
+		t.Fatalf("forced delete: status = %d, want 200", code)
+	}
+	if _, err := h.store.GetProject("p1"); !errors.Is(err, projects.ErrNotFound) {
+		t.Errorf("expected project removed, got %v", err)
+	}
+}
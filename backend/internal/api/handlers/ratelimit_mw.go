@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimit returns a middleware that caps each client at rps requests per
+// second with bursts up to burst. Clients are keyed by the connecting
+// address PeerAddr saw; only when that peer is one of trusted is the
+// forwarded address RealIP put in RemoteAddr used instead. Otherwise
+// anyone could mint a fresh bucket per request by forging
+// X-Forwarded-For. Over-limit requests get 429 with a Retry-After hint.
+//
+// The point is to shield the Docker daemon, which serializes many calls,
+// from a runaway client such as a dashboard stuck in a tight polling loop.
+//
+// rps <= 0 disables limiting (no-op middleware).
+func RateLimit(rps float64, burst int, trusted []netip.Prefix) func(http.Handler) http.Handler {
+	if rps <= 0 {
+		return func(h http.Handler) http.Handler { return h }
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	l := &rateLimiter{rps: rps, burst: float64(burst), buckets: map[string]*bucket{}, now: time.Now}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ok, wait := l.allow(clientKey(r, trusted))
+			if !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				respondError(w, http.StatusTooManyRequests, "RATE_LIMITED", "too many requests — slow down")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// bucket is one client's token bucket.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter is a per-key token bucket. Idle buckets are swept
+// opportunistically so the map doesn't grow with every IP ever seen.
+type rateLimiter struct {
+	mu        sync.Mutex
+	rps       float64
+	burst     float64
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time // injectable for tests
+}
+
+// idleTTL is how long a bucket may go untouched before it's dropped. Any
+// bucket idle this long has refilled to burst anyway, so dropping it loses
+// nothing.
+const idleTTL = 10 * time.Minute
+
+// allow takes a token for key. When none is available it reports how long
+// until the next one is.
+func (l *rateLimiter) allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) > idleTTL {
+		for k, b := range l.buckets {
+			if now.Sub(b.last) > idleTTL {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rps)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / l.rps * float64(time.Second))
+}
+
+type peerAddrKey struct{}
+
+// PeerAddr records the request's RemoteAddr as the TCP peer, before
+// middleware.RealIP rewrites it from forwarding headers. Must run ahead of
+// RealIP.
+func PeerAddr(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), peerAddrKey{}, r.RemoteAddr)))
+	})
+}
+
+// clientKey returns the IP to rate-limit r under: the TCP peer, or the
+// forwarded client address when the peer is a trusted proxy.
+func clientKey(r *http.Request, trusted []netip.Prefix) string {
+	peer, ok := r.Context().Value(peerAddrKey{}).(string)
+	if !ok {
+		peer = r.RemoteAddr
+	}
+	peerIP := hostOnly(peer)
+	if a, err := netip.ParseAddr(peerIP); err == nil {
+		for _, p := range trusted {
+			if p.Contains(a.Unmap()) {
+				return hostOnly(r.RemoteAddr)
+			}
+		}
+	}
+	return peerIP
+}
+
+// hostOnly strips the port when addr carries one.
+func hostOnly(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
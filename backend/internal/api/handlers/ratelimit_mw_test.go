@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestRateLimit_Disabled_PassesThrough(t *testing.T) {
+	mw := RateLimit(0, 0, nil)
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for i := 0; i < 100; i++ {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/x", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200", i, rec.Code)
+		}
+	}
+}
+
+func TestRateLimit_BurstThen429(t *testing.T) {
+	mw := RateLimit(1, 3, nil)
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	do := func(addr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/x", nil)
+		req.RemoteAddr = addr
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+	for i := 0; i < 3; i++ {
+		if rec := do("10.0.0.1:1234"); rec.Code != http.StatusOK {
+			t.Fatalf("burst request %d: status = %d", i, rec.Code)
+		}
+	}
+	rec := do("10.0.0.1:5678")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("missing Retry-After header")
+	}
+	// A different client has its own bucket.
+	if rec := do("10.0.0.2:1234"); rec.Code != http.StatusOK {
+		t.Errorf("other client: status = %d, want 200", rec.Code)
+	}
+}
+
+func TestRateLimiter_Refills(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := &rateLimiter{rps: 2, burst: 1, buckets: map[string]*bucket{}, now: func() time.Time { return now }}
+	if ok, _ := l.allow("a"); !ok {
+		t.Fatal("first request should pass")
+	}
+	ok, wait := l.allow("a")
+	if ok {
+		t.Fatal("second immediate request should be limited")
+	}
+	if wait != 500*time.Millisecond {
+		t.Errorf("wait = %v, want 500ms", wait)
+	}
+	now = now.Add(500 * time.Millisecond)
+	if ok, _ := l.allow("a"); !ok {
+		t.Error("request after refill should pass")
+	}
+}
+
+func TestClientKey(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("172.18.0.0/16")}
+	for _, c := range []struct {
+		name, peer, remote string
+		want               string
+	}{
+		// RealIP rewrote RemoteAddr from a header the client sent itself.
+		{"forged header ignored", "10.0.0.9:5000", "1.2.3.4", "10.0.0.9"},
+		{"trusted proxy forwards", "172.18.0.2:5000", "1.2.3.4", "1.2.3.4"},
+		{"no rewrite", "10.0.0.9:5000", "10.0.0.9:5000", "10.0.0.9"},
+	} {
+		req := httptest.NewRequest("GET", "/x", nil)
+		req = req.WithContext(context.WithValue(req.Context(), peerAddrKey{}, c.peer))
+		req.RemoteAddr = c.remote
+		if got := clientKey(req, trusted); got != c.want {
+			t.Errorf("%s: key = %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestRateLimit_ForgedForwardedForSharesBucket(t *testing.T) {
+	var h http.Handler = RateLimit(1, 1, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	// What the router does: PeerAddr, then RealIP's rewrite.
+	rewrite := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.RemoteAddr = r.Header.Get("X-Forwarded-For")
+			next.ServeHTTP(w, r)
+		})
+	}
+	h = PeerAddr(rewrite(h))
+	codes := make([]int, 2)
+	for i, xff := range []string{"1.1.1.1", "2.2.2.2"} {
+		req := httptest.NewRequest("GET", "/x", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("X-Forwarded-For", xff)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		codes[i] = rec.Code
+	}
+	if codes[1] != http.StatusTooManyRequests {
+		t.Errorf("statuses = %v, want the second request limited despite a new X-Forwarded-For", codes)
+	}
+}
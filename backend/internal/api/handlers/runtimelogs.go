@@ -1,12 +1,15 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/docker/docker/pkg/stdcopy"
@@ -21,7 +24,9 @@ import (
 // Implemented by *docker.Client.
 type RuntimeLogStreamer interface {
 	GetContainerLogs(id string, follow bool, tail string, since time.Time) (io.ReadCloser, error)
+	ContainerLogsRange(ctx context.Context, id, tail string, since, until time.Time) (io.ReadCloser, error)
 	ContainerStatus(ctx context.Context, name string) (exists, running bool, err error)
+	ContainerID(ctx context.Context, name string) (id string, running bool, err error)
 }
 
 // RuntimeLogsHandler exposes WS endpoints that stream `docker logs -f` output
@@ -64,14 +69,25 @@ var allowedSingletonServices = map[string]bool{
 // omitted. Resolves the container name as `<env_id>-<service>-1` (Docker
 // Compose's default naming), streams `docker logs -f` to the WS client.
 //
-// Closes when the container stops, when the client disconnects, or on
+// Survives container restarts and redeploys (see streamContainer); closes
+// when the container stays down, when the client disconnects, or on
 // underlying read error. Best-effort; not a guaranteed-delivery channel.
 func (h *RuntimeLogsHandler) StreamEnv(w http.ResponseWriter, r *http.Request) {
+	containerName, ok := h.envContainer(w, r)
+	if !ok {
+		return
+	}
+	h.streamContainer(w, r, containerName)
+}
+
+// envContainer resolves the {id} env + ?service= pair on r to the compose
+// container name, writing an HTTP error and returning false when it can't.
+func (h *RuntimeLogsHandler) envContainer(w http.ResponseWriter, r *http.Request) (string, bool) {
 	envID := chi.URLParam(r, "id")
 	projectID, branchSlug, ok := splitEnvID(envID)
 	if !ok {
 		http.Error(w, "invalid env id", http.StatusBadRequest)
-		return
+		return "", false
 	}
 	service := strings.TrimSpace(r.URL.Query().Get("service"))
 	if service == "" {
@@ -83,26 +99,25 @@ func (h *RuntimeLogsHandler) StreamEnv(w http.ResponseWriter, r *http.Request) {
 	}
 	if service == "" {
 		http.Error(w, "service query param required", http.StatusBadRequest)
-		return
+		return "", false
 	}
 	// Reject path-traversal-ish service names.
 	if strings.ContainsAny(service, "/\\") || service == ".." {
 		http.Error(w, "invalid service name", http.StatusBadRequest)
-		return
+		return "", false
 	}
 
 	if _, err := h.store.GetEnvironment(projectID, branchSlug); err != nil {
 		if errors.Is(err, projects.ErrNotFound) {
 			http.Error(w, "env not found", http.StatusNotFound)
-			return
+			return "", false
 		}
 		http.Error(w, "store error: "+err.Error(), http.StatusInternalServerError)
-		return
+		return "", false
 	}
 
 	// Compose default naming convention.
-	containerName := envID + "-" + service + "-1"
-	h.streamContainer(w, r, containerName)
+	return envID + "-" + service + "-1", true
 }
 
 // StreamService handles WS /ws/services/{name}/runtime-logs.
@@ -118,8 +133,22 @@ func (h *RuntimeLogsHandler) StreamService(w http.ResponseWriter, r *http.Reques
 	h.streamContainer(w, r, name)
 }
 
+// reattachPoll / reattachWait bound how long a follow waits for a stopped
+// container to come back before the stream is closed. Vars so tests can
+// shrink them.
+var (
+	reattachPoll = time.Second
+	reattachWait = 2 * time.Minute
+)
+
 // streamContainer is the shared streaming implementation. Returns on any
 // error; logs at warn level so disconnects don't spam.
+//
+// Docker's follow ends when the container stops. A redeploy (compose
+// recreates it: new ID, same name) or a restart-policy restart is routine,
+// so rather than going silent the stream waits for a running container under
+// the same name and re-attaches, marking the seam with
+// "--- container restarted ---".
 func (h *RuntimeLogsHandler) streamContainer(w http.ResponseWriter, r *http.Request, containerName string) {
 	if h.docker == nil {
 		http.Error(w, "docker client unavailable", http.StatusServiceUnavailable)
@@ -132,67 +161,277 @@ func (h *RuntimeLogsHandler) streamContainer(w http.ResponseWriter, r *http.Requ
 	}
 	defer conn.Close()
 
-	exists, _, err := h.docker.ContainerStatus(r.Context(), containerName)
+	id, _, err := h.docker.ContainerID(r.Context(), containerName)
 	if err != nil {
 		_ = conn.WriteJSON(map[string]string{"error": "container status: " + err.Error()})
 		return
 	}
-	if !exists {
+	if id == "" {
 		_ = conn.WriteJSON(map[string]string{"error": "container not found: " + containerName})
 		return
 	}
 
+	gone, stop := wsKeepalive(conn)
+	defer stop()
+
 	// Stream from "1m ago" so freshly-mounted clients see recent context.
-	since := time.Now().Add(-1 * time.Minute)
-	rc, err := h.docker.GetContainerLogs(containerName, true, "200", since)
+	since, tail := time.Now().Add(-1*time.Minute), "200"
+	for {
+		if !h.followLogs(conn, containerName, id, tail, since, gone) {
+			return
+		}
+		// Only lines written after the follow ended are new — whether the
+		// same container restarted or a fresh one replaced it.
+		since, tail = time.Now(), "all"
+		newID, ok := h.awaitContainer(r.Context(), containerName, gone)
+		if !ok {
+			_ = wsWrite(conn, []byte("\n--- container stopped ---\n"))
+			return
+		}
+		if wsWrite(conn, []byte("\n--- container restarted ---\n")) != nil {
+			return
+		}
+		id = newID
+	}
+}
+
+// followLogs streams one container's (by ID) followed logs to conn. Returns
+// true when docker ended the follow — the container stopped — with the
+// client still connected; false when the client went away or on error.
+func (h *RuntimeLogsHandler) followLogs(conn *websocket.Conn, name, id, tail string, since time.Time, gone <-chan struct{}) bool {
+	rc, err := h.docker.GetContainerLogs(id, true, tail, since)
 	if err != nil {
 		_ = conn.WriteJSON(map[string]string{"error": "get logs: " + err.Error()})
-		return
+		return false
 	}
 	defer rc.Close()
 
 	// stdcopy demultiplexes Docker's multiplexed log stream (8-byte header
 	// per chunk) into stdout/stderr. We forward both to the WS as text.
 	pr, pw := io.Pipe()
+	defer pr.Close() // unblocks the copier if we return mid-stream
 	go func() {
 		defer pw.Close()
 		_, _ = stdcopy.StdCopy(pw, pw, rc)
 	}()
 
-	// Reader → WS message pump. Bounded buffer; drop on slow consumer.
-	buf := make([]byte, 4096)
-	var writeMu sync.Mutex
-	// Goroutine for client → server: handles ping/pong + close detection.
-	closeCh := make(chan struct{})
+	// An idle container leaves pr.Read blocked indefinitely; closing the
+	// docker reader when the client goes away unblocks it, so the stream
+	// never outlives the socket.
+	done := make(chan struct{})
+	defer close(done)
 	go func() {
-		defer close(closeCh)
-		for {
-			if _, _, err := conn.NextReader(); err != nil {
-				return
-			}
+		select {
+		case <-gone:
+			_ = rc.Close()
+		case <-done:
 		}
 	}()
 
+	buf := make([]byte, 4096)
 	for {
-		select {
-		case <-closeCh:
-			return
-		default:
-		}
 		n, rerr := pr.Read(buf)
 		if n > 0 {
-			writeMu.Lock()
-			werr := conn.WriteMessage(websocket.TextMessage, buf[:n])
-			writeMu.Unlock()
-			if werr != nil {
-				return
+			if werr := wsWrite(conn, buf[:n]); werr != nil {
+				return false
 			}
 		}
 		if rerr != nil {
 			if rerr != io.EOF {
-				h.logger.Debug("runtime log stream ended", zap.String("container", containerName), zap.Error(rerr))
+				h.logger.Debug("runtime log stream ended", zap.String("container", name), zap.Error(rerr))
+				return false
+			}
+			select {
+			case <-gone:
+				return false
+			default:
+				return true
 			}
+		}
+	}
+}
+
+// awaitContainer polls for a running container called name until one shows
+// up, the client goes away, or reattachWait passes.
+func (h *RuntimeLogsHandler) awaitContainer(ctx context.Context, name string, gone <-chan struct{}) (string, bool) {
+	deadline := time.NewTimer(reattachWait)
+	defer deadline.Stop()
+	tick := time.NewTicker(reattachPoll)
+	defer tick.Stop()
+	for {
+		select {
+		case <-gone:
+			return "", false
+		case <-deadline.C:
+			return "", false
+		case <-tick.C:
+		}
+		id, running, err := h.docker.ContainerID(ctx, name)
+		if err == nil && id != "" && running {
+			return id, true
+		}
+	}
+}
+
+// DownloadEnv handles GET /api/v1/envs/{id}/runtime-logs/download.
+//
+// Same service resolution as StreamEnv; returns the logs as a plain-text
+// attachment instead of a live stream. See downloadContainer for the
+// since / until / tail parameters.
+func (h *RuntimeLogsHandler) DownloadEnv(w http.ResponseWriter, r *http.Request) {
+	containerName, ok := h.envContainer(w, r)
+	if !ok {
+		return
+	}
+	h.downloadContainer(w, r, containerName)
+}
+
+// DownloadService handles GET /api/v1/services/{name}/runtime-logs/download.
+// Same allowlist as StreamService.
+func (h *RuntimeLogsHandler) DownloadService(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	if !allowedSingletonServices[name] {
+		http.Error(w, "service not allowed", http.StatusBadRequest)
+		return
+	}
+	h.downloadContainer(w, r, name)
+}
+
+// downloadContainer writes a container's demultiplexed logs as an attachment
+// named <container>-<UTC timestamp>.log (.ndjson with format=json), for
+// pasting into bug reports.
+//
+// Query params:
+//   - since / until: RFC 3339 timestamp, or a Go duration meaning "that
+//     long ago" (e.g. 30m). Both optional.
+//   - tail: last N lines (default: all).
+//   - format: "text" (default) or "json" — newline-delimited JSON, one
+//     {"stream","timestamp","message"} object per line, for log shippers.
+func (h *RuntimeLogsHandler) downloadContainer(w http.ResponseWriter, r *http.Request, containerName string) {
+	if h.docker == nil {
+		http.Error(w, "docker client unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	q := r.URL.Query()
+	now := time.Now()
+	since, err := parseLogTime(q.Get("since"), now)
+	if err != nil {
+		http.Error(w, "invalid since: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	until, err := parseLogTime(q.Get("until"), now)
+	if err != nil {
+		http.Error(w, "invalid until: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	tail := q.Get("tail")
+	if tail != "" && tail != "all" {
+		if n, err := strconv.Atoi(tail); err != nil || n < 0 {
+			http.Error(w, "tail must be a non-negative integer or \"all\"", http.StatusBadRequest)
 			return
 		}
 	}
+	format := q.Get("format")
+	if format != "" && format != "text" && format != "json" {
+		http.Error(w, "format must be \"text\" or \"json\"", http.StatusBadRequest)
+		return
+	}
+
+	exists, _, err := h.docker.ContainerStatus(r.Context(), containerName)
+	if err != nil {
+		http.Error(w, "container status: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	if !exists {
+		http.Error(w, "container not found: "+containerName, http.StatusNotFound)
+		return
+	}
+	rc, err := h.docker.ContainerLogsRange(r.Context(), containerName, tail, since, until)
+	if err != nil {
+		http.Error(w, "get logs: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer rc.Close()
+
+	stamp := now.UTC().Format("20060102T150405Z")
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.ndjson"`, containerName, stamp))
+		enc := json.NewEncoder(w)
+		stdout := &ndjsonLogWriter{stream: "stdout", enc: enc}
+		stderr := &ndjsonLogWriter{stream: "stderr", enc: enc}
+		_, err = stdcopy.StdCopy(stdout, stderr, rc)
+		stdout.flush()
+		stderr.flush()
+	} else {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.log"`, containerName, stamp))
+		_, err = stdcopy.StdCopy(w, w, rc)
+	}
+	if err != nil {
+		// Headers are already out; all we can do is note it.
+		h.logger.Debug("runtime log download ended early", zap.String("container", containerName), zap.Error(err))
+	}
+}
+
+// logLine is one line of a format=json log download.
+type logLine struct {
+	Stream    string `json:"stream"`
+	Timestamp string `json:"timestamp,omitempty"`
+	Message   string `json:"message"`
+}
+
+// ndjsonLogWriter receives one demultiplexed stream and encodes each
+// complete line as a logLine. Docker prefixes lines with an RFC 3339 nano
+// timestamp (ContainerLogsRange asks for them), which is split off into
+// Timestamp. Not safe for concurrent use; StdCopy writes from one goroutine.
+type ndjsonLogWriter struct {
+	stream  string
+	enc     *json.Encoder
+	partial []byte
+}
+
+func (n *ndjsonLogWriter) Write(p []byte) (int, error) {
+	n.partial = append(n.partial, p...)
+	for {
+		i := bytes.IndexByte(n.partial, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		if err := n.emit(n.partial[:i]); err != nil {
+			return 0, err
+		}
+		n.partial = n.partial[i+1:]
+	}
+}
+
+// flush encodes a trailing line that had no newline.
+func (n *ndjsonLogWriter) flush() {
+	if len(n.partial) > 0 {
+		_ = n.emit(n.partial)
+		n.partial = nil
+	}
+}
+
+func (n *ndjsonLogWriter) emit(line []byte) error {
+	msg := strings.TrimSuffix(string(line), "\r")
+	l := logLine{Stream: n.stream, Message: msg}
+	if ts, rest, ok := strings.Cut(msg, " "); ok {
+		if _, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+			l.Timestamp, l.Message = ts, rest
+		}
+	}
+	return n.enc.Encode(l)
+}
+
+// parseLogTime parses a since/until value: empty → zero time, a Go duration
+// → that long before now, otherwise RFC 3339.
+func parseLogTime(v string, now time.Time) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(v); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, v)
 }
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"github.com/environment-manager/backend/internal/models"
+	"github.com/environment-manager/backend/internal/projects"
+)
+
+// fakeLogDocker serves a fixed multiplexed log stream for one container.
+type fakeLogDocker struct {
+	name     string
+	gotTail  string
+	gotSince time.Time
+	stdout   string
+	stderr   string
+}
+
+func (f *fakeLogDocker) GetContainerLogs(string, bool, string, time.Time) (io.ReadCloser, error) {
+	return nil, nil
+}
+
+func (f *fakeLogDocker) ContainerLogsRange(_ context.Context, _ string, tail string, since, _ time.Time) (io.ReadCloser, error) {
+	f.gotTail, f.gotSince = tail, since
+	var buf bytes.Buffer
+	_, _ = stdcopy.NewStdWriter(&buf, stdcopy.Stdout).Write([]byte(f.stdout))
+	_, _ = stdcopy.NewStdWriter(&buf, stdcopy.Stderr).Write([]byte(f.stderr))
+	return io.NopCloser(&buf), nil
+}
+
+func (f *fakeLogDocker) ContainerStatus(_ context.Context, name string) (bool, bool, error) {
+	return name == f.name, name == f.name, nil
+}
+
+func (f *fakeLogDocker) ContainerID(_ context.Context, name string) (string, bool, error) {
+	if name != f.name {
+		return "", false, nil
+	}
+	return "c1", true, nil
+}
+
+// restartingLogDocker plays a container that stops after one log line and
+// comes back under a new ID, then stops for good.
+type restartingLogDocker struct {
+	fakeLogDocker
+	mu       sync.Mutex
+	lookups  int
+	attached []string
+}
+
+func (f *restartingLogDocker) ContainerID(_ context.Context, _ string) (string, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lookups++
+	switch f.lookups {
+	case 1:
+		return "c1", true, nil
+	case 2:
+		return "c2", true, nil // recreated
+	default:
+		return "c2", false, nil // stopped for good
+	}
+}
+
+func (f *restartingLogDocker) GetContainerLogs(id string, _ bool, _ string, _ time.Time) (io.ReadCloser, error) {
+	f.mu.Lock()
+	f.attached = append(f.attached, id)
+	f.mu.Unlock()
+	var buf bytes.Buffer
+	_, _ = stdcopy.NewStdWriter(&buf, stdcopy.Stdout).Write([]byte("from " + id + "\n"))
+	return io.NopCloser(&buf), nil
+}
+
+func TestRuntimeLogsHandler_StreamReattachesAfterRestart(t *testing.T) {
+	oldPoll, oldWait := reattachPoll, reattachWait
+	reattachPoll, reattachWait = 10*time.Millisecond, 100*time.Millisecond
+	defer func() { reattachPoll, reattachWait = oldPoll, oldWait }()
+
+	docker := &restartingLogDocker{}
+	h := NewRuntimeLogsHandler(docker, nil, zap.NewNop(), nil)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.streamContainer(w, r, "p1--main-web-1")
+	}))
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var got strings.Builder
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			break
+		}
+		got.Write(msg)
+	}
+	want := "from c1\n\n--- container restarted ---\nfrom c2\n\n--- container stopped ---\n"
+	if got.String() != want {
+		t.Errorf("stream = %q, want %q", got.String(), want)
+	}
+	if len(docker.attached) != 2 || docker.attached[1] != "c2" {
+		t.Errorf("attached = %v, want [c1 c2]", docker.attached)
+	}
+}
+
+func TestRuntimeLogsHandler_DownloadEnv(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := projects.NewStore(dir)
+	_ = store.SaveEnvironment(&models.Environment{ID: "p1--main", ProjectID: "p1", BranchSlug: "main"})
+	docker := &fakeLogDocker{name: "p1--main-web-1", stdout: "hello\n", stderr: "oops\n"}
+	h := NewRuntimeLogsHandler(docker, store, zap.NewNop(), nil)
+
+	req := httptest.NewRequest("GET", "/api/v1/envs/p1--main/runtime-logs/download?service=web&since=1h&tail=50", nil)
+	req = withChiURLParams(req, map[string]string{"id": "p1--main"})
+	rec := httptest.NewRecorder()
+	h.DownloadEnv(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d; body = %s", rec.Code, rec.Body.String())
+	}
+	cd := rec.Header().Get("Content-Disposition")
+	if !strings.HasPrefix(cd, `attachment; filename="p1--main-web-1-`) || !strings.HasSuffix(cd, `.log"`) {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+	if got := rec.Body.String(); got != "hello\noops\n" {
+		t.Errorf("body = %q, want demultiplexed stdout+stderr", got)
+	}
+	if docker.gotTail != "50" {
+		t.Errorf("tail = %q, want 50", docker.gotTail)
+	}
+	if d := time.Since(docker.gotSince); d < 59*time.Minute || d > 61*time.Minute {
+		t.Errorf("since = %v ago, want ~1h", d)
+	}
+}
+
+func TestRuntimeLogsHandler_DownloadEnv_BadParams(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := projects.NewStore(dir)
+	_ = store.SaveEnvironment(&models.Environment{ID: "p1--main", ProjectID: "p1", BranchSlug: "main"})
+	h := NewRuntimeLogsHandler(&fakeLogDocker{name: "p1--main-web-1"}, store, zap.NewNop(), nil)
+
+	for _, q := range []string{"since=yesterday", "tail=-1", "until=10", "format=xml"} {
+		req := httptest.NewRequest("GET", "/x?service=web&"+q, nil)
+		req = withChiURLParams(req, map[string]string{"id": "p1--main"})
+		rec := httptest.NewRecorder()
+		h.DownloadEnv(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", q, rec.Code)
+		}
+	}
+}
+
+func TestRuntimeLogsHandler_DownloadJSON(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := projects.NewStore(dir)
+	_ = store.SaveEnvironment(&models.Environment{ID: "p1--main", ProjectID: "p1", BranchSlug: "main"})
+	docker := &fakeLogDocker{
+		name:   "p1--main-web-1",
+		stdout: "2026-10-16T09:00:00.123456789Z hello world\n2026-10-16T09:00:01Z no newline",
+		stderr: "2026-10-16T09:00:02Z oops\n",
+	}
+	h := NewRuntimeLogsHandler(docker, store, zap.NewNop(), nil)
+
+	req := withChiURLParams(httptest.NewRequest("GET", "/x?service=web&format=json", nil), map[string]string{"id": "p1--main"})
+	rec := httptest.NewRecorder()
+	h.DownloadEnv(rec, req)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/x-ndjson" {
+		t.Fatalf("status = %d, type = %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	var got []logLine
+	dec := json.NewDecoder(rec.Body)
+	for dec.More() {
+		var l logLine
+		if err := dec.Decode(&l); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, l)
+	}
+	want := []logLine{
+		{Stream: "stdout", Timestamp: "2026-10-16T09:00:00.123456789Z", Message: "hello world"},
+		{Stream: "stderr", Timestamp: "2026-10-16T09:00:02Z", Message: "oops"},
+		{Stream: "stdout", Timestamp: "2026-10-16T09:00:01Z", Message: "no newline"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -3,20 +3,33 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"time"
+
+	"github.com/environment-manager/backend/internal/models"
 )
 
-// ContainerInspector exposes only the container-status query needed by the
+// ContainerInspector exposes the container-status queries needed by the
 // services handler. Implemented by *docker.Client.
 type ContainerInspector interface {
 	ContainerStatus(ctx context.Context, name string) (exists, running bool, err error)
+	ContainerState(ctx context.Context, name string) (models.ContainerState, error)
+}
+
+// ContainerRestarter restarts a container by name as a stop that reports
+// how the container went down, then a start. Implemented by *docker.Client.
+type ContainerRestarter interface {
+	StopContainerReport(ctx context.Context, name string, timeout *int) (models.StopReport, error)
+	StartContainer(id string) error
 }
 
-// ServicesHandler exposes /api/v1/services/{postgres,redis} status endpoints.
-// Read-only; used by envm services status and the eventual UI Services page.
+// ServicesHandler exposes /api/v1/services/{postgres,redis} status endpoints,
+// used by envm services status and the UI Services page, plus a restart of
+// the whole service plane.
 type ServicesHandler struct {
-	docker ContainerInspector
+	docker    ContainerInspector
+	restarter ContainerRestarter
 }
 
 // NewServicesHandler wires the inspector. Pass nil to disable docker queries —
@@ -25,11 +38,34 @@ func NewServicesHandler(docker ContainerInspector) *ServicesHandler {
 	return &ServicesHandler{docker: docker}
 }
 
+// SetRestarter enables POST /services/restart. Without it the endpoint
+// returns 503.
+func (h *ServicesHandler) SetRestarter(r ContainerRestarter) {
+	h.restarter = r
+}
+
+// servicePlane lists the singleton containers the server manages, in the
+// order they are restarted.
+var servicePlane = []struct{ name, image string }{
+	{"paas-postgres", "postgres:16"},
+	{"paas-redis", "redis:7"},
+}
+
+// serviceStatus is the response body. ExitCode and OOMKilled are populated
+// from the last run when the container exists but is stopped, so operators
+// can tell a clean exit (0) from a crash (1, 137/OOM) without reading logs.
 type serviceStatus struct {
 	Container string `json:"container"`
 	Image     string `json:"image"`
 	Running   bool   `json:"running"`
 	Exists    bool   `json:"exists"`
+	State     string `json:"state,omitempty"`
+	ExitCode  int    `json:"exit_code"`
+	OOMKilled bool   `json:"oom_killed"`
+	// ImageID / ImageDigest identify the image the container actually
+	// runs, which can lag Image (the configured tag) after a re-tag.
+	ImageID     string `json:"image_id,omitempty"`
+	ImageDigest string `json:"image_digest,omitempty"`
 }
 
 // Postgres handles GET /api/v1/services/postgres.
@@ -42,21 +78,91 @@ func (h *ServicesHandler) Redis(w http.ResponseWriter, r *http.Request) {
 	h.respond(w, "paas-redis", "redis:7")
 }
 
+// respond degrades docker errors to exists=false — except timeouts, which
+// return 504 so a hung daemon isn't mistaken for a missing container.
 func (h *ServicesHandler) respond(w http.ResponseWriter, name, image string) {
-	exists, running := false, false
-	if h.docker != nil {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		e, run, err := h.docker.ContainerStatus(ctx, name)
+	status, err := h.status(name, image)
+	if err != nil {
+		respondError(w, http.StatusGatewayTimeout, "TIMEOUT", "docker: "+err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(status)
+}
+
+// status inspects one container. The only error returned is a docker
+// timeout; every other failure degrades to exists=false.
+func (h *ServicesHandler) status(name, image string) (serviceStatus, error) {
+	status := serviceStatus{Container: name, Image: image}
+	if h.docker == nil {
+		return status, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	e, run, err := h.docker.ContainerStatus(ctx, name)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return status, err
+	}
+	if err == nil {
+		status.Exists, status.Running = e, run
+	}
+	if status.Exists {
+		if st, serr := h.docker.ContainerState(ctx, name); serr == nil {
+			status.State = st.Status
+			status.ImageID, status.ImageDigest = st.ImageID, st.ImageDigest
+			if !st.Running {
+				status.ExitCode = st.ExitCode
+				status.OOMKilled = st.OOMKilled
+			}
+		}
+	}
+	return status, nil
+}
+
+// RestartResult reports one service-plane container after a restart.
+// Stop says whether the old process exited on SIGTERM or had to be killed
+// — a service that keeps ignoring SIGTERM risks losing writes on every
+// restart. Error is set when docker refused the stop or start; Status is
+// still filled so the caller sees what the container is doing now.
+type RestartResult struct {
+	Status serviceStatus     `json:"status"`
+	Stop   models.StopReport `json:"stop"`
+	Error  string            `json:"error,omitempty"`
+}
+
+// Restart handles POST /api/v1/services/restart. It restarts paas-postgres
+// and paas-redis so they pick up network or config changes, and returns
+// their status afterwards. A failure on one container doesn't stop the
+// other; the response is 200 with per-container errors, or 502 when every
+// restart failed.
+func (h *ServicesHandler) Restart(w http.ResponseWriter, r *http.Request) {
+	if h.restarter == nil {
+		respondError(w, http.StatusServiceUnavailable, "DOCKER_UNAVAILABLE", "container restart is not configured")
+		return
+	}
+	results := make([]RestartResult, 0, len(servicePlane))
+	failed := 0
+	for _, svc := range servicePlane {
+		var res RestartResult
+		stop, err := h.restarter.StopContainerReport(r.Context(), svc.name, nil)
 		if err == nil {
-			exists, running = e, run
+			err = h.restarter.StartContainer(svc.name)
+		}
+		res.Stop = stop
+		if err != nil {
+			res.Error = err.Error()
+			failed++
 		}
+		st, err := h.status(svc.name, svc.image)
+		if err != nil && res.Error == "" {
+			res.Error = err.Error()
+		}
+		res.Status = st
+		results = append(results, res)
 	}
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(serviceStatus{
-		Container: name,
-		Image:     image,
-		Running:   running,
-		Exists:    exists,
-	})
+	code := http.StatusOK
+	if failed == len(servicePlane) {
+		code = http.StatusBadGateway
+	}
+	respondJSON(w, code, results)
 }
@@ -4,19 +4,28 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/environment-manager/backend/internal/models"
 )
 
 type fakeInspector struct {
 	exists, running bool
 	err             error
+	state           models.ContainerState
 }
 
 func (f *fakeInspector) ContainerStatus(_ context.Context, _ string) (bool, bool, error) {
 	return f.exists, f.running, f.err
 }
 
+func (f *fakeInspector) ContainerState(_ context.Context, _ string) (models.ContainerState, error) {
+	return f.state, f.err
+}
+
 func TestServicesHandler_PostgresRunning(t *testing.T) {
 	h := NewServicesHandler(&fakeInspector{exists: true, running: true})
 	req := httptest.NewRequest("GET", "/api/v1/services/postgres", nil)
@@ -56,3 +65,95 @@ func TestServicesHandler_DockerError(t *testing.T) {
 		t.Error("expected running=false on docker error")
 	}
 }
+
+func TestServicesHandler_ExitedReportsExitCodeAndOOM(t *testing.T) {
+	h := NewServicesHandler(&fakeInspector{
+		exists: true,
+		state:  models.ContainerState{Status: "exited", ExitCode: 137, OOMKilled: true},
+	})
+	req := httptest.NewRequest("GET", "/api/v1/services/postgres", nil)
+	rec := httptest.NewRecorder()
+	h.Postgres(rec, req)
+	var got serviceStatus
+	_ = json.NewDecoder(rec.Body).Decode(&got)
+	if got.State != "exited" || got.ExitCode != 137 || !got.OOMKilled {
+		t.Errorf("got %+v, want exited/137/oom", got)
+	}
+}
+
+func TestServicesHandler_ReportsRunningImage(t *testing.T) {
+	h := NewServicesHandler(&fakeInspector{
+		exists: true, running: true,
+		state: models.ContainerState{Status: "running", Running: true, ImageID: "sha256:abc", ImageDigest: "postgres@sha256:def"},
+	})
+	req := httptest.NewRequest("GET", "/api/v1/services/postgres", nil)
+	rec := httptest.NewRecorder()
+	h.Postgres(rec, req)
+	var got serviceStatus
+	_ = json.NewDecoder(rec.Body).Decode(&got)
+	if got.ImageID != "sha256:abc" || got.ImageDigest != "postgres@sha256:def" {
+		t.Errorf("got %+v, want the running image id + digest", got)
+	}
+}
+
+func TestServicesHandler_TimeoutReturns504(t *testing.T) {
+	h := NewServicesHandler(&fakeInspector{err: fmt.Errorf("list paas-postgres: %w", context.DeadlineExceeded)})
+	req := httptest.NewRequest("GET", "/api/v1/services/postgres", nil)
+	rec := httptest.NewRecorder()
+	h.Postgres(rec, req)
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Errorf("status = %d, want 504", rec.Code)
+	}
+}
+
+type fakeRestarter struct {
+	restarted []string
+	fail      map[string]error
+	stop      models.StopReport
+}
+
+func (f *fakeRestarter) StopContainerReport(_ context.Context, name string, _ *int) (models.StopReport, error) {
+	return f.stop, f.fail[name]
+}
+
+func (f *fakeRestarter) StartContainer(id string) error {
+	f.restarted = append(f.restarted, id)
+	return nil
+}
+
+func TestServicesHandler_Restart(t *testing.T) {
+	h := NewServicesHandler(&fakeInspector{exists: true, running: true})
+	rs := &fakeRestarter{
+		fail: map[string]error{"paas-redis": errors.New("no such container")},
+		stop: models.StopReport{WasRunning: true, Graceful: false, ExitCode: 137},
+	}
+	h.SetRestarter(rs)
+	rec := httptest.NewRecorder()
+	h.Restart(rec, httptest.NewRequest("POST", "/api/v1/services/restart", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d; body = %s", rec.Code, rec.Body.String())
+	}
+	var got []RestartResult
+	_ = json.NewDecoder(rec.Body).Decode(&got)
+	if len(got) != 2 || len(rs.restarted) != 1 {
+		t.Fatalf("got %+v, restarted %v", got, rs.restarted)
+	}
+	if got[0].Status.Container != "paas-postgres" || got[0].Error != "" || !got[0].Status.Running {
+		t.Errorf("postgres = %+v", got[0])
+	}
+	if got[0].Stop.Graceful || got[0].Stop.ExitCode != 137 {
+		t.Errorf("postgres stop = %+v, want killed (137)", got[0].Stop)
+	}
+	if got[1].Status.Container != "paas-redis" || got[1].Error == "" {
+		t.Errorf("redis = %+v, want error", got[1])
+	}
+}
+
+func TestServicesHandler_RestartUnconfigured(t *testing.T) {
+	h := NewServicesHandler(nil)
+	rec := httptest.NewRecorder()
+	h.Restart(rec, httptest.NewRequest("POST", "/api/v1/services/restart", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", rec.Code)
+	}
+}
@@ -14,6 +14,13 @@ type LicenseStatusReader interface {
 	Status() license.Status
 }
 
+// LetsencryptEmailReader exposes the Let's Encrypt email currently in
+// force. Implemented by *builder.Runner, which a config reload updates;
+// nil = no email configured.
+type LetsencryptEmailReader interface {
+	LetsencryptEmail() string
+}
+
 // SettingsResponse is the GET /api/v1/settings body. No secrets, just
 // presence flags so the operator/UI can see what's configured.
 type SettingsResponse struct {
@@ -25,19 +32,20 @@ type SettingsResponse struct {
 
 // SettingsHandler returns operator-visible config presence (no values).
 type SettingsHandler struct {
-	hasLEEmail   bool
+	leEmail      LetsencryptEmailReader
 	hasCredStore bool
 	version      string
 	licenseRdr   LicenseStatusReader
 }
 
-// NewSettingsHandler constructs the handler. letsencryptEmail is the operator's
-// email used by Traefik LE; we only return whether it's set, never the value.
+// NewSettingsHandler constructs the handler. leEmail reports the operator's
+// email used by Traefik LE, read per request so a config reload shows up;
+// we only return whether it's set, never the value. leEmail may be nil.
 // credStoreReady mirrors whether the credential store has a working key.
 // licenseRdr may be nil when license enforcement is disabled.
-func NewSettingsHandler(letsencryptEmail string, credStoreReady bool, version string, licenseRdr LicenseStatusReader) *SettingsHandler {
+func NewSettingsHandler(leEmail LetsencryptEmailReader, credStoreReady bool, version string, licenseRdr LicenseStatusReader) *SettingsHandler {
 	return &SettingsHandler{
-		hasLEEmail:   letsencryptEmail != "",
+		leEmail:      leEmail,
 		hasCredStore: credStoreReady,
 		version:      version,
 		licenseRdr:   licenseRdr,
@@ -52,7 +60,7 @@ func (h *SettingsHandler) Get(w http.ResponseWriter, r *http.Request) {
 	}
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(SettingsResponse{
-		LetsencryptEmailSet: h.hasLEEmail,
+		LetsencryptEmailSet: h.leEmail != nil && h.leEmail.LetsencryptEmail() != "",
 		CredentialStoreSet:  h.hasCredStore,
 		Version:             h.version,
 		License:             licStatus,
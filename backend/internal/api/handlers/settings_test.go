@@ -6,8 +6,14 @@ import (
 	"testing"
 )
 
+// leEmail is a fixed LetsencryptEmailReader.
+type leEmail string
+
+func (e *leEmail) LetsencryptEmail() string { return string(*e) }
+
 func TestSettingsHandler(t *testing.T) {
-	h := NewSettingsHandler("ops@example.com", true, "v2-test", nil)
+	email := leEmail("ops@example.com")
+	h := NewSettingsHandler(&email, true, "v2-test", nil)
 	req := httptest.NewRequest("GET", "/api/v1/settings", nil)
 	rec := httptest.NewRecorder()
 	h.Get(rec, req)
@@ -19,7 +25,7 @@ func TestSettingsHandler(t *testing.T) {
 }
 
 func TestSettingsHandler_BothUnset(t *testing.T) {
-	h := NewSettingsHandler("", false, "v2-test", nil)
+	h := NewSettingsHandler(nil, false, "v2-test", nil)
 	req := httptest.NewRequest("GET", "/api/v1/settings", nil)
 	rec := httptest.NewRecorder()
 	h.Get(rec, req)
@@ -29,3 +35,22 @@ func TestSettingsHandler_BothUnset(t *testing.T) {
 		t.Errorf("got %+v", got)
 	}
 }
+
+func TestSettingsHandler_ReadsEmailPerRequest(t *testing.T) {
+	email := leEmail("")
+	h := NewSettingsHandler(&email, true, "v2-test", nil)
+	get := func() SettingsResponse {
+		rec := httptest.NewRecorder()
+		h.Get(rec, httptest.NewRequest("GET", "/api/v1/settings", nil))
+		var got SettingsResponse
+		_ = json.NewDecoder(rec.Body).Decode(&got)
+		return got
+	}
+	if get().LetsencryptEmailSet {
+		t.Fatal("LetsencryptEmailSet before the email was configured")
+	}
+	email = "ops@example.com" // a config reload
+	if !get().LetsencryptEmailSet {
+		t.Error("LetsencryptEmailSet still false after the email changed")
+	}
+}
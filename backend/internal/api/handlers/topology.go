@@ -108,25 +108,33 @@ func (h *TopologyHandler) Get(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *TopologyHandler) serviceNode(name, image string) TopologyNode {
-	status := "absent"
-	if h.docker != nil {
-		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-		defer cancel()
-		if exists, running, err := h.docker.ContainerStatus(ctx, name); err == nil {
-			switch {
-			case running:
-				status = "running"
-			case exists:
-				status = "stopped"
-			}
-		}
-	}
 	return TopologyNode{
 		ID:     name,
 		Type:   "service",
 		Label:  name,
-		Status: status,
+		Status: singletonStatus(h.docker, name),
 		Href:   "/services/" + name,
 		Image:  image,
 	}
 }
+
+// singletonStatus reports a service-plane container as "running",
+// "stopped", or "absent". A nil inspector or a docker error reads as
+// "absent".
+func singletonStatus(docker ContainerInspector, name string) string {
+	if docker == nil {
+		return "absent"
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	exists, running, err := docker.ContainerStatus(ctx, name)
+	switch {
+	case err != nil:
+		return "absent"
+	case running:
+		return "running"
+	case exists:
+		return "stopped"
+	}
+	return "absent"
+}
@@ -84,69 +84,113 @@ func (h *WebhookHandler) GitHub(w http.ResponseWriter, r *http.Request) {
 		zap.String("repo", payload.Repository.FullName),
 	)
 
-	status := h.processProjectPush(payload.Repository.CloneURL, payload.Ref, headSHA(payload))
-	respondSuccess(w, map[string]string{"status": "ok", "project_status": status})
+	status, verr := h.processProjectPush(r.Context(), payload.Repository.CloneURL, payload.Ref, headSHA(payload))
+	if status == "fetch_failed" {
+		// 502 marks the delivery failed on GitHub's side, so it can be
+		// redelivered once the remote is reachable again.
+		respondError(w, http.StatusBadGateway, "FETCH_FAILED", verr.Error())
+		return
+	}
+	resp := map[string]string{"status": "ok", "project_status": status}
+	if verr != nil {
+		resp["error"] = verr.Error()
+	}
+	respondSuccess(w, resp)
 }
 
 // processProjectPush is called for every push to a known Project repo.
 // Creates a preview env if the branch is new and has a .dev/ tree;
 // rebuilds an existing env via the builder runner.
-func (h *WebhookHandler) processProjectPush(repoURL, ref, headSHA string) string {
+//
+// The pushed compose file is validated first (projects.ValidateBranchCompose).
+// A broken one is not deployed: no build is queued, whatever is running
+// keeps running, and the validation error is returned alongside the
+// "invalid_compose" status. When git can't read the file at all (say the
+// fetched refs lack the branch) the status is "validate_failed" instead,
+// so a clone problem isn't blamed on the compose file.
+//
+// The pushed commit is fetched first, with retries (Store.FetchOriginWithRetry).
+// When that still fails nothing is deployed — the local refs may predate
+// the push — and "fetch_failed" is returned with the error.
+func (h *WebhookHandler) processProjectPush(ctx context.Context, repoURL, ref, headSHA string) (string, error) {
 	if h.projectsStore == nil || h.runner == nil {
-		return ""
+		return "", nil
 	}
 	branch := strings.TrimPrefix(ref, "refs/heads/")
 	if branch == ref {
-		return "" // not a branch push (e.g. tag)
+		return "", nil // not a branch push (e.g. tag)
 	}
 
 	project, err := h.projectsStore.GetProjectByRepoURL(repoURL)
 	if err != nil {
-		return "" // unknown repo
+		return "", nil // unknown repo
 	}
 
-	if out, err := projects.FetchOrigin(project.LocalPath, h.gitToken()); err != nil {
-		h.logger.Warn("git fetch failed",
+	if out, err := h.projectsStore.FetchOriginWithRetry(ctx, project.LocalPath, h.gitToken()); err != nil {
+		h.logger.Warn("git fetch failed; not deploying",
 			zap.String("repo", project.LocalPath),
 			zap.Error(err),
 			zap.String("out", string(out)))
+		return "fetch_failed", err
 	}
 
 	slug, err := projects.BranchSlug(branch)
 	if err != nil {
 		h.logger.Warn("invalid branch slug, skipping",
 			zap.String("branch", branch), zap.Error(err))
-		return ""
+		return "", nil
 	}
 
 	env, err := h.projectsStore.GetEnvironment(project.ID, slug)
 	if err != nil && !errors.Is(err, projects.ErrNotFound) {
 		h.logger.Error("get env failed", zap.Error(err))
-		return ""
+		return "", nil
 	}
 
-	if env == nil {
-		if !projects.DevDirExistsForBranch(project.LocalPath, branch) {
-			return "no_dev_dir"
+	if env == nil && !projects.DevDirExistsForBranch(project.LocalPath, branch) {
+		return "no_dev_dir", nil
+	}
+
+	composeFile := ".dev/docker-compose.dev.yml"
+	if branch == project.DefaultBranch {
+		composeFile = ".dev/docker-compose.prod.yml"
+	}
+	if env != nil {
+		composeFile = env.ComposeFile
+	}
+	if verr := projects.ValidateBranchCompose(project.LocalPath, branch, composeFile); verr != nil {
+		if !errors.Is(verr, projects.ErrInvalidCompose) {
+			h.logger.Warn("could not read pushed compose file; not deploying",
+				zap.String("project_id", project.ID),
+				zap.String("branch", branch),
+				zap.Error(verr))
+			return "validate_failed", verr
 		}
+		h.logger.Warn("pushed compose file is invalid; not deploying",
+			zap.String("project_id", project.ID),
+			zap.String("branch", branch),
+			zap.Error(verr))
+		return "invalid_compose", verr
+	}
+
+	if env == nil {
 		env = &models.Environment{
 			ID:          project.ID + "--" + slug,
 			ProjectID:   project.ID,
 			Branch:      branch,
 			BranchSlug:  slug,
 			Kind:        models.EnvKindPreview,
-			ComposeFile: ".dev/docker-compose.dev.yml",
+			ComposeFile: composeFile,
 			Status:      models.EnvStatusPending,
 			CreatedAt:   time.Now().UTC(),
 		}
 		if branch == project.DefaultBranch {
 			env.Kind = models.EnvKindProd
-			env.ComposeFile = ".dev/docker-compose.prod.yml"
 		}
 		env.URL = projects.ComposeURL(project, env, "home")
 		if err := h.projectsStore.SaveEnvironment(env); err != nil {
 			h.logger.Error("save new preview env", zap.Error(err))
-			return ""
+			return "", nil
 		}
 	}
 
@@ -160,10 +204,10 @@ func (h *WebhookHandler) processProjectPush(repoURL, ref, headSHA string) string
 	}
 	if err := h.projectsStore.SaveBuild(project.ID, build); err != nil {
 		h.logger.Error("save build", zap.Error(err))
-		return ""
+		return "", nil
 	}
 	go h.runner.Build(context.Background(), env, build)
-	return "build_enqueued:" + build.ID
+	return "build_enqueued:" + build.ID, nil
 }
 
 // handleDelete tears down preview envs when a branch is deleted on GitHub.
@@ -351,3 +351,68 @@ func TestWebhook_BranchDelete_TagIgnored(t *testing.T) {
 	}
 	_ = project
 }
+
+// TestWebhook_ProjectPush_InvalidComposeSkipsBuild verifies that a push
+// carrying a broken compose file queues no build and reports the error.
+func TestWebhook_ProjectPush_InvalidComposeSkipsBuild(t *testing.T) {
+	store, runner, project := makeProjectFixture(t)
+	h := newWebhookV2Handler(store, runner)
+
+	// Push a broken prod compose to origin from the working clone.
+	if err := os.WriteFile(filepath.Join(project.LocalPath, ".dev", "docker-compose.prod.yml"), []byte("services:\n  app:\n    image: [unclosed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"commit", "-am", "break compose"}, {"push", "origin", "main"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = project.LocalPath
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	body := []byte(`{"ref":"refs/heads/main","repository":{"full_name":"u/myapp","clone_url":"` + project.RepoURL + `"}}`)
+	req := httptest.NewRequest("POST", "/api/v1/webhook/github", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.GitHub(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200; body=%s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Data map[string]string `json:"data"`
+	}
+	_ = json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.Data["project_status"] != "invalid_compose" || resp.Data["error"] == "" {
+		t.Errorf("response = %v, want invalid_compose with an error", resp.Data)
+	}
+	if builds, _ := store.ListBuildsForEnv("p1", "p1--main"); len(builds) != 0 {
+		t.Errorf("got %d builds, want none for an invalid compose", len(builds))
+	}
+	if env, _ := store.GetEnvironment("p1", "main"); env.Status != models.EnvStatusRunning {
+		t.Errorf("env status = %v, want the running deploy left alone", env.Status)
+	}
+}
+
+func TestWebhook_ProjectPush_FetchFailedSkipsBuild(t *testing.T) {
+	store, runner, project := makeProjectFixture(t)
+	h := newWebhookV2Handler(store, runner)
+
+	// Point origin at a repo that doesn't exist: the fetch fails without retries.
+	cmd := exec.Command("git", "remote", "set-url", "origin", filepath.Join(t.TempDir(), "gone.git"))
+	cmd.Dir = project.LocalPath
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("set-url: %v\n%s", err, out)
+	}
+
+	body := []byte(`{"ref":"refs/heads/main","repository":{"full_name":"u/myapp","clone_url":"` + project.RepoURL + `"}}`)
+	req := httptest.NewRequest("POST", "/api/v1/webhook/github", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.GitHub(rec, req)
+
+	if rec.Code != http.StatusBadGateway || !bytes.Contains(rec.Body.Bytes(), []byte("FETCH_FAILED")) {
+		t.Fatalf("status = %d, want 502 FETCH_FAILED; body=%s", rec.Code, rec.Body.String())
+	}
+	if builds, _ := store.ListBuildsForEnv("p1", "p1--main"); len(builds) != 0 {
+		t.Errorf("got %d builds, want none when the fetch failed", len(builds))
+	}
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// WebSocket keepalive timing. The server pings every wsPingInterval; a
+// client that hasn't answered (or sent anything) within wsPongWait is
+// considered gone and its stream is torn down. wsWriteWait bounds each
+// write so a stalled client can't pin a goroutine.
+const (
+	wsPongWait     = 60 * time.Second
+	wsPingInterval = wsPongWait * 9 / 10
+	wsWriteWait    = 10 * time.Second
+)
+
+// LimitConnections returns a middleware that caps concurrent requests
+// through it at max, answering 503 beyond that. Meant for the WebSocket
+// group: each socket holds a file handle or docker log stream for as long
+// as it's open, so abandoned browser tabs must not accumulate unbounded.
+//
+// max <= 0 disables the cap.
+func LimitConnections(max int) func(http.Handler) http.Handler {
+	if max <= 0 {
+		return func(h http.Handler) http.Handler { return h }
+	}
+	var active atomic.Int64
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if active.Add(1) > int64(max) {
+				active.Add(-1)
+				http.Error(w, "too many open streams", http.StatusServiceUnavailable)
+				return
+			}
+			defer active.Add(-1)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// wsKeepalive starts the read pump and ping loop for a server→client
+// stream. The returned channel closes once the client is gone — it closed
+// the socket, failed to answer pings within wsPongWait, or a ping write
+// failed. stop ends the ping loop; call it (deferred) before closing conn.
+//
+// Callers must not read from conn themselves; the pump owns the read side.
+func wsKeepalive(conn *websocket.Conn) (gone <-chan struct{}, stop func()) {
+	closed := make(chan struct{})
+	_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		t := time.NewTicker(wsPingInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-closed:
+				return
+			case <-t.C:
+				// WriteControl is safe to call concurrently with WriteMessage.
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+					_ = conn.Close()
+					return
+				}
+			}
+		}
+	}()
+	return closed, func() { close(done) }
+}
+
+// wsWrite sends one text frame with a write deadline.
+func wsWrite(conn *websocket.Conn, data []byte) error {
+	_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	return conn.WriteMessage(websocket.TextMessage, data)
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestLimitConnections(t *testing.T) {
+	release := make(chan struct{})
+	entered := make(chan struct{}, 3)
+	h := LimitConnections(2)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entered <- struct{}{}
+		<-release
+	}))
+
+	var done sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ws", nil))
+		}()
+	}
+	<-entered
+	<-entered
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/ws", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("third connection: status = %d, want 503", rec.Code)
+	}
+	close(release)
+	done.Wait()
+
+	// Slots free up once the streams end.
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/ws", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("after release: status = %d, want 200", rec.Code)
+	}
+}
+
+func TestWSKeepalive_DetectsClientClose(t *testing.T) {
+	detected := make(chan struct{})
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		gone, stop := wsKeepalive(conn)
+		defer stop()
+		<-gone
+		close(detected)
+	}))
+	defer srv.Close()
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = client.Close()
+	select {
+	case <-detected:
+	case <-time.After(2 * time.Second):
+		t.Fatal("server did not notice the client going away")
+	}
+}
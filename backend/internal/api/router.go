@@ -2,13 +2,14 @@ package api
 
 import (
 	"net/http"
-	"path/filepath"
+	"net/netip"
 	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 
+	"github.com/environment-manager/backend/internal/activity"
 	"github.com/environment-manager/backend/internal/api/handlers"
 	"github.com/environment-manager/backend/internal/api/origin"
 	"github.com/environment-manager/backend/internal/builder"
@@ -39,9 +40,31 @@ type RouterConfig struct {
 	Logger           *zap.Logger
 	DockerClient     handlers.ContainerInspector  // nil = services endpoints return exists=false
 	DockerLogStream  handlers.RuntimeLogStreamer  // nil = runtime-logs endpoints return 503
-	LetsencryptEmail string
+	DockerContainers handlers.ComposeContainerLister // nil = env containers endpoint returns 503
+	DockerTop        handlers.ContainerTopper        // nil = env top endpoint returns 503
+	DockerRestarter  handlers.ContainerRestarter     // nil = services restart endpoint returns 503
+	DockerNetworks   handlers.NetworkManager         // nil = network endpoints return 503
 	Version          string
 	License          *license.Watcher // nil = enforcement disabled
+	// RateLimit / RateBurst cap each client's request rate on /api/v1
+	// (req/s, burst). RateLimit <= 0 disables.
+	RateLimit        float64
+	RateBurst        int
+	// TrustedProxies are the peers whose forwarded client address the
+	// rate limiter keys on; everyone else is keyed on their own address.
+	TrustedProxies []netip.Prefix
+	// MaxStreams caps concurrently open WebSocket log streams. <= 0
+	// disables the cap.
+	MaxStreams int
+	// Spawner creates and tears down envs for the on-demand project
+	// reconcile. nil = POST /projects/{id}/reconcile returns 503.
+	Spawner projects.EnvSpawner
+	// BasePath mounts every route under a subpath ("/envmgr"); see
+	// withBasePath. Empty = domain root.
+	BasePath string
+	// Activity is the global operation log. nil = nothing recorded and
+	// GET /activity returns 503.
+	Activity *activity.Log
 }
 
 // NewRouter creates a new HTTP router.
@@ -50,14 +73,19 @@ func NewRouter(cfg RouterConfig) http.Handler {
 
 	// Middleware
 	r.Use(middleware.RequestID)
+	// PeerAddr keeps the connecting address for the rate limiter before
+	// RealIP replaces it with the (client-supplied) forwarded one.
+	r.Use(handlers.PeerAddr)
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
+	// HEAD on any GET route runs the GET handler; net/http drops the body.
+	r.Use(middleware.GetHead)
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   origin.Allowed(cfg.BaseDomain),
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedMethods:   []string{"GET", "HEAD", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "Idempotency-Key", "If-None-Match"},
+		ExposedHeaders:   []string{"Link", "ETag"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
@@ -69,19 +97,40 @@ func NewRouter(cfg RouterConfig) http.Handler {
 	webhookHandler.SetCredentialStore(cfg.CredentialStore)
 	wsCheckOrigin := origin.CheckOrigin(cfg.BaseDomain)
 	projectsHandler := handlers.NewProjectsHandler(cfg.ProjectsStore, cfg.ReposManager, cfg.CredentialStore, cfg.BaseDomain, cfg.Logger, cfg.Builder)
+	if cfg.Spawner != nil {
+		projectsHandler.SetSpawner(cfg.Spawner)
+	}
 	buildsHandler := handlers.NewBuildsHandler(cfg.ProjectsStore, cfg.Builder, cfg.DataDir, cfg.Logger, wsCheckOrigin)
 	envsHandler := handlers.NewEnvsHandler(cfg.ProjectsStore, cfg.Builder, cfg.CredentialStore, cfg.Logger)
+	if cfg.DockerContainers != nil {
+		envsHandler.SetContainerLister(cfg.DockerContainers)
+	}
+	if cfg.DockerTop != nil {
+		envsHandler.SetProcessLister(cfg.DockerTop)
+	}
 	servicesHandler := handlers.NewServicesHandler(cfg.DockerClient)
+	if cfg.DockerRestarter != nil {
+		servicesHandler.SetRestarter(cfg.DockerRestarter)
+	}
 	// Pass nil licenseRdr when no watcher is wired (disables the field on
 	// the response).
 	var licenseRdr handlers.LicenseStatusReader
 	if cfg.License != nil {
 		licenseRdr = cfg.License
 	}
-	settingsHandler := handlers.NewSettingsHandler(cfg.LetsencryptEmail, cfg.CredentialStore != nil, cfg.Version, licenseRdr)
+	var leEmail handlers.LetsencryptEmailReader
+	if cfg.Builder != nil {
+		leEmail = cfg.Builder
+	}
+	settingsHandler := handlers.NewSettingsHandler(leEmail, cfg.CredentialStore != nil, cfg.Version, licenseRdr)
 	backupHandler := handlers.NewBackupHandler(cfg.DataDir, cfg.Logger)
+	dataDirHandler := handlers.NewDataDirHandler(cfg.DataDir)
+	maintenanceHandler := handlers.NewMaintenanceHandler(cfg.Builder, cfg.Logger)
+	networksHandler := handlers.NewNetworksHandler(cfg.DockerNetworks, cfg.ProjectsStore, cfg.Logger)
 	topologyHandler := handlers.NewTopologyHandler(cfg.ProjectsStore, cfg.DockerClient)
+	overviewHandler := handlers.NewOverviewHandler(cfg.ProjectsStore, cfg.DockerClient)
 	runtimeLogsHandler := handlers.NewRuntimeLogsHandler(cfg.DockerLogStream, cfg.ProjectsStore, cfg.Logger, wsCheckOrigin)
+	activityHandler := handlers.NewActivityHandler(cfg.Activity)
 
 	// auth wraps a route group with BearerAuth when the credential store is
 	// available. credStore can be nil in dev / first-boot — in that mode the
@@ -94,14 +143,30 @@ func NewRouter(cfg RouterConfig) http.Handler {
 
 	// API routes
 	r.Route("/api/v1", func(r chi.Router) {
-		// Always open: liveness + webhook (HMAC-secured separately).
+		// gzip JSON/text responses. Scoped to /api/v1 so the WebSocket
+		// routes below never see a compressing writer, which would break
+		// the upgrade hijack.
+		r.Use(middleware.Compress(5, "application/json", "text/plain"))
+		// ETag sits inside Compress so the tag hashes the plain JSON and
+		// stays the same whether or not the client accepts gzip.
+		r.Use(handlers.ETag)
+		// Per-client rate limit keeps a runaway poller from saturating the
+		// docker daemon behind the list/inspect endpoints. One limiter,
+		// shared by every group below; health probes and GitHub's webhook
+		// deliveries are exempt so a busy client can't make either fail.
+		rateLimit := handlers.RateLimit(cfg.RateLimit, cfg.RateBurst, cfg.TrustedProxies)
+
+		// Always open, never rate-limited: liveness + webhook
+		// (HMAC-secured separately).
 		r.Get("/health", handlers.HealthCheck)
-		r.Post("/webhook/github", webhookHandler.GitHub)
+		r.With(handlers.RecordActivity(cfg.Activity, activity.TypeWebhook)).
+			Post("/webhook/github", webhookHandler.GitHub)
 
 		// Read-only endpoints. In lab mode these are open on the LAN. With
 		// LAB_MODE=false the operator is opting into stricter auth — Bearer
 		// then applies to reads as well as writes.
 		r.Group(func(r chi.Router) {
+			r.Use(rateLimit)
 			if !cfg.LabMode {
 				auth(r)
 			}
@@ -109,11 +174,38 @@ func NewRouter(cfg RouterConfig) http.Handler {
 			r.Get("/projects/{id}", projectsHandler.Get)
 			r.Get("/projects/{id}/secrets", projectsHandler.ListSecrets)
 			r.Get("/envs/{id}/builds", buildsHandler.List)
+			r.Get("/envs/{id}/events", envsHandler.Events)
+			r.Get("/envs/{id}/containers", envsHandler.Containers)
+			r.Get("/envs/{id}/state", envsHandler.State)
+			r.Get("/envs/{id}/labels", envsHandler.Labels)
+			r.Get("/envs/{id}/top", envsHandler.Top)
+			r.Get("/envs/{id}/divergence", envsHandler.Divergence)
+			r.Get("/envs/{id}/compose/history", envsHandler.ComposeHistory)
+			r.Get("/envs/{id}/compose/history/{sha}", envsHandler.ComposeAt)
 			r.Get("/builds/{id}/log", buildsHandler.GetLog)
+			r.Get("/envs/{id}/runtime-logs/download", runtimeLogsHandler.DownloadEnv)
+			r.Get("/services/{name}/runtime-logs/download", runtimeLogsHandler.DownloadService)
 			r.Get("/services/postgres", servicesHandler.Postgres)
 			r.Get("/services/redis", servicesHandler.Redis)
 			r.Get("/settings", settingsHandler.Get)
 			r.Get("/topology", topologyHandler.Get)
+			r.Get("/networks", networksHandler.List)
+			r.Get("/overview", overviewHandler.Get)
+			r.Get("/activity", activityHandler.List)
+		})
+
+		// Token-gated, regardless of LAB_MODE, but no license needed —
+		// nothing changes. /remote spends the project's PAT on a live
+		// `git ls-remote`; a build's compose snapshot has the project
+		// secrets substituted in; lint parses whatever YAML it's posted
+		// (nothing is stored). None of it should be open to anyone on
+		// the LAN.
+		r.Group(func(r chi.Router) {
+			r.Use(rateLimit)
+			auth(r)
+			r.Get("/projects/{id}/remote", projectsHandler.Remote)
+			r.Get("/builds/{id}/compose", buildsHandler.GetCompose)
+			r.Post("/compose/lint", handlers.LintCompose)
 		})
 
 		// Admin endpoints — always require admin token, regardless of
@@ -122,22 +214,42 @@ func NewRouter(cfg RouterConfig) http.Handler {
 		// License enforcement does NOT block backup: an operator with an
 		// expired license still needs to be able to take their data out.
 		r.Group(func(r chi.Router) {
+			r.Use(rateLimit)
 			auth(r)
+			r.Use(handlers.RecordActivity(cfg.Activity, activity.TypeUser))
 			r.Get("/admin/backup", backupHandler.Get)
+			r.Get("/admin/data/tree", dataDirHandler.Tree)
+			r.Get("/admin/data/file", dataDirHandler.File)
+			r.Post("/admin/prune", maintenanceHandler.Prune)
+			r.Post("/admin/drain", maintenanceHandler.Drain)
+			r.Post("/admin/undrain", maintenanceHandler.Undrain)
+			r.Post("/admin/networks/prune", networksHandler.Prune)
+			r.Post("/admin/projects/{id}/gc", projectsHandler.GC)
 		})
 
 		// Mutating endpoints — always require admin token (when one exists)
 		// AND a valid license (when enforcement is on).
 		r.Group(func(r chi.Router) {
+			r.Use(rateLimit)
 			auth(r)
 			r.Use(handlers.RequireLicense(licenseRdr))
+			r.Use(handlers.RecordActivity(cfg.Activity, activity.TypeUser))
+			// Idempotency-Key support so a client retrying a POST after a
+			// dropped connection doesn't onboard or build twice.
+			r.Use(handlers.Idempotency(handlers.IdempotencyTTL))
 			r.Post("/projects", projectsHandler.Create)
 			r.Delete("/projects/{id}", projectsHandler.Delete)
+			r.Put("/projects/{id}/notes", projectsHandler.SetNotes)
+			r.Put("/projects/{id}/protection", projectsHandler.SetProtected)
 			r.Get("/projects/{id}/secrets/{key}", projectsHandler.GetSecret)
 			r.Put("/projects/{id}/secrets", projectsHandler.SetSecrets)
 			r.Delete("/projects/{id}/secrets/{key}", projectsHandler.DeleteSecret)
+			r.Post("/projects/{id}/reconcile", projectsHandler.Reconcile)
 			r.Post("/envs/{id}/build", buildsHandler.Trigger)
+			r.Post("/builds/{id}/cancel", buildsHandler.Cancel)
 			r.Post("/envs/{id}/destroy", envsHandler.Destroy)
+			r.Post("/envs/{id}/pull", envsHandler.Pull)
+			r.Post("/services/restart", servicesHandler.Restart)
 		})
 	})
 
@@ -147,6 +259,7 @@ func NewRouter(cfg RouterConfig) http.Handler {
 		if !cfg.LabMode {
 			auth(r)
 		}
+		r.Use(handlers.LimitConnections(cfg.MaxStreams))
 		r.Get("/ws/envs/{id}/build-logs", buildsHandler.StreamLogs)
 		r.Get("/ws/envs/{id}/runtime-logs", runtimeLogsHandler.StreamEnv)
 		r.Get("/ws/services/{name}/runtime-logs", runtimeLogsHandler.StreamService)
@@ -160,17 +273,20 @@ func NewRouter(cfg RouterConfig) http.Handler {
 	// will keep loading the old asset bundle even after a redeploy.
 	fileServer := http.FileServer(http.Dir(cfg.StaticDir))
 	r.Handle("/*", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" || r.URL.Path == "/index.html" {
+			serveIndex(w, r, cfg.StaticDir, cfg.BasePath)
+			return
+		}
 		if _, err := http.Dir(cfg.StaticDir).Open(r.URL.Path); err != nil {
 			// SPA fallback: index.html for any unknown path.
-			w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
-			http.ServeFile(w, r, filepath.Join(cfg.StaticDir, "index.html"))
+			serveIndex(w, r, cfg.StaticDir, cfg.BasePath)
 			return
 		}
 		setStaticCacheHeaders(w, r.URL.Path)
 		fileServer.ServeHTTP(w, r)
 	}))
 
-	return r
+	return withBasePath(cfg.BasePath, r)
 }
 
 // setStaticCacheHeaders applies Cache-Control to static assets based on
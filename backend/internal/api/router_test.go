@@ -0,0 +1,86 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/environment-manager/backend/internal/credentials"
+	"github.com/environment-manager/backend/internal/projects"
+)
+
+// newLabRouter returns a lab-mode router whose admin token is "secret".
+// opts adjust the config before the router is built.
+func newLabRouter(t *testing.T, opts ...func(*RouterConfig)) http.Handler {
+	t.Helper()
+	dir := t.TempDir()
+	store, err := projects.NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds, err := credentials.NewStore(filepath.Join(dir, "c.json"), make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := creds.SaveSystemSecret("system:admin_token", "secret"); err != nil {
+		t.Fatal(err)
+	}
+	cfg := RouterConfig{
+		ProjectsStore:   store,
+		CredentialStore: creds,
+		LabMode:         true,
+		Logger:          zap.NewNop(),
+	}
+	for _, o := range opts {
+		o(&cfg)
+	}
+	return NewRouter(cfg)
+}
+
+func TestRouter_LabModeAuth(t *testing.T) {
+	h := newLabRouter(t)
+	for _, c := range []struct {
+		method, path string
+		want         int
+	}{
+		{"GET", "/api/v1/projects", http.StatusOK},
+		// Spends the project's stored PAT on every call.
+		{"GET", "/api/v1/projects/p1/remote", http.StatusUnauthorized},
+		// The resolved compose carries the project's secrets.
+		{"GET", "/api/v1/builds/b1/compose", http.StatusUnauthorized},
+		{"POST", "/api/v1/compose/lint", http.StatusUnauthorized},
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(c.method, c.path, nil))
+		if rec.Code != c.want {
+			t.Errorf("%s %s: status = %d, want %d", c.method, c.path, rec.Code, c.want)
+		}
+	}
+}
+
+func TestRouter_RateLimitExemptions(t *testing.T) {
+	h := newLabRouter(t, func(c *RouterConfig) { c.RateLimit, c.RateBurst = 0.001, 1 })
+	status := func(method, path string) int {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec.Code
+	}
+	if got := status("GET", "/api/v1/projects"); got != http.StatusOK {
+		t.Fatalf("first request: status = %d", got)
+	}
+	if got := status("GET", "/api/v1/projects"); got != http.StatusTooManyRequests {
+		t.Fatalf("second request: status = %d, want 429", got)
+	}
+	// The client is out of tokens, but probes and deliveries still get through.
+	for i := 0; i < 3; i++ {
+		if got := status("GET", "/api/v1/health"); got == http.StatusTooManyRequests {
+			t.Errorf("health was rate limited")
+		}
+		if got := status("POST", "/api/v1/webhook/github"); got == http.StatusTooManyRequests {
+			t.Errorf("webhook was rate limited")
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package builder
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MissingBindSources lists the relative bind-mount sources in the compose
+// file at composePath that don't exist under projectDir (where compose
+// resolves them, see --project-directory). Docker silently creates a
+// missing source as an empty directory, so a typo shows up later as "my
+// config isn't loaded"; the runner warns in the build log instead.
+//
+// Absolute and ~ sources are skipped — the server may not share the
+// docker host's filesystem — as are sources with ${VAR} interpolation and
+// long-form mounts that opt in via bind.create_host_path: true. Each
+// entry reads "<service>: <source>".
+func MissingBindSources(composePath, projectDir string) ([]string, error) {
+	data, err := os.ReadFile(composePath)
+	if err != nil {
+		return nil, fmt.Errorf("read compose: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse compose YAML: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	services := labelsFindMapValue(doc.Content[0], "services")
+	if services == nil || services.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	var missing []string
+	for i := 0; i+1 < len(services.Content); i += 2 {
+		name, svc := services.Content[i].Value, services.Content[i+1]
+		if svc == nil || svc.Kind != yaml.MappingNode {
+			continue
+		}
+		vols := labelsFindMapValue(svc, "volumes")
+		if vols == nil || vols.Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, v := range vols.Content {
+			src := bindSource(v, false)
+			if !strings.HasPrefix(src, ".") || strings.Contains(src, "$") {
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(projectDir, src)); os.IsNotExist(err) {
+				missing = append(missing, name+": "+src)
+			}
+		}
+	}
+	return missing, nil
+}
+
+// bindSource returns the host source of a bind-mount volume entry in
+// either compose syntax ("./src:/dst:ro" or {type: bind, source: ./src}),
+// or "" for named volumes and tmpfs. Long-syntax binds that set
+// bind.create_host_path are included only when withCreated is true —
+// docker creates their source, so they're never "missing".
+func bindSource(v *yaml.Node, withCreated bool) string {
+	switch v.Kind {
+	case yaml.ScalarNode:
+		src, _, ok := strings.Cut(v.Value, ":")
+		if !ok {
+			return "" // anonymous volume: just a container path
+		}
+		return src
+	case yaml.MappingNode:
+		if t := labelsFindMapValue(v, "type"); t == nil || t.Value != "bind" {
+			return ""
+		}
+		if !withCreated {
+			if b := labelsFindMapValue(v, "bind"); b != nil && b.Kind == yaml.MappingNode {
+				if c := labelsFindMapValue(b, "create_host_path"); c != nil && c.Value == "true" {
+					return ""
+				}
+			}
+		}
+		if s := labelsFindMapValue(v, "source"); s != nil {
+			return s.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,52 @@
+package builder
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMissingBindSources(t *testing.T) {
+	project := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(project, "config"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	compose := filepath.Join(t.TempDir(), "docker-compose.yaml")
+	src := `services:
+  app:
+    image: app
+    volumes:
+      - ./config:/etc/app:ro
+      - ./confg/extra.yml:/etc/extra.yml
+      - data:/var/lib/app
+      - /var/run/docker.sock:/var/run/docker.sock
+      - ./${DATA_DIR}:/data
+      - /tmp/anon
+  worker:
+    image: worker
+    volumes:
+      - type: bind
+        source: ./missing-long
+        target: /x
+      - type: bind
+        source: ./created
+        target: /y
+        bind:
+          create_host_path: true
+      - type: volume
+        source: ./not-a-bind
+        target: /z
+`
+	if err := os.WriteFile(compose, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := MissingBindSources(compose, project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "app: ./confg/extra.yml,worker: ./missing-long"
+	if strings.Join(got, ",") != want {
+		t.Errorf("got %v, want %s", got, want)
+	}
+}
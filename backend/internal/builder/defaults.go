@@ -0,0 +1,170 @@
+package builder
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ComposeDefaults are host-wide settings applied to every service of a
+// rendered compose file that doesn't declare its own value. The user's
+// compose always wins — defaults only fill gaps.
+//
+// The zero value applies nothing.
+type ComposeDefaults struct {
+	// LogDriver + LogOptions become the service's `logging:` block. Without
+	// one, Docker falls back to the daemon default, typically json-file
+	// with no rotation — a chatty container can then fill the host disk.
+	// LogOptions are only written for drivers that accept them (see
+	// LogDriverRotates); syslog or journald reject max-size / max-file.
+	LogDriver  string
+	LogOptions map[string]string
+
+	// Restart becomes the service's `restart:` policy ("unless-stopped",
+	// "always", "on-failure", ...). Empty leaves Docker's default ("no").
+	Restart string
+
+	// MemLimit becomes the service's `mem_limit:` ("512m", "1g", ...).
+	// Skipped when the service already sets mem_limit or
+	// deploy.resources.limits.memory.
+	MemLimit string
+
+	// Init sets `init: true` so Docker runs tini as PID 1, reaping zombies
+	// and forwarding signals for images whose entrypoint is a shell script.
+	// Skipped when the service sets init explicitly (true or false).
+	Init bool
+
+	// TZ is added to the service's `environment:` as TZ so container
+	// clocks and logs match the host's zone ("Europe/Amsterdam", ...).
+	// Skipped when the service already sets TZ itself.
+	TZ string
+}
+
+// DefaultComposeDefaults returns the defaults used when the operator hasn't
+// configured anything: json-file logs rotated at 10MB × 3 files.
+func DefaultComposeDefaults() ComposeDefaults {
+	return ComposeDefaults{
+		LogDriver:  "json-file",
+		LogOptions: map[string]string{"max-size": "10m", "max-file": "3"},
+	}
+}
+
+// ApplyComposeDefaults rewrites the compose file at composePath, filling in
+// d for every service that lacks the corresponding key. Idempotent.
+func ApplyComposeDefaults(composePath string, d ComposeDefaults) error {
+	data, err := os.ReadFile(composePath)
+	if err != nil {
+		return fmt.Errorf("read compose: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("parse compose YAML: %w", err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return fmt.Errorf("compose YAML is empty")
+	}
+	root := doc.Content[0]
+	services := labelsFindMapValue(root, "services")
+	if services == nil || services.Kind != yaml.MappingNode {
+		return fmt.Errorf("compose YAML has no services mapping")
+	}
+
+	for i := 0; i+1 < len(services.Content); i += 2 {
+		svc := services.Content[i+1]
+		if svc == nil || svc.Kind != yaml.MappingNode {
+			continue
+		}
+		if d.LogDriver != "" && labelsFindMapValue(svc, "logging") == nil {
+			labelsSetMapValue(svc, "logging", loggingNode(d.LogDriver, d.LogOptions))
+		}
+		if d.Restart != "" && labelsFindMapValue(svc, "restart") == nil {
+			labelsSetMapValue(svc, "restart", &yaml.Node{Kind: yaml.ScalarNode, Value: d.Restart})
+		}
+		if d.MemLimit != "" && !hasMemoryLimit(svc) {
+			labelsSetMapValue(svc, "mem_limit", &yaml.Node{Kind: yaml.ScalarNode, Value: d.MemLimit})
+		}
+		if d.Init && labelsFindMapValue(svc, "init") == nil {
+			labelsSetMapValue(svc, "init", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"})
+		}
+		if d.TZ != "" {
+			setDefaultEnv(svc, "TZ", d.TZ)
+		}
+	}
+
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		return fmt.Errorf("marshal compose YAML: %w", err)
+	}
+	return os.WriteFile(composePath, out, 0644)
+}
+
+// hasMemoryLimit reports whether svc declares a memory cap in either the
+// legacy (mem_limit) or the swarm-style (deploy.resources.limits.memory) form.
+func hasMemoryLimit(svc *yaml.Node) bool {
+	if labelsFindMapValue(svc, "mem_limit") != nil {
+		return true
+	}
+	n := labelsFindMapValue(svc, "deploy")
+	for _, key := range []string{"resources", "limits", "memory"} {
+		if n == nil || n.Kind != yaml.MappingNode {
+			return false
+		}
+		n = labelsFindMapValue(n, key)
+	}
+	return n != nil
+}
+
+// setDefaultEnv adds key=value to svc's `environment:` unless key is
+// already there. Handles both compose forms: the mapping (`KEY: value`)
+// and the list (`- KEY=value`, or a bare `- KEY` passed through from the
+// host). A missing environment block is created as a mapping.
+func setDefaultEnv(svc *yaml.Node, key, value string) {
+	env := labelsFindMapValue(svc, "environment")
+	switch {
+	case env == nil:
+		env = &yaml.Node{Kind: yaml.MappingNode}
+		labelsSetMapValue(svc, "environment", env)
+		fallthrough
+	case env.Kind == yaml.MappingNode:
+		if labelsFindMapValue(env, key) == nil {
+			labelsSetMapValue(env, key, &yaml.Node{Kind: yaml.ScalarNode, Value: value})
+		}
+	case env.Kind == yaml.SequenceNode:
+		for _, item := range env.Content {
+			if item.Value == key || strings.HasPrefix(item.Value, key+"=") {
+				return
+			}
+		}
+		env.Content = append(env.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key + "=" + value})
+	}
+}
+
+// loggingNode builds `{driver: <driver>, options: {k: v, ...}}` with option
+// keys sorted so the rendered file is stable across builds.
+// LogDriverRotates reports whether driver takes the max-size / max-file
+// rotation options: json-file and local do, the rest (syslog, journald,
+// ...) fail the deploy at `up` if given them.
+func LogDriverRotates(driver string) bool {
+	return driver == "json-file" || driver == "local"
+}
+
+func loggingNode(driver string, opts map[string]string) *yaml.Node {
+	n := &yaml.Node{Kind: yaml.MappingNode}
+	labelsSetMapValue(n, "driver", &yaml.Node{Kind: yaml.ScalarNode, Value: driver})
+	if len(opts) > 0 && LogDriverRotates(driver) {
+		keys := make([]string, 0, len(opts))
+		for k := range opts {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		on := &yaml.Node{Kind: yaml.MappingNode}
+		for _, k := range keys {
+			labelsSetMapValue(on, k, &yaml.Node{Kind: yaml.ScalarNode, Value: opts[k], Style: yaml.DoubleQuotedStyle})
+		}
+		labelsSetMapValue(n, "options", on)
+	}
+	return n
+}
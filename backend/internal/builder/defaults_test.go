@@ -0,0 +1,161 @@
+package builder
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyComposeDefaults_FillsLoggingOnlyWhenAbsent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "docker-compose.yaml")
+	src := `services:
+  app:
+    image: app
+  db:
+    image: postgres
+    logging:
+      driver: syslog
+`
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ApplyComposeDefaults(path, DefaultComposeDefaults()); err != nil {
+		t.Fatalf("ApplyComposeDefaults: %v", err)
+	}
+	// Second pass must be a no-op.
+	if err := ApplyComposeDefaults(path, DefaultComposeDefaults()); err != nil {
+		t.Fatal(err)
+	}
+	out, _ := os.ReadFile(path)
+	got := string(out)
+	if strings.Count(got, "driver: json-file") != 1 {
+		t.Errorf("expected json-file default on app only:\n%s", got)
+	}
+	if !strings.Contains(got, `max-size: "10m"`) || !strings.Contains(got, `max-file: "3"`) {
+		t.Errorf("missing rotation options:\n%s", got)
+	}
+	if !strings.Contains(got, "driver: syslog") {
+		t.Errorf("user logging config must be preserved:\n%s", got)
+	}
+}
+
+func TestApplyComposeDefaults_SyslogGetsNoRotationOptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "docker-compose.yaml")
+	_ = os.WriteFile(path, []byte("services:\n  app:\n    image: app\n"), 0644)
+	d := ComposeDefaults{LogDriver: "syslog", LogOptions: map[string]string{"max-size": "10m", "max-file": "3"}}
+	if err := ApplyComposeDefaults(path, d); err != nil {
+		t.Fatal(err)
+	}
+	out, _ := os.ReadFile(path)
+	got := string(out)
+	if !strings.Contains(got, "driver: syslog") {
+		t.Errorf("missing syslog driver:\n%s", got)
+	}
+	if strings.Contains(got, "options") {
+		t.Errorf("syslog must not get json-file rotation options:\n%s", got)
+	}
+}
+
+func TestApplyComposeDefaults_ZeroValueIsNoop(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "docker-compose.yaml")
+	_ = os.WriteFile(path, []byte("services:\n  app:\n    image: app\n"), 0644)
+	if err := ApplyComposeDefaults(path, ComposeDefaults{}); err != nil {
+		t.Fatal(err)
+	}
+	out, _ := os.ReadFile(path)
+	if strings.Contains(string(out), "logging") {
+		t.Errorf("zero defaults should not add logging:\n%s", out)
+	}
+}
+
+func TestApplyComposeDefaults_RestartAndMemLimit(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "docker-compose.yaml")
+	src := `services:
+  app:
+    image: app
+  worker:
+    image: worker
+    restart: "no"
+    deploy:
+      resources:
+        limits:
+          memory: 2g
+`
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	d := ComposeDefaults{Restart: "unless-stopped", MemLimit: "512m"}
+	if err := ApplyComposeDefaults(path, d); err != nil {
+		t.Fatalf("ApplyComposeDefaults: %v", err)
+	}
+	out, _ := os.ReadFile(path)
+	got := string(out)
+	if strings.Count(got, "restart: unless-stopped") != 1 {
+		t.Errorf("restart default should apply to app only:\n%s", got)
+	}
+	if strings.Count(got, "mem_limit: 512m") != 1 {
+		t.Errorf("mem_limit default should apply to app only:\n%s", got)
+	}
+	if !strings.Contains(got, `restart: "no"`) || !strings.Contains(got, "memory: 2g") {
+		t.Errorf("per-service settings must be preserved:\n%s", got)
+	}
+}
+
+func TestApplyComposeDefaults_Init(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "docker-compose.yaml")
+	src := "services:\n  app:\n    image: app\n  legacy:\n    image: legacy\n    init: false\n"
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ApplyComposeDefaults(path, ComposeDefaults{Init: true}); err != nil {
+		t.Fatal(err)
+	}
+	out, _ := os.ReadFile(path)
+	got := string(out)
+	if strings.Count(got, "init: true") != 1 || !strings.Contains(got, "init: false") {
+		t.Errorf("init default should apply to app only:\n%s", got)
+	}
+}
+
+func TestApplyComposeDefaults_TZ(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "docker-compose.yaml")
+	src := `services:
+  bare:
+    image: bare
+  mapped:
+    image: mapped
+    environment:
+      FOO: bar
+  listed:
+    image: listed
+    environment:
+      - FOO=bar
+  own:
+    image: own
+    environment:
+      - TZ=UTC
+`
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ApplyComposeDefaults(path, ComposeDefaults{TZ: "Europe/Amsterdam"}); err != nil {
+		t.Fatal(err)
+	}
+	out, _ := os.ReadFile(path)
+	got := string(out)
+	if n := strings.Count(got, "TZ: Europe/Amsterdam"); n != 2 {
+		t.Errorf("want TZ added to bare + mapped, got %d:\n%s", n, got)
+	}
+	if !strings.Contains(got, "- TZ=Europe/Amsterdam") || !strings.Contains(got, "- TZ=UTC") {
+		t.Errorf("list form should gain TZ; explicit TZ must be kept:\n%s", got)
+	}
+	if strings.Count(got, "TZ=") != 2 {
+		t.Errorf("service with its own TZ got a second one:\n%s", got)
+	}
+}
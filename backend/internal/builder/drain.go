@@ -0,0 +1,176 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/environment-manager/backend/internal/models"
+	"github.com/environment-manager/backend/internal/projects"
+)
+
+// ErrNotDrained is returned by Undrain when no drain is recorded.
+var ErrNotDrained = errors.New("host is not drained")
+
+// ErrDrained is returned by Build while a drain is recorded.
+var ErrDrained = errors.New("host is drained for maintenance; undrain before building")
+
+// drainFile records a drain under dataDir so it survives the restart or
+// reboot the drain was for.
+const drainFile = "drained.json"
+
+// DrainState is what Drain records and Undrain reads back.
+type DrainState struct {
+	DrainedAt time.Time `json:"drained_at"`
+	// Envs are the envs whose containers were stopped, or whose stop
+	// failed part way; Undrain starts exactly these.
+	Envs []string `json:"envs"`
+	// Failed maps env ID → error for envs whose stop or start failed.
+	Failed map[string]string `json:"failed,omitempty"`
+}
+
+// Drain stops the containers of every env whose stored status is running,
+// for planned host maintenance, and records which envs it stopped. Each
+// env is a `docker compose stop`, which stops its services in reverse
+// depends_on order. Stored env status is left as running: it still says
+// what should be up, and Undrain (or the next boot) brings it back.
+// Builds are refused with ErrDrained until then.
+//
+// Draining an already-drained host adds any newly running envs to the
+// record.
+func (r *Runner) Drain(ctx context.Context) (*DrainState, error) {
+	state, err := r.Drained()
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		state = &DrainState{}
+	}
+	state.DrainedAt = time.Now().UTC()
+	seen := map[string]bool{}
+	for _, id := range state.Envs {
+		seen[id] = true
+	}
+	envs, err := r.runningEnvs()
+	if err != nil {
+		return nil, err
+	}
+	for _, env := range envs {
+		if err := r.Stop(ctx, env); err != nil {
+			if errors.Is(err, ErrNotBuilt) {
+				continue
+			}
+			if state.Failed == nil {
+				state.Failed = map[string]string{}
+			}
+			state.Failed[env.ID] = err.Error()
+		}
+		if !seen[env.ID] {
+			state.Envs = append(state.Envs, env.ID)
+			seen[env.ID] = true
+		}
+	}
+	if err := r.writeDrain(state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// Undrain starts the envs recorded by Drain. The record is cleared once
+// every env has started; envs whose start failed stay recorded, so a
+// later Undrain retries them and builds stay refused. Envs destroyed in
+// the meantime are dropped. Returns ErrNotDrained when there is nothing
+// to undo.
+func (r *Runner) Undrain(ctx context.Context) (*DrainState, error) {
+	state, err := r.Drained()
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, ErrNotDrained
+	}
+	state.Failed = nil
+	var started, remaining []string
+	for _, id := range state.Envs {
+		env, err := r.findEnv(id)
+		if err != nil {
+			continue // destroyed while drained
+		}
+		if err := r.Start(ctx, env); err != nil {
+			if state.Failed == nil {
+				state.Failed = map[string]string{}
+			}
+			state.Failed[id] = err.Error()
+			remaining = append(remaining, id)
+			continue
+		}
+		started = append(started, id)
+	}
+	if len(remaining) > 0 {
+		rest := &DrainState{DrainedAt: state.DrainedAt, Envs: remaining, Failed: state.Failed}
+		if err := r.writeDrain(rest); err != nil {
+			return state, err
+		}
+	} else if err := os.Remove(filepath.Join(r.dataDir, drainFile)); err != nil && !os.IsNotExist(err) {
+		return state, err
+	}
+	state.Envs = started
+	return state, nil
+}
+
+func (r *Runner) writeDrain(state *DrainState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(r.dataDir, drainFile), data, 0644)
+}
+
+// Drained returns the recorded drain, or nil when the host isn't drained.
+func (r *Runner) Drained() (*DrainState, error) {
+	data, err := os.ReadFile(filepath.Join(r.dataDir, drainFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state DrainState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func (r *Runner) runningEnvs() ([]*models.Environment, error) {
+	projs, err := r.store.ListProjects()
+	if err != nil {
+		return nil, err
+	}
+	var out []*models.Environment
+	for _, p := range projs {
+		envs, err := r.store.ListEnvironments(p.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range envs {
+			if e.Status == models.EnvStatusRunning {
+				out = append(out, e)
+			}
+		}
+	}
+	return out, nil
+}
+
+// findEnv loads an env by its "<projectID>--<slug>" ID.
+func (r *Runner) findEnv(id string) (*models.Environment, error) {
+	projectID, slug, ok := strings.Cut(id, "--")
+	if !ok {
+		return nil, projects.ErrNotFound
+	}
+	return r.store.GetEnvironment(projectID, slug)
+}
@@ -0,0 +1,133 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/environment-manager/backend/internal/models"
+)
+
+func TestRunner_DrainUndrain(t *testing.T) {
+	r, store, _, env, dataDir, _ := newRunnerTest(t)
+	exec := &pullExecutor{}
+	r.exec = exec
+
+	env.Status = models.EnvStatusRunning
+	_ = store.SaveEnvironment(env)
+	// A running env that was never built, and a failed one: neither is
+	// recorded.
+	_ = store.SaveEnvironment(&models.Environment{ID: "p1--unbuilt", ProjectID: "p1", BranchSlug: "unbuilt", Status: models.EnvStatusRunning})
+	_ = store.SaveEnvironment(&models.Environment{ID: "p1--old", ProjectID: "p1", BranchSlug: "old", Status: models.EnvStatusFailed})
+	for _, id := range []string{env.ID, "p1--old"} {
+		if err := writeFiles(filepath.Join(dataDir, "envs", id), map[string]string{"docker-compose.yaml": "services:\n  app:\n    image: nginx:1\n"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := r.Undrain(context.Background()); !errors.Is(err, ErrNotDrained) {
+		t.Fatalf("Undrain before Drain: err = %v, want ErrNotDrained", err)
+	}
+	state, err := r.Drain(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Envs) != 1 || state.Envs[0] != env.ID || len(state.Failed) != 0 {
+		t.Fatalf("drain = %+v, want only %s", state, env.ID)
+	}
+	if got, _ := store.GetEnvironment("p1", "main"); got.Status != models.EnvStatusRunning {
+		t.Errorf("stored status = %s, want running kept", got.Status)
+	}
+	if rec, _ := r.Drained(); rec == nil {
+		t.Fatal("drain not recorded")
+	}
+
+	state, err = r.Undrain(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Envs) != 1 {
+		t.Errorf("undrain = %+v", state)
+	}
+	if rec, _ := r.Drained(); rec != nil {
+		t.Error("drain record not cleared")
+	}
+	verbs := []string{}
+	for _, c := range exec.calls {
+		verbs = append(verbs, c[len(c)-1])
+	}
+	if len(verbs) != 2 || verbs[0] != "stop" || verbs[1] != "start" {
+		t.Errorf("compose verbs = %v, want [stop start]", verbs)
+	}
+}
+
+func TestRunner_Build_RefusedWhileDrained(t *testing.T) {
+	r, store, _, env, _, exec := newRunnerTest(t)
+	env.Status = models.EnvStatusRunning
+	_ = store.SaveEnvironment(env)
+	if _, err := r.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	calls := exec.calls
+
+	build := &models.Build{ID: "b1", EnvID: env.ID, Status: models.BuildStatusRunning}
+	_ = store.SaveBuild("p1", build)
+	if err := r.Build(context.Background(), env, build); !errors.Is(err, ErrDrained) {
+		t.Fatalf("Build err = %v, want ErrDrained", err)
+	}
+	if exec.calls != calls {
+		t.Errorf("compose ran %d times while drained", exec.calls-calls)
+	}
+	if got, _ := store.GetEnvironment("p1", "main"); got.Status != models.EnvStatusRunning {
+		t.Errorf("env status = %s, want running kept", got.Status)
+	}
+	if got, _ := store.GetBuild("p1", "b1"); got.Status != models.BuildStatusFailed || got.FinishedAt == nil {
+		t.Errorf("build = %+v, want finished as failed", got)
+	}
+}
+
+func TestRunner_Undrain_KeepsFailedEnvs(t *testing.T) {
+	r, store, _, env, dataDir, _ := newRunnerTest(t)
+	exec := &pullExecutor{failService: "stop"}
+	r.exec = exec
+	env.Status = models.EnvStatusRunning
+	_ = store.SaveEnvironment(env)
+	if err := writeFiles(filepath.Join(dataDir, "envs", env.ID), map[string]string{"docker-compose.yaml": "services:\n  app:\n    image: nginx:1\n"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed stop is still recorded, so Undrain starts whatever did stop.
+	state, err := r.Drain(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Envs) != 1 || state.Failed[env.ID] == "" {
+		t.Fatalf("drain = %+v, want %s recorded as failed", state, env.ID)
+	}
+
+	exec.failService = "start"
+	state, err = r.Undrain(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Envs) != 0 || state.Failed[env.ID] == "" {
+		t.Errorf("undrain = %+v, want %s failed", state, env.ID)
+	}
+	rec, _ := r.Drained()
+	if rec == nil || len(rec.Envs) != 1 || rec.Envs[0] != env.ID {
+		t.Fatalf("record = %+v, want %s kept for a retry", rec, env.ID)
+	}
+
+	exec.failService = ""
+	state, err = r.Undrain(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Envs) != 1 || len(state.Failed) != 0 {
+		t.Errorf("retry = %+v, want %s started", state, env.ID)
+	}
+	if rec, _ := r.Drained(); rec != nil {
+		t.Error("drain record not cleared once every env started")
+	}
+}
@@ -3,6 +3,7 @@ package builder
 import (
 	"fmt"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -22,10 +23,35 @@ import (
 //     public domains fall back to HTTP-only routers; caller is expected to
 //     emit a warning. Plan 5 does NOT mutate Traefik command flags — that's
 //     a manual one-time host op covered by Plan 8.
+//   - WebEntrypoint / WebsecureEntrypoint: names of the Traefik entrypoints
+//     for plain HTTP and TLS routers. Must match the entrypoints the host's
+//     Traefik was started with. Empty → "web" / "websecure".
+//   - ServiceSubdomains: also route every other service with a ports:
+//     entry at <service>.<env.URL> (iac service_subdomains).
 type TraefikOptions struct {
-	ProxyNetwork     string
-	Domains          *iac.Domains
-	LetsencryptEmail string
+	ProxyNetwork        string
+	Domains             *iac.Domains
+	LetsencryptEmail    string
+	WebEntrypoint       string
+	WebsecureEntrypoint string
+	ServiceSubdomains   bool
+}
+
+// webEntrypoint returns the configured HTTP entrypoint name or "web".
+func (o TraefikOptions) webEntrypoint() string {
+	if o.WebEntrypoint != "" {
+		return o.WebEntrypoint
+	}
+	return "web"
+}
+
+// websecureEntrypoint returns the configured TLS entrypoint name or
+// "websecure".
+func (o TraefikOptions) websecureEntrypoint() string {
+	if o.WebsecureEntrypoint != "" {
+		return o.WebsecureEntrypoint
+	}
+	return "websecure"
 }
 
 // InjectTraefikLabels reads the compose file at composePath, injects Traefik
@@ -38,6 +64,12 @@ type TraefikOptions struct {
 //     extract the first port number from it.
 //   - If no target can be found, return nil (no-op).
 //
+// With opts.ServiceSubdomains, each remaining service that publishes a port
+// also gets an HTTP router on <service>.<env.URL> for its first port. A
+// service opts out with the label env-manager.subdomain=false, picks its
+// own prefix with env-manager.subdomain=<name>, and is left alone when it
+// sets traefik.enable itself.
+//
 // When proxyNetwork is empty the function returns nil immediately so that
 // tests that pass "" bypass label injection entirely.
 func InjectTraefikLabels(composePath string, env *models.Environment, expose *models.ExposeSpec, opts TraefikOptions) error {
@@ -45,26 +77,9 @@ func InjectTraefikLabels(composePath string, env *models.Environment, expose *mo
 		return nil
 	}
 
-	data, err := os.ReadFile(composePath)
+	doc, root, services, err := readComposeServices(composePath)
 	if err != nil {
-		return fmt.Errorf("read compose: %w", err)
-	}
-
-	var doc yaml.Node
-	if err := yaml.Unmarshal(data, &doc); err != nil {
-		return fmt.Errorf("parse compose YAML: %w", err)
-	}
-	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
-		return fmt.Errorf("compose YAML is empty")
-	}
-	root := doc.Content[0]
-	if root.Kind != yaml.MappingNode {
-		return fmt.Errorf("compose YAML root is not a mapping")
-	}
-
-	services := labelsFindMapValue(root, "services")
-	if services == nil || services.Kind != yaml.MappingNode {
-		return fmt.Errorf("compose YAML has no services mapping")
+		return err
 	}
 
 	// Determine target service + port.
@@ -87,13 +102,75 @@ func InjectTraefikLabels(composePath string, env *models.Environment, expose *mo
 	labelsEnsureNetworkOnService(svc, opts.ProxyNetwork)
 	labelsEnsureExternalNetwork(root, opts.ProxyNetwork)
 
-	out, err := yaml.Marshal(&doc)
+	if opts.ServiceSubdomains && env.URL != "" {
+		for i := 0; i+1 < len(services.Content); i += 2 {
+			name, other := services.Content[i].Value, services.Content[i+1]
+			if name == targetService || other == nil || other.Kind != yaml.MappingNode {
+				continue
+			}
+			prefix, ok, err := serviceSubdomain(name, other)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			port, found := extractFirstPort(labelsFindMapValue(other, "ports"))
+			if !found {
+				continue
+			}
+			labelsEnsureLabels(other, buildServiceSubdomainLabels(env, name, prefix+"."+env.URL, port, opts))
+			labelsEnsureNetworkOnService(other, opts.ProxyNetwork)
+		}
+	}
+
+	out, err := yaml.Marshal(doc)
 	if err != nil {
 		return fmt.Errorf("marshal compose YAML: %w", err)
 	}
 	return os.WriteFile(composePath, out, 0644)
 }
 
+// PreviewTraefikLabels computes the labels InjectTraefikLabels would add to
+// the compose file at composePath without writing anything. Returns the
+// target service name ("" when no routable service exists) and its labels.
+func PreviewTraefikLabels(composePath string, env *models.Environment, expose *models.ExposeSpec, opts TraefikOptions) (string, map[string]string, error) {
+	_, _, services, err := readComposeServices(composePath)
+	if err != nil {
+		return "", nil, err
+	}
+	targetService, targetPort, ok := resolveTarget(services, expose)
+	if !ok {
+		return "", map[string]string{}, nil
+	}
+	return targetService, buildTraefikLabels(env, targetPort, opts), nil
+}
+
+// readComposeServices parses the compose file at path and returns the
+// document, its root mapping, and the services mapping.
+func readComposeServices(path string) (doc, root, services *yaml.Node, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("read compose: %w", err)
+	}
+	doc = &yaml.Node{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, nil, nil, fmt.Errorf("parse compose YAML: %w", err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil, nil, nil, fmt.Errorf("compose YAML is empty")
+	}
+	root = doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, nil, nil, fmt.Errorf("compose YAML root is not a mapping")
+	}
+	services = labelsFindMapValue(root, "services")
+	if services == nil || services.Kind != yaml.MappingNode {
+		return nil, nil, nil, fmt.Errorf("compose YAML has no services mapping")
+	}
+	return doc, root, services, nil
+}
+
 // buildTraefikLabels assembles the Traefik label map for a service.
 //
 // Two modes:
@@ -116,7 +193,7 @@ func buildTraefikLabels(env *models.Environment, targetPort int, opts TraefikOpt
 	if opts.Domains == nil {
 		// Legacy single HTTP router on env.URL — preserve exact existing shape.
 		labels[fmt.Sprintf("traefik.http.routers.%s.rule", env.ID)] = fmt.Sprintf("Host(`%s`)", env.URL)
-		labels[fmt.Sprintf("traefik.http.routers.%s.entrypoints", env.ID)] = "web"
+		labels[fmt.Sprintf("traefik.http.routers.%s.entrypoints", env.ID)] = opts.webEntrypoint()
 		return labels
 	}
 
@@ -124,7 +201,7 @@ func buildTraefikLabels(env *models.Environment, targetPort int, opts TraefikOpt
 	if env.URL != "" {
 		homeRouter := env.ID + "-home"
 		labels[fmt.Sprintf("traefik.http.routers.%s.rule", homeRouter)] = fmt.Sprintf("Host(`%s`)", env.URL)
-		labels[fmt.Sprintf("traefik.http.routers.%s.entrypoints", homeRouter)] = "web"
+		labels[fmt.Sprintf("traefik.http.routers.%s.entrypoints", homeRouter)] = opts.webEntrypoint()
 		labels[fmt.Sprintf("traefik.http.routers.%s.service", homeRouter)] = env.ID
 	}
 
@@ -146,7 +223,7 @@ func buildTraefikLabels(env *models.Environment, targetPort int, opts TraefikOpt
 		labels[fmt.Sprintf("traefik.http.routers.%s.rule", publicRouter)] = formatHostRule(publicHosts)
 		labels[fmt.Sprintf("traefik.http.routers.%s.service", publicRouter)] = env.ID
 		if opts.LetsencryptEmail != "" {
-			labels[fmt.Sprintf("traefik.http.routers.%s.entrypoints", publicRouter)] = "websecure"
+			labels[fmt.Sprintf("traefik.http.routers.%s.entrypoints", publicRouter)] = opts.websecureEntrypoint()
 			labels[fmt.Sprintf("traefik.http.routers.%s.tls", publicRouter)] = "true"
 			labels[fmt.Sprintf("traefik.http.routers.%s.tls.certresolver", publicRouter)] = "letsencrypt"
 
@@ -154,7 +231,7 @@ func buildTraefikLabels(env *models.Environment, targetPort int, opts TraefikOpt
 			redirectRouter := env.ID + "-public-http"
 			middlewareName := "https-redirect-" + env.ID
 			labels[fmt.Sprintf("traefik.http.routers.%s.rule", redirectRouter)] = formatHostRule(publicHosts)
-			labels[fmt.Sprintf("traefik.http.routers.%s.entrypoints", redirectRouter)] = "web"
+			labels[fmt.Sprintf("traefik.http.routers.%s.entrypoints", redirectRouter)] = opts.webEntrypoint()
 			labels[fmt.Sprintf("traefik.http.routers.%s.middlewares", redirectRouter)] = middlewareName
 			labels[fmt.Sprintf("traefik.http.routers.%s.service", redirectRouter)] = env.ID
 			labels[fmt.Sprintf("traefik.http.middlewares.%s.redirectscheme.scheme", middlewareName)] = "https"
@@ -162,13 +239,81 @@ func buildTraefikLabels(env *models.Environment, targetPort int, opts TraefikOpt
 			// HTTP-only fallback when LE unconfigured. Caller (runner) is
 			// expected to log a warning. No redirect router emitted because
 			// there's no HTTPS endpoint to redirect to.
-			labels[fmt.Sprintf("traefik.http.routers.%s.entrypoints", publicRouter)] = "web"
+			labels[fmt.Sprintf("traefik.http.routers.%s.entrypoints", publicRouter)] = opts.webEntrypoint()
 		}
 	}
 
 	return labels
 }
 
+// serviceSubdomainLabel overrides a service's auto subdomain: "false"
+// disables it, any other value replaces the service name as the prefix.
+const serviceSubdomainLabel = "env-manager.subdomain"
+
+// dnsLabelRE matches one DNS label: the value ends up in a Traefik Host
+// rule, so a dot, space or backtick would change what the rule matches.
+var dnsLabelRE = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// serviceSubdomain returns the host prefix for a service's auto subdomain,
+// or ok=false when the service opted out or manages its own Traefik labels.
+// A serviceSubdomainLabel value that isn't a single DNS label is an error.
+func serviceSubdomain(name string, svc *yaml.Node) (prefix string, ok bool, err error) {
+	if _, set := serviceLabel(svc, "traefik.enable"); set {
+		return "", false, nil
+	}
+	if v, set := serviceLabel(svc, serviceSubdomainLabel); set {
+		if v == "false" {
+			return "", false, nil
+		}
+		if v != "" {
+			v = strings.ToLower(v)
+			if !dnsLabelRE.MatchString(v) {
+				return "", false, fmt.Errorf("service %q: %s %q is not a valid DNS label (letters, digits and inner hyphens, at most 63 characters)", name, serviceSubdomainLabel, v)
+			}
+			return v, true, nil
+		}
+	}
+	// Compose service names may contain underscores; hostnames may not.
+	return strings.ReplaceAll(strings.ToLower(name), "_", "-"), true, nil
+}
+
+// serviceLabel looks up a label on a service in either compose form
+// (mapping, or a list of "key=value").
+func serviceLabel(svc *yaml.Node, key string) (string, bool) {
+	labels := labelsFindMapValue(svc, "labels")
+	if labels == nil {
+		return "", false
+	}
+	switch labels.Kind {
+	case yaml.MappingNode:
+		if v := labelsFindMapValue(labels, key); v != nil {
+			return v.Value, true
+		}
+	case yaml.SequenceNode:
+		for _, n := range labels.Content {
+			if k, v, _ := strings.Cut(n.Value, "="); k == key {
+				return v, true
+			}
+		}
+	}
+	return "", false
+}
+
+// buildServiceSubdomainLabels routes one extra service at host over plain
+// HTTP, like the -home router. Router and backend are named
+// <env.ID>-<service> so they can't collide with the exposed service's.
+func buildServiceSubdomainLabels(env *models.Environment, service, host string, port int, opts TraefikOptions) map[string]string {
+	name := env.ID + "-" + service
+	return map[string]string{
+		"traefik.enable":         "true",
+		"traefik.docker.network": opts.ProxyNetwork,
+		fmt.Sprintf("traefik.http.services.%s.loadbalancer.server.port", name): strconv.Itoa(port),
+		fmt.Sprintf("traefik.http.routers.%s.rule", name):                      fmt.Sprintf("Host(`%s`)", host),
+		fmt.Sprintf("traefik.http.routers.%s.entrypoints", name):               opts.webEntrypoint(),
+		fmt.Sprintf("traefik.http.routers.%s.service", name):                   name,
+	}
+}
+
 // formatHostRule joins multiple hostnames into a Traefik Host(...) rule
 // using the || operator, e.g. Host(`a.com`) || Host(`b.com`).
 func formatHostRule(hosts []string) string {
@@ -457,3 +457,120 @@ func TestInjectTraefikLabels_V2_ProdEnvIgnoresPreviewPattern(t *testing.T) {
 		t.Errorf("preview pattern resolved for prod env; got:\n%s", out)
 	}
 }
+
+func TestInjectTraefikLabels_CustomEntrypoints(t *testing.T) {
+	dir := t.TempDir()
+	input := "services:\n  app:\n    image: alpine\n"
+	path := writeCompose(t, dir, input)
+
+	env := &models.Environment{ID: "p--main", URL: "myapp.home", Kind: models.EnvKindProd}
+	err := InjectTraefikLabels(path, env, &models.ExposeSpec{Service: "app", Port: 80}, TraefikOptions{
+		ProxyNetwork:        "my-net",
+		Domains:             &iac.Domains{Prod: []string{"blocksweb.nl"}},
+		LetsencryptEmail:    "ops@example.com",
+		WebEntrypoint:       "http8080",
+		WebsecureEntrypoint: "https8443",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := readCompose(t, path)
+	mustContain(t, out, "traefik.http.routers.p--main-home.entrypoints=http8080")
+	mustContain(t, out, "traefik.http.routers.p--main-public.entrypoints=https8443")
+	mustContain(t, out, "traefik.http.routers.p--main-public-http.entrypoints=http8080")
+}
+
+func TestPreviewTraefikLabels_DoesNotWrite(t *testing.T) {
+	dir := t.TempDir()
+	input := "services:\n  app:\n    image: alpine\n"
+	path := writeCompose(t, dir, input)
+
+	env := &models.Environment{ID: "p--main", URL: "myapp.home", Kind: models.EnvKindProd}
+	svc, labels, err := PreviewTraefikLabels(path, env, &models.ExposeSpec{Service: "app", Port: 8080}, TraefikOptions{ProxyNetwork: "my-net"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if svc != "app" {
+		t.Errorf("service = %q, want app", svc)
+	}
+	if labels["traefik.http.services.p--main.loadbalancer.server.port"] != "8080" {
+		t.Errorf("port label missing: %v", labels)
+	}
+	if readCompose(t, path) != input {
+		t.Error("preview must not modify the compose file")
+	}
+}
+
+func TestInjectTraefikLabels_ServiceSubdomains(t *testing.T) {
+	dir := t.TempDir()
+	input := `services:
+  web:
+    image: myapp
+    ports:
+      - "8080:3000"
+  admin_ui:
+    image: adminer
+    ports:
+      - "8081:8080"
+  mail:
+    image: mailhog
+    ports:
+      - "8025"
+    labels:
+      env-manager.subdomain: inbox
+  db:
+    image: postgres
+    ports:
+      - "5432:5432"
+    labels:
+      - env-manager.subdomain=false
+  custom:
+    image: custom
+    ports:
+      - "9000"
+    labels:
+      traefik.enable: "false"
+  worker:
+    image: myapp
+`
+	path := writeCompose(t, dir, input)
+	env := testEnv("p1--main", "myapp.home")
+	opts := TraefikOptions{ProxyNetwork: "proxy", ServiceSubdomains: true}
+	if err := InjectTraefikLabels(path, env, nil, opts); err != nil {
+		t.Fatal(err)
+	}
+	out := readCompose(t, path)
+
+	mustContain(t, out, "traefik.http.routers.p1--main.rule=Host(`myapp.home`)")
+	mustContain(t, out, "traefik.http.routers.p1--main-admin_ui.rule=Host(`admin-ui.myapp.home`)")
+	mustContain(t, out, "traefik.http.services.p1--main-admin_ui.loadbalancer.server.port=8080")
+	mustContain(t, out, "traefik.http.routers.p1--main-mail.rule=Host(`inbox.myapp.home`)")
+	for _, svc := range []string{"db", "custom", "worker"} {
+		if strings.Contains(out, "routers.p1--main-"+svc+".") {
+			t.Errorf("%s should not get a subdomain router:\n%s", svc, out)
+		}
+	}
+
+	// Off by default.
+	path = writeCompose(t, t.TempDir(), input)
+	if err := InjectTraefikLabels(path, env, nil, TraefikOptions{ProxyNetwork: "proxy"}); err != nil {
+		t.Fatal(err)
+	}
+	if out := readCompose(t, path); strings.Contains(out, "p1--main-admin_ui") {
+		t.Error("service subdomains injected without the option")
+	}
+}
+
+func TestInjectTraefikLabels_InvalidSubdomainLabel(t *testing.T) {
+	env := testEnv("p1--main", "myapp.home")
+	opts := TraefikOptions{ProxyNetwork: "proxy", ServiceSubdomains: true}
+	for _, bad := range []string{"a`) || Host(`evil.com", "in.box", "in box", "-inbox", strings.Repeat("a", 64)} {
+		input := "services:\n  web:\n    image: myapp\n    ports:\n      - \"8080:3000\"\n" +
+			"  mail:\n    image: mailhog\n    ports:\n      - \"8025\"\n    labels:\n      env-manager.subdomain: '" + bad + "'\n"
+		path := writeCompose(t, t.TempDir(), input)
+		err := InjectTraefikLabels(path, env, nil, opts)
+		if err == nil || !strings.Contains(err.Error(), "not a valid DNS label") {
+			t.Errorf("subdomain %q: err = %v, want a DNS label error", bad, err)
+		}
+	}
+}
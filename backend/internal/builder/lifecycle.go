@@ -0,0 +1,54 @@
+package builder
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/environment-manager/backend/internal/models"
+)
+
+// Stop stops an env's containers without removing them (`docker compose
+// stop`). The Environment row is left as-is, so its stored status still
+// says what the env should be doing and Start can bring it back. Returns
+// ErrNotBuilt when there is no rendered compose file.
+func (r *Runner) Stop(ctx context.Context, env *models.Environment) error {
+	return r.composeLifecycle(ctx, env, "stop")
+}
+
+// Start starts an env's existing, stopped containers (`docker compose
+// start`). Containers that are already running are left alone.
+func (r *Runner) Start(ctx context.Context, env *models.Environment) error {
+	return r.composeLifecycle(ctx, env, "start")
+}
+
+func (r *Runner) composeLifecycle(ctx context.Context, env *models.Environment, verb string) error {
+	envDir := filepath.Join(r.dataDir, "envs", env.ID)
+	if _, err := os.Stat(filepath.Join(envDir, "docker-compose.yaml")); os.IsNotExist(err) {
+		return ErrNotBuilt
+	}
+
+	release, err := r.queue.Acquire(ctx, env.ID)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	args := []string{"-f", "docker-compose.yaml", "-p", env.ID}
+	if project, err := r.store.GetProject(env.ProjectID); err == nil {
+		args = append(args, profileArgs(project.Profiles)...)
+	}
+	args = append(args, verb)
+	var stderr bytes.Buffer
+	if err := r.exec.Compose(ctx, env.ID, envDir, args, io.Discard, &stderr); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("compose %s: %w: %s", verb, err, msg)
+		}
+		return fmt.Errorf("compose %s: %w", verb, err)
+	}
+	return nil
+}
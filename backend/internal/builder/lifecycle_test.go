@@ -0,0 +1,31 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunner_StopStart(t *testing.T) {
+	r, _, _, env, dataDir, _ := newRunnerTest(t)
+	exec := &pullExecutor{}
+	r.exec = exec
+
+	if err := r.Stop(context.Background(), env); !errors.Is(err, ErrNotBuilt) {
+		t.Fatalf("Stop on unbuilt env: err = %v, want ErrNotBuilt", err)
+	}
+	envDir := filepath.Join(dataDir, "envs", env.ID)
+	if err := writeFiles(envDir, map[string]string{"docker-compose.yaml": "services:\n  app:\n    image: nginx:1\n"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Stop(context.Background(), env); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Start(context.Background(), env); err != nil {
+		t.Fatal(err)
+	}
+	if len(exec.calls) != 2 || exec.calls[0][len(exec.calls[0])-1] != "stop" || exec.calls[1][len(exec.calls[1])-1] != "start" {
+		t.Errorf("compose calls = %v, want stop then start", exec.calls)
+	}
+}
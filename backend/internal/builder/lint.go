@@ -0,0 +1,157 @@
+package builder
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Lint rule IDs reported in Advisory.Rule.
+const (
+	LintRootUser      = "root-user"
+	LintNoLimits      = "no-resource-limits"
+	LintNoHealthcheck = "no-healthcheck"
+	LintLatestTag     = "latest-tag"
+	LintPrivileged    = "privileged"
+	LintSensitiveBind = "sensitive-bind"
+	LintRestartNo     = "restart-no"
+)
+
+// Advisory is a non-fatal finding about one compose service. Unlike
+// validation errors, advisories never block a build.
+type Advisory struct {
+	Service string `json:"service"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// sensitiveHostPaths are host directories a bind mount shouldn't expose
+// to a container. Subdirectories count too, except for "/" itself.
+var sensitiveHostPaths = []string{
+	"/etc", "/root", "/home", "/boot", "/dev", "/proc", "/sys",
+	"/var/lib/docker", "/var/run/docker.sock", "/run/docker.sock",
+}
+
+// LintCompose checks every service of a compose file for risky but valid
+// configuration: running as root, no resource limits, no healthcheck, a
+// floating image tag, privileged mode, binds of sensitive host paths, and
+// an explicit restart: "no". Advisories come back in service order.
+//
+// Only the file as written is checked. The host's ComposeDefaults (a
+// restart policy, a mem_limit) may fill some gaps at render time, and an
+// image may declare its own USER or HEALTHCHECK — the messages say so.
+func LintCompose(data []byte) ([]Advisory, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse compose YAML: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("compose file is not a mapping")
+	}
+	services := labelsFindMapValue(doc.Content[0], "services")
+	if services == nil || services.Kind != yaml.MappingNode || len(services.Content) == 0 {
+		return nil, fmt.Errorf("no services defined")
+	}
+	out := []Advisory{}
+	for i := 0; i+1 < len(services.Content); i += 2 {
+		name, svc := services.Content[i].Value, services.Content[i+1]
+		if svc == nil || svc.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("service %q is not a mapping", name)
+		}
+		add := func(rule, msg string) {
+			out = append(out, Advisory{Service: name, Rule: rule, Message: msg})
+		}
+		if user := scalarValue(svc, "user"); user == "" {
+			add(LintRootUser, "no user: set; the container runs as the image's default user, which is often root")
+		} else if isRootUser(user) {
+			add(LintRootUser, "user: "+user+" runs the container as root")
+		}
+		if !hasResourceLimits(svc) {
+			add(LintNoLimits, "no mem_limit, cpus or deploy.resources.limits; one runaway container can starve the host")
+		}
+		if hc := labelsFindMapValue(svc, "healthcheck"); hc == nil {
+			add(LintNoHealthcheck, "no healthcheck:; dependents and status only see the container as started, unless the image declares HEALTHCHECK")
+		} else if scalarValue(hc, "disable") == "true" {
+			add(LintNoHealthcheck, "healthcheck is disabled")
+		}
+		if img := scalarValue(svc, "image"); floatingTag(img) {
+			add(LintLatestTag, "image "+img+" has no pinned tag; rebuilds may silently pick up a different version")
+		}
+		if scalarValue(svc, "privileged") == "true" {
+			add(LintPrivileged, "privileged: true gives the container full access to the host")
+		}
+		if vols := labelsFindMapValue(svc, "volumes"); vols != nil && vols.Kind == yaml.SequenceNode {
+			for _, v := range vols.Content {
+				if src := bindSource(v, true); sensitiveHostPath(src) {
+					add(LintSensitiveBind, "bind mount of host path "+src+" exposes host files to the container")
+				}
+			}
+		}
+		if scalarValue(svc, "restart") == "no" {
+			add(LintRestartNo, `restart: "no" leaves the service down after a crash or host reboot`)
+		}
+	}
+	return out, nil
+}
+
+// scalarValue returns the scalar value under key in m, or "".
+func scalarValue(m *yaml.Node, key string) string {
+	v := labelsFindMapValue(m, key)
+	if v == nil || v.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return strings.TrimSpace(v.Value)
+}
+
+// isRootUser reports whether a compose user: ("name", "uid", "uid:gid")
+// selects root.
+func isRootUser(user string) bool {
+	u, _, _ := strings.Cut(user, ":")
+	return u == "root" || u == "0"
+}
+
+func hasResourceLimits(svc *yaml.Node) bool {
+	for _, key := range []string{"mem_limit", "cpus", "cpu_quota"} {
+		if scalarValue(svc, key) != "" {
+			return true
+		}
+	}
+	deploy := labelsFindMapValue(svc, "deploy")
+	if deploy == nil {
+		return false
+	}
+	res := labelsFindMapValue(deploy, "resources")
+	if res == nil {
+		return false
+	}
+	limits := labelsFindMapValue(res, "limits")
+	return limits != nil && limits.Kind == yaml.MappingNode && len(limits.Content) > 0
+}
+
+// floatingTag reports whether image has no tag or the latest tag. Digest
+// references and images with ${VAR} interpolation are treated as pinned.
+func floatingTag(image string) bool {
+	if image == "" || strings.Contains(image, "@") || strings.Contains(image, "$") {
+		return false
+	}
+	last := image[strings.LastIndex(image, "/")+1:]
+	_, tag, ok := strings.Cut(last, ":")
+	return !ok || tag == "latest"
+}
+
+func sensitiveHostPath(src string) bool {
+	if !strings.HasPrefix(src, "/") {
+		return false
+	}
+	src = "/" + strings.Trim(src, "/")
+	if src == "/" {
+		return true
+	}
+	for _, p := range sensitiveHostPaths {
+		if src == p || strings.HasPrefix(src, p+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package builder
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLintCompose(t *testing.T) {
+	src := `services:
+  web:
+    image: nginx
+    privileged: true
+    restart: "no"
+    volumes:
+      - /var/run/docker.sock:/var/run/docker.sock
+      - /etc/ssl/certs:/certs:ro
+      - ./site:/usr/share/nginx/html
+      - data:/data
+      - type: bind
+        source: /
+        target: /host
+        bind:
+          create_host_path: true
+  db:
+    image: postgres:16
+    user: "999:999"
+    mem_limit: 512m
+    restart: unless-stopped
+    healthcheck:
+      test: ["CMD", "pg_isready"]
+  worker:
+    image: registry.local:5000/worker:latest
+    user: "0:0"
+    deploy:
+      resources:
+        limits:
+          cpus: "0.5"
+    healthcheck:
+      disable: true
+  pinned:
+    image: app@sha256:abc
+    user: app
+    cpus: 1
+    healthcheck:
+      test: ["CMD", "true"]
+`
+	got, err := LintCompose([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	type key struct{ service, rule string }
+	var keys []key
+	for _, a := range got {
+		if a.Message == "" {
+			t.Errorf("%s/%s: empty message", a.Service, a.Rule)
+		}
+		keys = append(keys, key{a.Service, a.Rule})
+	}
+	want := []key{
+		{"web", LintRootUser},
+		{"web", LintNoLimits},
+		{"web", LintNoHealthcheck},
+		{"web", LintLatestTag},
+		{"web", LintPrivileged},
+		{"web", LintSensitiveBind},
+		{"web", LintSensitiveBind},
+		{"web", LintSensitiveBind},
+		{"web", LintRestartNo},
+		{"worker", LintRootUser},
+		{"worker", LintNoHealthcheck},
+		{"worker", LintLatestTag},
+	}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("advisories = %v\nwant %v", keys, want)
+	}
+}
+
+func TestLintCompose_Invalid(t *testing.T) {
+	for name, src := range map[string]string{
+		"yaml":        "services: [",
+		"no services": "version: '3'\n",
+		"not mapping": "services:\n  web: nginx\n",
+	} {
+		if _, err := LintCompose([]byte(src)); err == nil {
+			t.Errorf("%s: want error", name)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package builder
+
+import "regexp"
+
+// profileRE matches a compose profile name as compose itself validates it.
+var profileRE = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// ValidProfile reports whether name is an acceptable compose profile.
+func ValidProfile(name string) bool {
+	return profileRE.MatchString(name)
+}
+
+// profileArgs expands a project's active profiles into compose's global
+// --profile flags. Build, up and down for an env all carry the same set, so
+// a reconcile brings back exactly the services the last deploy ran.
+func profileArgs(profiles []string) []string {
+	args := make([]string, 0, 2*len(profiles))
+	for _, p := range profiles {
+		args = append(args, "--profile", p)
+	}
+	return args
+}
@@ -0,0 +1,120 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/environment-manager/backend/internal/models"
+	"github.com/environment-manager/backend/internal/projects"
+)
+
+// ComposeContainerLister lists a compose project's containers. Implemented
+// by *docker.Client.
+type ComposeContainerLister interface {
+	ListComposeContainers(ctx context.Context, project string) ([]models.ComposeContainer, error)
+}
+
+// SetContainerLister lets PruneOrphans keep the dirs of compose projects
+// that still have containers. Call before serving.
+func (r *Runner) SetContainerLister(l ComposeContainerLister) {
+	r.containers = l
+}
+
+// PruneOrphans finds per-env runtime directories — rendered compose under
+// <dataDir>/envs/<env_id> and build logs under <dataDir>/builds/<env_id> —
+// whose environment no longer exists in the store, e.g. after an env row was
+// deleted while its teardown failed. Returns the orphaned paths (relative
+// to dataDir) and, unless dryRun, removes them.
+//
+// A dir whose compose project still has containers is kept: its rendered
+// compose is the only way left to `compose down` them.
+//
+// Build records and event history under projects/ are deliberately kept:
+// they're the audit trail for destroyed envs.
+func (r *Runner) PruneOrphans(ctx context.Context, dryRun bool) ([]string, error) {
+	live := map[string]bool{}
+	projs, err := r.store.ListProjects()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range projs {
+		envs, err := r.store.ListEnvironments(p.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range envs {
+			live[e.ID] = true
+		}
+	}
+
+	orphans := []string{}
+	hasContainers := map[string]bool{}
+	for _, sub := range []string{"envs", "builds"} {
+		entries, err := os.ReadDir(filepath.Join(r.dataDir, sub))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return orphans, err
+		}
+		for _, e := range entries {
+			id := e.Name()
+			if !e.IsDir() || live[id] {
+				continue
+			}
+			running, ok := hasContainers[id]
+			if !ok {
+				if running, err = r.projectHasContainers(ctx, id); err != nil {
+					return orphans, err
+				}
+				hasContainers[id] = running
+			}
+			if running {
+				continue
+			}
+			rel := filepath.Join(sub, id)
+			if !dryRun {
+				removed, err := r.removeOrphan(ctx, id, rel)
+				if err != nil {
+					return orphans, err
+				}
+				if !removed {
+					continue
+				}
+			}
+			orphans = append(orphans, filepath.ToSlash(rel))
+		}
+	}
+	return orphans, nil
+}
+
+// removeOrphan removes rel under the env's queue slot, so a build racing
+// to recreate the env can't have its directory removed underneath it. The
+// store is checked again once the slot is held; false means the env was
+// created in the meantime and rel was kept.
+func (r *Runner) removeOrphan(ctx context.Context, envID, rel string) (bool, error) {
+	release, err := r.queue.Acquire(ctx, envID)
+	if err != nil {
+		return false, err
+	}
+	defer release()
+	if _, err := r.findEnv(envID); err == nil {
+		return false, nil
+	} else if !errors.Is(err, projects.ErrNotFound) {
+		return false, err
+	}
+	return true, os.RemoveAll(filepath.Join(r.dataDir, rel))
+}
+
+func (r *Runner) projectHasContainers(ctx context.Context, envID string) (bool, error) {
+	if r.containers == nil {
+		return false, nil
+	}
+	list, err := r.containers.ListComposeContainers(ctx, envID)
+	if err != nil {
+		return false, err
+	}
+	return len(list) > 0, nil
+}
@@ -0,0 +1,111 @@
+package builder
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/environment-manager/backend/internal/models"
+	"github.com/environment-manager/backend/internal/projects"
+)
+
+func TestRunner_PruneOrphans(t *testing.T) {
+	r, _, _, env, dataDir, _ := newRunnerTest(t)
+	for _, d := range []string{
+		filepath.Join("envs", env.ID),
+		filepath.Join("builds", env.ID),
+		filepath.Join("envs", "p1--gone"),
+		filepath.Join("builds", "p1--gone"),
+	} {
+		if err := os.MkdirAll(filepath.Join(dataDir, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{"envs/p1--gone", "builds/p1--gone"}
+
+	got, err := r.PruneOrphans(context.Background(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("dry run = %v, want %v", got, want)
+	}
+	if !fileExists(filepath.Join(dataDir, "envs", "p1--gone")) {
+		t.Error("dry run must not remove anything")
+	}
+
+	if _, err := r.PruneOrphans(context.Background(), false); err != nil {
+		t.Fatal(err)
+	}
+	if fileExists(filepath.Join(dataDir, "envs", "p1--gone")) || fileExists(filepath.Join(dataDir, "builds", "p1--gone")) {
+		t.Error("orphans not removed")
+	}
+	if !fileExists(filepath.Join(dataDir, "envs", env.ID)) {
+		t.Error("live env dir must be kept")
+	}
+}
+
+// fakeContainerLister reports containers for the listed compose projects.
+type fakeContainerLister map[string]bool
+
+func (f fakeContainerLister) ListComposeContainers(_ context.Context, project string) ([]models.ComposeContainer, error) {
+	if f[project] {
+		return []models.ComposeContainer{{Name: project + "-app-1"}}, nil
+	}
+	return nil, nil
+}
+
+func TestRunner_PruneOrphans_KeepsProjectsWithContainers(t *testing.T) {
+	r, _, _, _, dataDir, _ := newRunnerTest(t)
+	r.SetContainerLister(fakeContainerLister{"p1--still-up": true})
+	for _, d := range []string{
+		filepath.Join("envs", "p1--still-up"),
+		filepath.Join("builds", "p1--still-up"),
+		filepath.Join("envs", "p1--gone"),
+	} {
+		if err := os.MkdirAll(filepath.Join(dataDir, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := r.PruneOrphans(context.Background(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"envs/p1--gone"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("pruned = %v, want %v", got, want)
+	}
+	if !fileExists(filepath.Join(dataDir, "envs", "p1--still-up")) {
+		t.Error("dir of a project with containers must be kept")
+	}
+}
+
+// creatingLister creates the env when prune asks about its containers,
+// i.e. after prune has read the store but before it takes the env's slot.
+type creatingLister struct {
+	store *projects.Store
+	env   *models.Environment
+}
+
+func (c creatingLister) ListComposeContainers(context.Context, string) ([]models.ComposeContainer, error) {
+	return nil, c.store.SaveEnvironment(c.env)
+}
+
+func TestRunner_PruneOrphans_RechecksStore(t *testing.T) {
+	r, store, _, _, dataDir, _ := newRunnerTest(t)
+	if err := os.MkdirAll(filepath.Join(dataDir, "envs", "p1--new"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	r.SetContainerLister(creatingLister{store: store, env: &models.Environment{ID: "p1--new", ProjectID: "p1", BranchSlug: "new"}})
+	got, err := r.PruneOrphans(context.Background(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("pruned = %v, want nothing", got)
+	}
+	if !fileExists(filepath.Join(dataDir, "envs", "p1--new")) {
+		t.Error("dir of an env created during prune was removed")
+	}
+}
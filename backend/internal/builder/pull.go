@@ -0,0 +1,94 @@
+package builder
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/environment-manager/backend/internal/models"
+)
+
+// ErrNotBuilt is returned by operations that need an env's rendered compose
+// file when the env has never been built.
+var ErrNotBuilt = errors.New("environment has not been built yet")
+
+// PullResult is the outcome of pulling one compose service's image.
+type PullResult struct {
+	Service string `json:"service"`
+	Image   string `json:"image,omitempty"`
+	// Skipped is set for services with a build: key or no image: key —
+	// they're built locally, so there's nothing to pull. Their image:, if
+	// any, is a local tag.
+	Skipped bool   `json:"skipped,omitempty"`
+	OK      bool   `json:"ok"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Pull pre-pulls the images of every service in the env's rendered compose
+// file, one `docker compose pull <service>` at a time so each service gets
+// its own result. An image error surfaces here rather than halfway through
+// the next `up`, and the following build starts from a warm cache.
+//
+// Runs under the env's queue slot so it never races a build re-rendering
+// the compose file. Progress is copied to log (may be nil).
+func (r *Runner) Pull(ctx context.Context, env *models.Environment, log io.Writer) ([]PullResult, error) {
+	envDir := filepath.Join(r.dataDir, "envs", env.ID)
+	composePath := filepath.Join(envDir, "docker-compose.yaml")
+	if _, err := os.Stat(composePath); os.IsNotExist(err) {
+		return nil, ErrNotBuilt
+	}
+	if log == nil {
+		log = io.Discard
+	}
+
+	release, err := r.queue.Acquire(ctx, env.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	_, _, services, err := readComposeServices(composePath)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]PullResult, 0, len(services.Content)/2)
+	for i := 0; i+1 < len(services.Content); i += 2 {
+		name := services.Content[i].Value
+		res := PullResult{Service: name}
+		if img := labelsFindMapValue(services.Content[i+1], "image"); img != nil {
+			res.Image = img.Value
+		}
+		if res.Image == "" || labelsFindMapValue(services.Content[i+1], "build") != nil {
+			res.Skipped = true
+			res.OK = true
+			results = append(results, res)
+			continue
+		}
+		releasePull, err := r.acquirePull(ctx)
+		if err != nil {
+			return results, err
+		}
+		_, _ = log.Write([]byte("==> docker compose pull " + name + "\n"))
+		var stderr bytes.Buffer
+		args := []string{"-f", "docker-compose.yaml", "-p", env.ID, "pull", name}
+		err = r.exec.Compose(ctx, env.ID, envDir, args, log, io.MultiWriter(log, &stderr))
+		releasePull()
+		if err != nil {
+			res.Error = strings.TrimSpace(stderr.String())
+			if res.Error == "" {
+				res.Error = err.Error()
+			}
+		} else {
+			res.OK = true
+		}
+		results = append(results, res)
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,144 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/environment-manager/backend/internal/models"
+)
+
+// pullExecutor fails pulls of the named service and records every call.
+type pullExecutor struct {
+	failService string
+	calls       [][]string
+}
+
+func (p *pullExecutor) Compose(_ context.Context, _, _ string, args []string, _, stderr io.Writer) error {
+	p.calls = append(p.calls, args)
+	if args[len(args)-1] == p.failService {
+		_, _ = stderr.Write([]byte("manifest unknown\n"))
+		return errors.New("exit status 1")
+	}
+	return nil
+}
+
+func TestRunner_Pull(t *testing.T) {
+	r, _, _, env, dataDir, _ := newRunnerTest(t)
+	exec := &pullExecutor{failService: "worker"}
+	r.exec = exec
+
+	envDir := filepath.Join(dataDir, "envs", env.ID)
+	if err := writeFiles(envDir, map[string]string{
+		"docker-compose.yaml": "services:\n  app:\n    image: nginx:1\n  worker:\n    image: bogus/none\n  api:\n    build: .\n  web:\n    build: .\n    image: myapp-web:dev\n",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := r.Pull(context.Background(), env, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("got %d results, want 4", len(results))
+	}
+	if !results[0].OK || results[0].Image != "nginx:1" {
+		t.Errorf("app = %+v", results[0])
+	}
+	if results[1].OK || !strings.Contains(results[1].Error, "manifest unknown") {
+		t.Errorf("worker = %+v", results[1])
+	}
+	if !results[2].Skipped {
+		t.Errorf("api (build-only) should be skipped: %+v", results[2])
+	}
+	if !results[3].Skipped || !results[3].OK {
+		t.Errorf("web (build with a local image tag) should be skipped: %+v", results[3])
+	}
+	if len(exec.calls) != 2 {
+		t.Errorf("compose calls = %d, want 2 (build-only service skipped)", len(exec.calls))
+	}
+}
+
+func TestRunner_Pull_NotBuilt(t *testing.T) {
+	r, _, _, env, dataDir, _ := newRunnerTest(t)
+	_ = os.RemoveAll(filepath.Join(dataDir, "envs", env.ID))
+	if _, err := r.Pull(context.Background(), env, nil); !errors.Is(err, ErrNotBuilt) {
+		t.Errorf("err = %v, want ErrNotBuilt", err)
+	}
+}
+
+// countingLimiter tracks how many pull slots are held at once.
+type countingLimiter struct {
+	held, max int
+}
+
+func (l *countingLimiter) AcquirePull(context.Context) (func(), error) {
+	l.held++
+	if l.held > l.max {
+		l.max = l.held
+	}
+	return func() { l.held-- }, nil
+}
+
+func TestRunner_Pull_HoldsPullSlotPerService(t *testing.T) {
+	r, _, _, env, dataDir, _ := newRunnerTest(t)
+	r.exec = &pullExecutor{}
+	lim := &countingLimiter{}
+	r.SetPullLimiter(lim)
+
+	envDir := filepath.Join(dataDir, "envs", env.ID)
+	if err := writeFiles(envDir, map[string]string{
+		"docker-compose.yaml": "services:\n  app:\n    image: nginx:1\n  worker:\n    image: redis:7\n",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Pull(context.Background(), env, nil); err != nil {
+		t.Fatal(err)
+	}
+	if lim.held != 0 || lim.max != 1 {
+		t.Errorf("held = %d, max = %d; want every slot released, one at a time", lim.held, lim.max)
+	}
+}
+
+// slotExecutor records, per compose subcommand, whether a pull slot was
+// held while it ran.
+type slotExecutor struct {
+	lim  *countingLimiter
+	held map[string]bool
+}
+
+func (s *slotExecutor) Compose(_ context.Context, _, _ string, args []string, _, _ io.Writer) error {
+	for _, verb := range []string{"build", "pull", "up"} {
+		for _, a := range args {
+			if a == verb {
+				s.held[verb] = s.lim.held > 0
+			}
+		}
+	}
+	return nil
+}
+
+func TestRunner_Build_PullSlotCoversPullOnly(t *testing.T) {
+	r, store, _, env, _, _ := newRunnerTest(t)
+	lim := &countingLimiter{}
+	exec := &slotExecutor{lim: lim, held: map[string]bool{}}
+	r.exec = exec
+	r.SetPullLimiter(lim)
+
+	build := &models.Build{ID: "b1", EnvID: env.ID, Status: models.BuildStatusRunning}
+	_ = store.SaveBuild("p1", build)
+	if err := r.Build(context.Background(), env, build); err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if want := map[string]bool{"build": false, "pull": true, "up": false}; !reflect.DeepEqual(exec.held, want) {
+		t.Errorf("slot held per step = %v, want %v", exec.held, want)
+	}
+	if lim.held != 0 {
+		t.Errorf("held = %d after build, want 0", lim.held)
+	}
+}
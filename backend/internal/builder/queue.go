@@ -1,29 +1,38 @@
 package builder
 
-import "sync"
+import (
+	"context"
+	"sync"
+)
 
 // Queue serializes operations on the same key while allowing different
 // keys to run in parallel. Used to ensure one build per env at a time.
 type Queue struct {
 	mu    sync.Mutex
-	locks map[string]*sync.Mutex
+	slots map[string]chan struct{}
 }
 
 // NewQueue returns an empty Queue.
 func NewQueue() *Queue {
-	return &Queue{locks: make(map[string]*sync.Mutex)}
+	return &Queue{slots: make(map[string]chan struct{})}
 }
 
-// Acquire blocks until the lock for key is available, then returns a
-// release function the caller MUST call (typically via defer).
-func (q *Queue) Acquire(key string) func() {
+// Acquire blocks until the slot for key is available, then returns a
+// release function the caller MUST call (typically via defer). If ctx is
+// done first, Acquire gives up without taking the slot and returns
+// ctx.Err().
+func (q *Queue) Acquire(ctx context.Context, key string) (func(), error) {
 	q.mu.Lock()
-	m, ok := q.locks[key]
+	slot, ok := q.slots[key]
 	if !ok {
-		m = &sync.Mutex{}
-		q.locks[key] = m
+		slot = make(chan struct{}, 1)
+		q.slots[key] = slot
 	}
 	q.mu.Unlock()
-	m.Lock()
-	return func() { m.Unlock() }
+	select {
+	case slot <- struct{}{}:
+		return func() { <-slot }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 }
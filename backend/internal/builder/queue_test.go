@@ -1,6 +1,8 @@
 package builder
 
 import (
+	"context"
+	"errors"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -17,7 +19,11 @@ func TestQueue_SerializesSameEnv(t *testing.T) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			release := q.Acquire("env-a")
+			release, err := q.Acquire(context.Background(), "env-a")
+			if err != nil {
+				t.Error(err)
+				return
+			}
 			defer release()
 			c := atomic.AddInt32(&concurrent, 1)
 			defer atomic.AddInt32(&concurrent, -1)
@@ -48,7 +54,11 @@ func TestQueue_ParallelDifferentEnvs(t *testing.T) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			release := q.Acquire(envID)
+			release, err := q.Acquire(context.Background(), envID)
+			if err != nil {
+				t.Error(err)
+				return
+			}
 			defer release()
 			c := atomic.AddInt32(&concurrent, 1)
 			defer atomic.AddInt32(&concurrent, -1)
@@ -67,3 +77,25 @@ func TestQueue_ParallelDifferentEnvs(t *testing.T) {
 		t.Errorf("maxConcurrent across 4 envs = %d, expected >= 2", maxConcurrent)
 	}
 }
+
+func TestQueue_AcquireGivesUpOnCancel(t *testing.T) {
+	q := NewQueue()
+	release, err := q.Acquire(context.Background(), "env-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := q.Acquire(ctx, "env-a"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Acquire on a held slot err = %v, want DeadlineExceeded", err)
+	}
+
+	// The abandoned wait must not have taken the slot.
+	release()
+	release, err = q.Acquire(context.Background(), "env-a")
+	if err != nil {
+		t.Fatalf("Acquire after release: %v", err)
+	}
+	release()
+}
@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
@@ -63,13 +64,20 @@ type RedisEnvACL struct {
 }
 
 // DockerComposeExecutor invokes the host's `docker compose` binary.
-type DockerComposeExecutor struct{}
+type DockerComposeExecutor struct {
+	// Env is appended to the inherited environment — DOCKER_HOST and
+	// friends when the daemon is configured explicitly. nil = inherit only.
+	Env []string
+}
 
 // Compose runs `docker compose <args>` in workdir with stdout/stderr piped
 // to the supplied writers.
-func (DockerComposeExecutor) Compose(ctx context.Context, projectName, workdir string, args []string, stdout, stderr io.Writer) error {
+func (e DockerComposeExecutor) Compose(ctx context.Context, projectName, workdir string, args []string, stdout, stderr io.Writer) error {
 	cmd := exec.CommandContext(ctx, "docker", append([]string{"compose"}, args...)...)
 	cmd.Dir = workdir
+	if len(e.Env) > 0 {
+		cmd.Env = append(os.Environ(), e.Env...)
+	}
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
 	return cmd.Run()
@@ -78,17 +86,33 @@ func (DockerComposeExecutor) Compose(ctx context.Context, projectName, workdir s
 // Runner builds an Environment: render compose, run `up -d --build`,
 // update Store with results.
 type Runner struct {
-	store            *projects.Store
-	exec             ComposeExecutor
-	dataDir          string
-	proxyNetwork     string
-	queue            *Queue
-	logger           *zap.Logger
-	logRing          int // ring buffer size for buildlog.Log
-	credStore        *credentials.Store
-	postgres         PostgresProvisioner // nil = postgres provisioning disabled
-	redis            RedisProvisioner    // nil = redis provisioning disabled
-	letsencryptEmail string              // "" = LE disabled, public domains serve HTTP only
+	store        *projects.Store
+	exec         ComposeExecutor
+	dataDir      string
+	proxyNetwork string
+	queue        *Queue
+	logger       *zap.Logger
+	logRing      int // ring buffer size for buildlog.Log
+	credStore    *credentials.Store
+	postgres     PostgresProvisioner    // nil = postgres provisioning disabled
+	redis        RedisProvisioner       // nil = redis provisioning disabled
+	pulls        PullLimiter            // nil = compose pulls unbounded
+	containers   ComposeContainerLister // nil = PruneOrphans can't see containers
+
+	// settingsMu guards the host-wide settings below, which a config
+	// reload may swap while builds are running.
+	settingsMu       sync.RWMutex
+	letsencryptEmail string          // "" = LE disabled, public domains serve HTTP only
+	composeDefaults  ComposeDefaults // host-wide per-service fallbacks (logging, ...)
+	webEntrypoint    string          // "" = "web"
+	secureEntrypoint string          // "" = "websecure"
+
+	// cancels holds the cancel func of every build that is queued or
+	// running, keyed by build ID. cancelled marks builds whose context was
+	// cancelled via Cancel so fail() can record them as such.
+	cancelMu  sync.Mutex
+	cancels   map[string]context.CancelFunc
+	cancelled map[string]bool
 }
 
 // NewRunner constructs a Runner. proxyNetwork is the name of the external
@@ -97,14 +121,17 @@ type Runner struct {
 // credStore may be nil — in that case secret injection is skipped.
 func NewRunner(store *projects.Store, exec ComposeExecutor, dataDir string, proxyNetwork string, queue *Queue, logger *zap.Logger, credStore *credentials.Store) *Runner {
 	return &Runner{
-		store:        store,
-		exec:         exec,
-		dataDir:      dataDir,
-		proxyNetwork: proxyNetwork,
-		queue:        queue,
-		logger:       logger,
-		logRing:      64 * 1024,
-		credStore:    credStore,
+		store:           store,
+		exec:            exec,
+		dataDir:         dataDir,
+		proxyNetwork:    proxyNetwork,
+		queue:           queue,
+		logger:          logger,
+		logRing:         64 * 1024,
+		credStore:       credStore,
+		composeDefaults: DefaultComposeDefaults(),
+		cancels:         make(map[string]context.CancelFunc),
+		cancelled:       make(map[string]bool),
 	}
 }
 
@@ -112,8 +139,28 @@ func NewRunner(store *projects.Store, exec ComposeExecutor, dataDir string, prox
 // The caller should have already saved the initial Build record with
 // Status=running and StartedAt set.
 func (r *Runner) Build(ctx context.Context, env *models.Environment, b *models.Build) error {
-	release := r.queue.Acquire(env.ID)
+	ctx, cancel := context.WithCancel(ctx)
+	r.trackBuild(b.ID, cancel)
+	defer r.untrackBuild(b.ID)
+	defer cancel()
+
+	// Cancelled while waiting behind another build of the same env: that
+	// build owns the env's status, so only this build record is finalised.
+	release, err := r.queue.Acquire(ctx, env.ID)
+	if err != nil {
+		return r.skip(env, b, fmt.Errorf("cancelled before start: %w", err))
+	}
 	defer release()
+	if err := ctx.Err(); err != nil {
+		return r.skip(env, b, fmt.Errorf("cancelled before start: %w", err))
+	}
+	// A drained host stays down until Undrain; a push or reconcile must
+	// not bring envs back up mid-maintenance.
+	if state, err := r.Drained(); err != nil {
+		return r.fail(env, b, "read drain record: "+err.Error())
+	} else if state != nil {
+		return r.skip(env, b, ErrDrained)
+	}
 
 	project, err := r.store.GetProject(env.ProjectID)
 	if err != nil {
@@ -134,10 +181,11 @@ func (r *Runner) Build(ctx context.Context, env *models.Environment, b *models.B
 	}
 	defer log.Close()
 	b.LogPath = logPath
-	_ = r.store.SaveBuild(env.ProjectID, b)
+	r.persistBuild(env, b)
 
 	env.Status = models.EnvStatusBuilding
-	_ = r.store.SaveEnvironment(env)
+	env.LastExitCode, env.OOMKilled = 0, false
+	r.persistEnv(env)
 
 	srcPath := filepath.Join(project.LocalPath, env.ComposeFile)
 	_, _ = log.Write([]byte("==> rendering compose: " + srcPath + "\n"))
@@ -196,16 +244,12 @@ func (r *Runner) Build(ctx context.Context, env *models.Environment, b *models.B
 
 	composePath := filepath.Join(envDir, "docker-compose.yaml")
 	_, _ = log.Write([]byte("==> injecting traefik labels\n"))
-	traefikOpts := TraefikOptions{
-		ProxyNetwork:     r.proxyNetwork,
-		LetsencryptEmail: r.letsencryptEmail,
-	}
+	traefikOpts := r.traefikOptions(iacCfg)
 	if iacCfg != nil {
-		traefikOpts.Domains = &iacCfg.Domains
 		// Surface a one-time warning if the operator declared public domains
 		// but didn't set LETSENCRYPT_EMAIL — the labels still emit HTTP-only
 		// routers, but TLS/redirect/LE won't apply.
-		if r.letsencryptEmail == "" && hasPublicDomains(env, &iacCfg.Domains) {
+		if traefikOpts.LetsencryptEmail == "" && hasPublicDomains(env, &iacCfg.Domains) {
 			_, _ = log.Write([]byte("WARNING: domains declared but LETSENCRYPT_EMAIL is unset; public domains will serve HTTP only\n"))
 		}
 	}
@@ -222,6 +266,19 @@ func (r *Runner) Build(ctx context.Context, env *models.Environment, b *models.B
 		}
 	}
 
+	r.settingsMu.RLock()
+	defaults := r.composeDefaults
+	r.settingsMu.RUnlock()
+	if err := ApplyComposeDefaults(composePath, defaults); err != nil {
+		_, _ = log.Write([]byte("ERROR: " + err.Error() + "\n"))
+		return r.fail(env, b, "apply compose defaults: "+err.Error())
+	}
+	if missing, err := MissingBindSources(composePath, project.LocalPath); err == nil {
+		for _, m := range missing {
+			_, _ = log.Write([]byte("WARNING: bind mount source does not exist (docker will create an empty dir): " + m + "\n"))
+		}
+	}
+
 	// --project-directory makes relative paths in the compose file (build
 	// contexts, dockerfile paths) resolve from the user's repo root rather
 	// than from envDir where the rendered compose lives. Without this,
@@ -232,11 +289,19 @@ func (r *Runner) Build(ctx context.Context, env *models.Environment, b *models.B
 		"-p", env.ID,
 		"--project-directory", project.LocalPath,
 	}
+	composeBaseArgs = append(composeBaseArgs, profileArgs(project.Profiles)...)
+
+	// Snapshot the compose as docker resolves it — `compose config` under
+	// the same args as the build, so ${VAR} substitution, .env and profiles
+	// are applied — next to the build log. Best-effort: a failure only
+	// costs the audit trail, not the deploy.
+	r.snapshotCompose(ctx, env, b, envDir, filepath.Join(logDir, b.ID+".compose.yaml"), composeBaseArgs, log)
 
 	_, _ = log.Write([]byte("==> docker compose build\n"))
 	buildArgs := append(append([]string(nil), composeBaseArgs...), "build")
 	if err := r.exec.Compose(ctx, env.ID, envDir, buildArgs, log, log); err != nil {
 		_, _ = log.Write([]byte("BUILD FAILED: " + err.Error() + "\n"))
+		r.recordExit(ctx, env, err, false)
 		return r.fail(env, b, err.Error())
 	}
 
@@ -247,15 +312,35 @@ func (r *Runner) Build(ctx context.Context, env *models.Environment, b *models.B
 		hookExec := r.newHookExecutor(iacCfg, env, project, envDir, log)
 		if err := hookExec.RunPre(ctx, iacCfg.Hooks.PreDeploy); err != nil {
 			_, _ = log.Write([]byte("ERROR: " + err.Error() + "\n"))
+			r.recordExit(ctx, env, err, false)
 			return r.fail(env, b, err.Error())
 		}
 	}
 	// ------------------------------------------------------------------------
 
+	// Pull missing images up front, under a pull slot, so `up` finds them
+	// all locally and container startup doesn't count against the cap.
+	// Services built above are skipped — their images aren't in a registry.
+	_, _ = log.Write([]byte("==> docker compose pull\n"))
+	pullArgs := append(append([]string(nil), composeBaseArgs...), "pull", "--quiet", "--ignore-buildable", "--policy", "missing")
+	releasePull, err := r.acquirePull(ctx)
+	if err != nil {
+		_, _ = log.Write([]byte("PULL FAILED: waiting for a pull slot: " + err.Error() + "\n"))
+		return r.fail(env, b, err.Error())
+	}
+	err = r.exec.Compose(ctx, env.ID, envDir, pullArgs, log, log)
+	releasePull()
+	if err != nil {
+		_, _ = log.Write([]byte("PULL FAILED: " + err.Error() + "\n"))
+		r.recordExit(ctx, env, err, false)
+		return r.fail(env, b, err.Error())
+	}
+
 	_, _ = log.Write([]byte("==> docker compose up -d\n"))
 	upArgs := append(append([]string(nil), composeBaseArgs...), "up", "-d")
 	if err := r.exec.Compose(ctx, env.ID, envDir, upArgs, log, log); err != nil {
 		_, _ = log.Write([]byte("UP FAILED: " + err.Error() + "\n"))
+		r.recordExit(ctx, env, err, true)
 		return r.fail(env, b, err.Error())
 	}
 
@@ -269,23 +354,46 @@ func (r *Runner) Build(ctx context.Context, env *models.Environment, b *models.B
 	now := time.Now().UTC()
 	b.FinishedAt = &now
 	b.Status = models.BuildStatusSuccess
-	_ = r.store.SaveBuild(env.ProjectID, b)
+	r.persistBuild(env, b)
 	env.Status = models.EnvStatusRunning
 	env.LastBuildID = b.ID
 	env.LastDeployedSHA = b.SHA
-	_ = r.store.SaveEnvironment(env)
+	r.persistEnv(env)
 	return nil
 }
 
 // Teardown removes an environment's containers, volumes, and per-env data
 // directory. The Environment row is NOT deleted by this method — caller
-// is responsible. Used by the branch-delete webhook flow.
+// is responsible. Used by the branch-delete webhook flow. Best-effort: a
+// failed `compose down` is logged, not returned.
 func (r *Runner) Teardown(ctx context.Context, env *models.Environment) error {
-	release := r.queue.Acquire(env.ID)
+	_, _ = r.TeardownWith(ctx, env, TeardownOptions{})
+	return nil
+}
+
+// TeardownOptions tunes TeardownWith. The zero value matches Teardown.
+type TeardownOptions struct {
+	// KeepVolumes runs `compose down` without -v, so the env's named
+	// volumes and their data survive the teardown.
+	KeepVolumes bool
+}
+
+// TeardownWith is Teardown with options. It returns the env's named
+// volumes as declared by the rendered compose file — removed, or left in
+// place when opts.KeepVolumes. Empty when the env was never built.
+//
+// The env and build directories are removed even when `compose down`
+// fails; its error is still returned so callers know the containers and
+// volumes may remain.
+func (r *Runner) TeardownWith(ctx context.Context, env *models.Environment, opts TeardownOptions) ([]string, error) {
+	release, err := r.queue.Acquire(ctx, env.ID)
+	if err != nil {
+		return nil, err
+	}
 	defer release()
 
 	env.Status = models.EnvStatusDestroying
-	_ = r.store.SaveEnvironment(env)
+	r.persistEnv(env)
 
 	envDir := filepath.Join(r.dataDir, "envs", env.ID)
 	composePath := filepath.Join(envDir, "docker-compose.yaml")
@@ -295,15 +403,28 @@ func (r *Runner) Teardown(ctx context.Context, env *models.Environment) error {
 	// If the rendered compose exists, run docker compose down -v to remove
 	// containers + named volumes. If it doesn't exist (env never built),
 	// skip the docker call.
+	var volumes []string
+	var downErr error
 	if _, err := os.Stat(composePath); err == nil {
+		volumes = composeVolumeNames(composePath, env.ID)
 		var stderr bytes.Buffer
-		args := []string{"-f", "docker-compose.yaml", "-p", env.ID, "down", "-v"}
+		args := []string{"-f", "docker-compose.yaml", "-p", env.ID}
+		// Pass the same profiles the env was brought up with — down only
+		// removes services it knows about.
+		if project, err := r.store.GetProject(env.ProjectID); err == nil {
+			args = append(args, profileArgs(project.Profiles)...)
+		}
+		args = append(args, "down")
+		if !opts.KeepVolumes {
+			args = append(args, "-v")
+		}
 		if err := r.exec.Compose(ctx, env.ID, envDir, args, io.Discard, &stderr); err != nil {
 			r.logger.Warn("docker compose down failed",
 				zap.String("env_id", env.ID),
 				zap.String("stderr", stderr.String()),
 				zap.Error(err))
 			// continue — we still want to clean up the directories
+			downErr = fmt.Errorf("compose down: %w", err)
 		}
 	}
 
@@ -316,7 +437,7 @@ func (r *Runner) Teardown(ctx context.Context, env *models.Environment) error {
 		r.logger.Warn("rm builds dir failed", zap.Error(err))
 	}
 
-	return nil
+	return volumes, downErr
 }
 
 // provisionServices parses the project's .dev/config.yaml and provisions any
@@ -424,14 +545,19 @@ func (r *Runner) newHookExecutor(cfg *iac.Config, env *models.Environment, proje
 	}
 }
 
-// fail marks the build + env as failed and returns the error wrapped.
+// fail marks the build + env as failed and returns the error wrapped. A
+// build stopped via Cancel is recorded as cancelled instead of failed.
 func (r *Runner) fail(env *models.Environment, b *models.Build, msg string) error {
 	now := time.Now().UTC()
 	b.FinishedAt = &now
 	b.Status = models.BuildStatusFailed
-	_ = r.store.SaveBuild(env.ProjectID, b)
+	if r.wasCancelled(b.ID) {
+		b.Status = models.BuildStatusCancelled
+		msg = "build cancelled: " + msg
+	}
+	r.persistBuild(env, b)
 	env.Status = models.EnvStatusFailed
-	_ = r.store.SaveEnvironment(env)
+	r.persistEnv(env)
 	r.logger.Warn("build failed",
 		zap.String("env_id", env.ID),
 		zap.String("build_id", b.ID),
@@ -439,6 +565,131 @@ func (r *Runner) fail(env *models.Environment, b *models.Build, msg string) erro
 	return errors.New(msg)
 }
 
+// snapshotCompose writes `docker compose config` output to path and
+// records it on b. The resolved config carries substituted secrets, so the
+// file is owner-only.
+func (r *Runner) snapshotCompose(ctx context.Context, env *models.Environment, b *models.Build, envDir, path string, baseArgs []string, log io.Writer) {
+	var out, stderr bytes.Buffer
+	args := append(append([]string(nil), baseArgs...), "config")
+	if err := r.exec.Compose(ctx, env.ID, envDir, args, &out, &stderr); err != nil {
+		msg := err.Error()
+		if s := strings.TrimSpace(stderr.String()); s != "" {
+			msg += ": " + s
+		}
+		_, _ = log.Write([]byte("WARNING: compose snapshot failed: " + msg + "\n"))
+		return
+	}
+	if err := os.WriteFile(path, out.Bytes(), 0600); err != nil {
+		_, _ = log.Write([]byte("WARNING: compose snapshot failed: " + err.Error() + "\n"))
+		return
+	}
+	b.ComposePath = path
+	r.persistBuild(env, b)
+}
+
+// recordExit notes on env the exit code of the compose command behind
+// err, so the failed transition that follows records it. When containers
+// is set (a failed `up`), a service container that exited non-zero or was
+// OOM-killed is the better answer — compose itself only exits 1.
+func (r *Runner) recordExit(ctx context.Context, env *models.Environment, err error, containers bool) {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		env.LastExitCode = exitErr.ExitCode()
+	}
+	if !containers || r.containers == nil || ctx.Err() != nil {
+		return
+	}
+	list, lerr := r.containers.ListComposeContainers(ctx, env.ID)
+	if lerr != nil {
+		r.logger.Warn("list containers after failed up",
+			zap.String("env_id", env.ID), zap.Error(lerr))
+		return
+	}
+	for _, c := range list {
+		if c.OOMKilled || c.ExitCode != 0 {
+			env.LastExitCode, env.OOMKilled = c.ExitCode, c.OOMKilled
+			return
+		}
+	}
+}
+
+// skip finishes b without running it. Nothing was deployed, so env keeps
+// its stored status.
+func (r *Runner) skip(env *models.Environment, b *models.Build, reason error) error {
+	now := time.Now().UTC()
+	b.FinishedAt = &now
+	b.Status = models.BuildStatusFailed
+	if r.wasCancelled(b.ID) {
+		b.Status = models.BuildStatusCancelled
+	}
+	r.persistBuild(env, b)
+	r.logger.Warn("build skipped",
+		zap.String("env_id", env.ID),
+		zap.String("build_id", b.ID),
+		zap.Error(reason))
+	return reason
+}
+
+// Cancel aborts a queued or running build by cancelling its context. The
+// in-flight `docker compose` process is killed; docker itself discards any
+// partially pulled layers. Returns false when no build with that ID is
+// active. The build record is finalised (status=cancelled) by Build itself
+// once the pipeline unwinds.
+func (r *Runner) Cancel(buildID string) bool {
+	r.cancelMu.Lock()
+	defer r.cancelMu.Unlock()
+	cancel, ok := r.cancels[buildID]
+	if !ok {
+		return false
+	}
+	r.cancelled[buildID] = true
+	cancel()
+	return true
+}
+
+func (r *Runner) trackBuild(buildID string, cancel context.CancelFunc) {
+	r.cancelMu.Lock()
+	r.cancels[buildID] = cancel
+	r.cancelMu.Unlock()
+}
+
+func (r *Runner) untrackBuild(buildID string) {
+	r.cancelMu.Lock()
+	delete(r.cancels, buildID)
+	delete(r.cancelled, buildID)
+	r.cancelMu.Unlock()
+}
+
+func (r *Runner) wasCancelled(buildID string) bool {
+	r.cancelMu.Lock()
+	defer r.cancelMu.Unlock()
+	return r.cancelled[buildID]
+}
+
+// persistBuild saves b, logging rather than returning a store failure: the
+// pipeline must still run to completion, but a build record stuck at
+// "running" on disk needs to be traceable.
+func (r *Runner) persistBuild(env *models.Environment, b *models.Build) {
+	if err := r.store.SaveBuild(env.ProjectID, b); err != nil {
+		r.logger.Error("save build record failed",
+			zap.String("env_id", env.ID),
+			zap.String("build_id", b.ID),
+			zap.String("status", string(b.Status)),
+			zap.Error(err))
+	}
+}
+
+// persistEnv saves env, logging rather than returning a store failure. See
+// persistBuild.
+func (r *Runner) persistEnv(env *models.Environment) {
+	if err := r.store.SaveEnvironment(env); err != nil {
+		r.logger.Error("save environment failed",
+			zap.String("env_id", env.ID),
+			zap.String("status", string(env.Status)),
+			zap.Error(err))
+	}
+}
+
 // SetServiceProvisioners wires the per-env service provisioners. Either or
 // both may be nil; nil disables provisioning for that service. Safe to call
 // before serving but not concurrently with Build/Teardown.
@@ -447,13 +698,118 @@ func (r *Runner) SetServiceProvisioners(pg PostgresProvisioner, rd RedisProvisio
 	r.redis = rd
 }
 
+// PullLimiter bounds concurrent image pulls host-wide. Implemented by
+// *docker.Client, so compose pulls share the cap with the client's own.
+type PullLimiter interface {
+	AcquirePull(ctx context.Context) (func(), error)
+}
+
+// SetPullLimiter makes Pull and the build's `compose pull` wait for a
+// pull slot.
+// Call before serving.
+func (r *Runner) SetPullLimiter(l PullLimiter) {
+	r.pulls = l
+}
+
+func (r *Runner) acquirePull(ctx context.Context) (func(), error) {
+	if r.pulls == nil {
+		return func() {}, nil
+	}
+	return r.pulls.AcquirePull(ctx)
+}
+
 // SetLetsencryptEmail wires the Let's Encrypt email used by the v2 Traefik
 // label generator. Empty string means LE is disabled — public domains will
-// fall back to plain HTTP routers and the build log will warn.
+// fall back to plain HTTP routers and the build log will warn. Safe to call
+// while builds run; the next build picks it up.
 func (r *Runner) SetLetsencryptEmail(email string) {
+	r.settingsMu.Lock()
+	defer r.settingsMu.Unlock()
 	r.letsencryptEmail = email
 }
 
+// LetsencryptEmail returns the email last set by SetLetsencryptEmail.
+func (r *Runner) LetsencryptEmail() string {
+	r.settingsMu.RLock()
+	defer r.settingsMu.RUnlock()
+	return r.letsencryptEmail
+}
+
+// traefikOptions assembles the label-generator options for an env whose
+// parsed iac config is cfg (nil when absent).
+func (r *Runner) traefikOptions(cfg *iac.Config) TraefikOptions {
+	r.settingsMu.RLock()
+	defer r.settingsMu.RUnlock()
+	opts := TraefikOptions{
+		ProxyNetwork:        r.proxyNetwork,
+		LetsencryptEmail:    r.letsencryptEmail,
+		WebEntrypoint:       r.webEntrypoint,
+		WebsecureEntrypoint: r.secureEntrypoint,
+	}
+	if cfg != nil {
+		opts.Domains = &cfg.Domains
+		opts.ServiceSubdomains = cfg.ServiceSubdomains
+	}
+	return opts
+}
+
+// LabelPreview is the result of Runner.PreviewLabels.
+type LabelPreview struct {
+	Service string            `json:"service"` // "" when no routable service
+	Network string            `json:"network"` // proxy network attached to Service
+	Labels  map[string]string `json:"labels"`
+}
+
+// PreviewLabels returns the Traefik labels the next build of env would
+// inject, computed from the project's current checkout. Nothing is written.
+// Labels is empty when label injection is disabled (no proxy network) or
+// the compose has no routable service.
+func (r *Runner) PreviewLabels(env *models.Environment) (*LabelPreview, error) {
+	project, err := r.store.GetProject(env.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("load project: %w", err)
+	}
+	return r.PreviewProjectLabels(project, env)
+}
+
+// PreviewProjectLabels is PreviewLabels for a project that needn't be in
+// the store yet — an onboarding dry run previews a project it never saves.
+func (r *Runner) PreviewProjectLabels(project *models.Project, env *models.Environment) (*LabelPreview, error) {
+	preview := &LabelPreview{Network: r.proxyNetwork, Labels: map[string]string{}}
+	if r.proxyNetwork == "" {
+		return preview, nil
+	}
+	var cfg *iac.Config
+	if data, ferr := os.ReadFile(filepath.Join(project.LocalPath, ".dev", "config.yaml")); ferr == nil {
+		cfg, _ = iac.Parse(data)
+	}
+	svc, labels, err := PreviewTraefikLabels(filepath.Join(project.LocalPath, env.ComposeFile), env, project.Expose, r.traefikOptions(cfg))
+	if err != nil {
+		return nil, err
+	}
+	preview.Service, preview.Labels = svc, labels
+	return preview, nil
+}
+
+// SetTraefikEntrypoints overrides the Traefik entrypoint names used on
+// generated routers. Empty strings keep the "web" / "websecure" defaults.
+// Safe to call while builds run.
+func (r *Runner) SetTraefikEntrypoints(web, websecure string) {
+	r.settingsMu.Lock()
+	defer r.settingsMu.Unlock()
+	r.webEntrypoint = web
+	r.secureEntrypoint = websecure
+}
+
+// SetComposeDefaults replaces the host-wide per-service defaults applied to
+// every rendered compose file. Pass ComposeDefaults{} to apply nothing.
+// Safe to call while builds run.
+func (r *Runner) SetComposeDefaults(d ComposeDefaults) {
+	r.settingsMu.Lock()
+	defer r.settingsMu.Unlock()
+	r.composeDefaults = d
+}
+
 // hasPublicDomains returns true when the env will receive any non-.home
 // router. Used by the runner to surface a warning when LE is unset.
 func hasPublicDomains(env *models.Environment, d *iac.Domains) bool {
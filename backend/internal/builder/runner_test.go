@@ -5,11 +5,16 @@ import (
 	"errors"
 	"io"
 	"os"
+	osexec "os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 
 	"github.com/environment-manager/backend/internal/credentials"
 	"github.com/environment-manager/backend/internal/models"
@@ -103,8 +108,8 @@ func TestRunner_BuildSuccess(t *testing.T) {
 		t.Fatalf("Build: %v", err)
 	}
 
-	if exec.calls != 2 {
-		t.Errorf("exec calls = %d, want 2 (build + up)", exec.calls)
+	if exec.calls != 4 {
+		t.Errorf("exec calls = %d, want 4 (config + build + pull + up)", exec.calls)
 	}
 	gotEnv, _ := store.GetEnvironment(env.ProjectID, env.BranchSlug)
 	if gotEnv.Status != models.EnvStatusRunning {
@@ -120,6 +125,150 @@ func TestRunner_BuildSuccess(t *testing.T) {
 	}
 }
 
+// configExecutor answers `compose config` with resolved and fails nothing
+// else, recording every call's args.
+type configExecutor struct {
+	resolved string
+	err      error
+	argsList [][]string
+}
+
+func (c *configExecutor) Compose(_ context.Context, _, _ string, args []string, stdout, _ io.Writer) error {
+	c.argsList = append(c.argsList, append([]string(nil), args...))
+	if args[len(args)-1] != "config" {
+		return nil
+	}
+	if c.err != nil {
+		return c.err
+	}
+	_, _ = stdout.Write([]byte(c.resolved))
+	return nil
+}
+
+func TestRunner_BuildSnapshotsCompose(t *testing.T) {
+	r, store, project, env, dataDir, _ := newRunnerTest(t)
+	exec := &configExecutor{resolved: "services:\n  app:\n    image: app:1.2.3\n"}
+	r.exec = exec
+	project.Profiles = []string{"debug"}
+	if err := store.SaveProject(project); err != nil {
+		t.Fatal(err)
+	}
+
+	build := &models.Build{ID: "b1", EnvID: env.ID, Status: models.BuildStatusRunning}
+	if err := store.SaveBuild("p1", build); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Build(context.Background(), env, build); err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	gotBuild, _ := store.GetBuild("p1", build.ID)
+	want := filepath.Join(dataDir, "builds", env.ID, build.ID+".compose.yaml")
+	if gotBuild.ComposePath != want {
+		t.Fatalf("ComposePath = %q, want %q", gotBuild.ComposePath, want)
+	}
+	data, err := os.ReadFile(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The snapshot is what `compose config` resolved, not the rendered file.
+	if string(data) != exec.resolved {
+		t.Errorf("snapshot = %q, want the compose config output", data)
+	}
+	if info, _ := os.Stat(want); info.Mode().Perm() != 0600 {
+		t.Errorf("snapshot mode = %v, want 0600", info.Mode().Perm())
+	}
+	// Resolved under the same args as the build.
+	config, build0 := exec.argsList[0], exec.argsList[1]
+	if got, want := strings.Join(config[:len(config)-1], " "), strings.Join(build0[:len(build0)-1], " "); got != want {
+		t.Errorf("config args = %q, want the build's %q", got, want)
+	}
+}
+
+func TestRunner_BuildSnapshotFailureIsNotFatal(t *testing.T) {
+	r, store, _, env, dataDir, _ := newRunnerTest(t)
+	r.exec = &configExecutor{err: errors.New("exit status 15")}
+
+	build := &models.Build{ID: "b1", EnvID: env.ID, Status: models.BuildStatusRunning}
+	_ = store.SaveBuild("p1", build)
+	if err := r.Build(context.Background(), env, build); err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	gotBuild, _ := store.GetBuild("p1", build.ID)
+	if gotBuild.ComposePath != "" {
+		t.Errorf("ComposePath = %q, want empty when the snapshot failed", gotBuild.ComposePath)
+	}
+	logBytes, _ := os.ReadFile(filepath.Join(dataDir, "builds", env.ID, build.ID+".log"))
+	if !strings.Contains(string(logBytes), "WARNING: compose snapshot failed: exit status 15") {
+		t.Errorf("log missing snapshot warning:\n%s", logBytes)
+	}
+}
+
+// exitError returns the *exec.ExitError of a process that exited with code.
+func exitError(t *testing.T, code int) error {
+	t.Helper()
+	err := osexec.Command("sh", "-c", "exit "+strconv.Itoa(code)).Run()
+	var exitErr *osexec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("sh exit %d: %v", code, err)
+	}
+	return err
+}
+
+// staticLister reports the same containers for every project.
+type staticLister []models.ComposeContainer
+
+func (s staticLister) ListComposeContainers(context.Context, string) ([]models.ComposeContainer, error) {
+	return s, nil
+}
+
+func TestRunner_BuildFailureRecordsExit(t *testing.T) {
+	cases := []struct {
+		name       string
+		exitErrs   []error
+		containers staticLister
+		wantCode   int
+		wantOOM    bool
+	}{
+		// config, build (fails)
+		{name: "build", exitErrs: []error{nil, exitError(t, 17)}, wantCode: 17},
+		// config, build, pull, up (fails) — the dead container explains it.
+		{
+			name:     "up oom",
+			exitErrs: []error{nil, nil, nil, exitError(t, 1)},
+			containers: staticLister{
+				{Service: "app", State: "running"},
+				{Service: "worker", State: "exited", ExitCode: 137, OOMKilled: true},
+			},
+			wantCode: 137, wantOOM: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r, store, _, env, _, _ := newRunnerTest(t)
+			r.exec = &fakeOrderedExecutor{exitErrs: c.exitErrs}
+			r.SetContainerLister(c.containers)
+
+			build := &models.Build{ID: "b1", EnvID: env.ID, Status: models.BuildStatusRunning}
+			_ = store.SaveBuild("p1", build)
+			if err := r.Build(context.Background(), env, build); err == nil {
+				t.Fatal("Build should fail")
+			}
+
+			got, _ := store.GetEnvironment(env.ProjectID, env.BranchSlug)
+			if got.LastExitCode != c.wantCode || got.OOMKilled != c.wantOOM {
+				t.Errorf("env exit = %d oom=%v, want %d oom=%v", got.LastExitCode, got.OOMKilled, c.wantCode, c.wantOOM)
+			}
+			events, _ := store.ListEnvEvents(env.ProjectID, env.BranchSlug, 0)
+			last := events[len(events)-1]
+			if last.To != models.EnvStatusFailed || last.ExitCode != c.wantCode || last.OOMKilled != c.wantOOM {
+				t.Errorf("last event = %+v, want failed with exit %d oom=%v", last, c.wantCode, c.wantOOM)
+			}
+		})
+	}
+}
+
 func TestRunner_Teardown(t *testing.T) {
 	r, store, _, env, dataDir, exec := newRunnerTest(t)
 	// Pretend a previous build happened — render a compose file.
@@ -153,6 +302,66 @@ func TestRunner_Teardown(t *testing.T) {
 	_ = store
 }
 
+func TestRunner_TeardownWith_Volumes(t *testing.T) {
+	compose := `services:
+  app:
+    image: app
+volumes:
+  data: {}
+  cache:
+    name: shared-cache
+  ext:
+    external: true
+`
+	for _, keep := range []bool{false, true} {
+		r, _, _, env, dataDir, _ := newRunnerTest(t)
+		exec := &fakeOrderedExecutor{}
+		r.exec = exec
+		envDir := filepath.Join(dataDir, "envs", env.ID)
+		if err := os.MkdirAll(envDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(envDir, "docker-compose.yaml"), []byte(compose), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		vols, err := r.TeardownWith(context.Background(), env, TeardownOptions{KeepVolumes: keep})
+		if err != nil {
+			t.Fatalf("TeardownWith: %v", err)
+		}
+		want := []string{env.ID + "_data", "shared-cache"}
+		if strings.Join(vols, ",") != strings.Join(want, ",") {
+			t.Errorf("keep=%v: volumes = %v, want %v", keep, vols, want)
+		}
+		if len(exec.argsList) != 1 {
+			t.Fatalf("keep=%v: compose calls = %d, want 1", keep, len(exec.argsList))
+		}
+		args := strings.Join(exec.argsList[0], " ")
+		if hasV := strings.HasSuffix(args, "down -v"); hasV == keep {
+			t.Errorf("keep=%v: args = %q", keep, args)
+		}
+	}
+}
+
+func TestRunner_TeardownWith_DownFailure(t *testing.T) {
+	r, _, _, env, dataDir, _ := newRunnerTest(t)
+	r.exec = &fakeOrderedExecutor{exitErrs: []error{errors.New("exit status 1")}}
+	envDir := filepath.Join(dataDir, "envs", env.ID)
+	if err := os.MkdirAll(envDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(envDir, "docker-compose.yaml"), []byte("services:\n  app:\n    image: app\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.TeardownWith(context.Background(), env, TeardownOptions{}); err == nil {
+		t.Error("TeardownWith: want the compose down error")
+	}
+	if _, err := os.Stat(envDir); !os.IsNotExist(err) {
+		t.Errorf("env dir should be removed even when down fails")
+	}
+}
+
 func TestRunner_Teardown_NeverBuilt(t *testing.T) {
 	r, _, _, env, dataDir, exec := newRunnerTest(t)
 	// No compose file — env was never built.
@@ -273,6 +482,77 @@ func TestRunner_BuildFailure(t *testing.T) {
 	}
 }
 
+// breakStore makes every build and env write for p1/main fail: a regular
+// file where the builds directory goes, and a directory where the env
+// record goes. Both hold even for root, unlike a chmod.
+func breakStore(t *testing.T, store *projects.Store) {
+	t.Helper()
+	projDir := filepath.Join(store.Root(), "p1")
+	if err := os.RemoveAll(filepath.Join(projDir, "builds")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(projDir, "builds"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	envPath := filepath.Join(projDir, "environments", "main.yaml")
+	if err := os.Remove(envPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(envPath, "keep"), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunner_StoreErrorsAreLoggedNotFatal(t *testing.T) {
+	cases := []struct {
+		name      string
+		exitErr   error
+		wantBuild models.BuildStatus
+		wantEnv   models.EnvironmentStatus
+	}{
+		{"success", nil, models.BuildStatusSuccess, models.EnvStatusRunning},
+		{"failure", errors.New("docker exited with 1"), models.BuildStatusFailed, models.EnvStatusFailed},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r, store, _, env, _, exec := newRunnerTest(t)
+			exec.exitErr = c.exitErr
+			core, logs := observer.New(zap.ErrorLevel)
+			r.logger = zap.New(core)
+			breakStore(t, store)
+
+			build := &models.Build{
+				ID: "b1", EnvID: env.ID, SHA: "abc",
+				TriggeredBy: models.BuildTriggerManual,
+				Status:      models.BuildStatusRunning,
+			}
+			err := r.Build(context.Background(), env, build)
+			if (err != nil) != (c.exitErr != nil) {
+				t.Fatalf("Build err = %v, want the compose outcome regardless of the store", err)
+			}
+			if build.Status != c.wantBuild || env.Status != c.wantEnv {
+				t.Errorf("build/env = %v/%v, want %v/%v", build.Status, env.Status, c.wantBuild, c.wantEnv)
+			}
+			for _, msg := range []string{"save build record failed", "save environment failed"} {
+				entries := logs.FilterMessage(msg).All()
+				if len(entries) == 0 {
+					t.Errorf("no %q log", msg)
+					continue
+				}
+				last := entries[len(entries)-1].ContextMap()
+				if last["env_id"] != env.ID || last["error"] == nil {
+					t.Errorf("%q fields = %v, want env_id and error", msg, last)
+				}
+			}
+			if got := logs.FilterMessage("save build record failed").All(); len(got) > 0 {
+				if st := got[len(got)-1].ContextMap()["status"]; st != string(c.wantBuild) {
+					t.Errorf("last failed build save status = %v, want %v", st, c.wantBuild)
+				}
+			}
+		})
+	}
+}
+
 // fakePostgres / fakeRedis implement the runner's provisioner interfaces.
 type fakePostgres struct {
 	ensureCalls []string // env IDs ensured
@@ -670,9 +950,9 @@ hooks:
 	if err := r2.Build(context.Background(), env, build); err != nil {
 		t.Fatalf("Build: %v", err)
 	}
-	// 4 compose calls: build, hook 1, hook 2, up.
-	if exec.calls != 4 {
-		t.Errorf("exec.calls = %d, want 4 (build + 2 hooks + up)", exec.calls)
+	// 6 compose calls: config, build, hook 1, hook 2, pull, up.
+	if exec.calls != 6 {
+		t.Errorf("exec.calls = %d, want 6 (config + build + 2 hooks + pull + up)", exec.calls)
 	}
 }
 
@@ -730,9 +1010,9 @@ hooks:
 	}
 	_ = store.SaveEnvironment(env)
 
-	// Compose calls return: build OK, hook1 OK, hook2 FAIL, then ... but we expect no further calls.
+	// Compose calls return: config OK, build OK, hook1 OK, hook2 FAIL, then ... but we expect no further calls.
 	exec := &fakeOrderedExecutor{
-		exitErrs: []error{nil, nil, errors.New("migrate exited 1")},
+		exitErrs: []error{nil, nil, nil, errors.New("migrate exited 1")},
 	}
 	r := NewRunner(store, exec, dataDir, "", NewQueue(), zap.NewNop(), nil)
 
@@ -748,12 +1028,12 @@ hooks:
 		t.Fatal("expected build to fail when pre_deploy hook fails")
 	}
 
-	// Expect: build (1) + hook1 (1) + hook2 (1) = 3 calls. No third hook, no up, no post.
-	if len(exec.argsList) != 3 {
-		t.Fatalf("expected 3 compose calls (build + 2 hooks), got %d: %v", len(exec.argsList), exec.argsList)
+	// Expect: config (1) + build (1) + hook1 (1) + hook2 (1) = 4 calls. No third hook, no up, no post.
+	if len(exec.argsList) != 4 {
+		t.Fatalf("expected 4 compose calls (config + build + 2 hooks), got %d: %v", len(exec.argsList), exec.argsList)
 	}
 	// Last call should be the FAILED hook (containing "broken-2"), not 'up'.
-	last := exec.argsList[2]
+	last := exec.argsList[3]
 	if !strings.Contains(strings.Join(last, " "), "broken-2") {
 		t.Errorf("expected last call to be the failed hook, got %v", last)
 	}
@@ -799,9 +1079,9 @@ hooks:
 	if err := r2.Build(context.Background(), env, build); err != nil {
 		t.Fatalf("Build: %v", err)
 	}
-	// 3 compose calls: build, up, post-hook.
-	if exec.calls != 3 {
-		t.Errorf("exec.calls = %d, want 3 (build + up + 1 post-hook)", exec.calls)
+	// 5 compose calls: config, build, pull, up, post-hook.
+	if exec.calls != 5 {
+		t.Errorf("exec.calls = %d, want 5 (config + build + pull + up + 1 post-hook)", exec.calls)
 	}
 }
 
@@ -841,9 +1121,9 @@ hooks:
 	}
 	_ = store.SaveEnvironment(env)
 
-	// build OK, up OK, hook 1 fails, hook 2 fails — but build should still succeed.
+	// config, build, pull and up OK, hook 1 fails, hook 2 fails — but build should still succeed.
 	exec := &fakeOrderedExecutor{
-		exitErrs: []error{nil, nil, errors.New("queue restart failed"), errors.New("cache-clear failed")},
+		exitErrs: []error{nil, nil, nil, nil, errors.New("queue restart failed"), errors.New("cache-clear failed")},
 	}
 	r := NewRunner(store, exec, dataDir, "", NewQueue(), zap.NewNop(), nil)
 
@@ -858,9 +1138,9 @@ hooks:
 		t.Fatalf("Build should succeed despite post-hook failures, got %v", err)
 	}
 
-	// Expect 4 calls: build + up + 2 hooks (both ran despite first's failure).
-	if len(exec.argsList) != 4 {
-		t.Fatalf("expected 4 calls, got %d: %v", len(exec.argsList), exec.argsList)
+	// Expect 6 calls: config + build + pull + up + 2 hooks (both ran despite first's failure).
+	if len(exec.argsList) != 6 {
+		t.Fatalf("expected 6 calls, got %d: %v", len(exec.argsList), exec.argsList)
 	}
 
 	gotBuild, _ := store.GetBuild("p1", build.ID)
@@ -965,3 +1245,111 @@ domains:
 		t.Errorf("expected LE-unset warning in build log; got:\n%s", logBytes)
 	}
 }
+
+// blockingExecutor blocks every Compose call until ctx is cancelled, then
+// returns ctx.Err() — mimicking exec.CommandContext killing the process.
+// started is closed by the first call.
+type blockingExecutor struct {
+	started chan struct{}
+	once    sync.Once
+}
+
+func (b *blockingExecutor) Compose(ctx context.Context, _, _ string, _ []string, _, _ io.Writer) error {
+	b.once.Do(func() { close(b.started) })
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestRunner_Cancel(t *testing.T) {
+	r, store, _, env, _, _ := newRunnerTest(t)
+	exec := &blockingExecutor{started: make(chan struct{})}
+	r.exec = exec
+
+	build := &models.Build{ID: "b1", EnvID: env.ID, Status: models.BuildStatusRunning}
+	_ = store.SaveBuild("p1", build)
+
+	if r.Cancel("b1") {
+		t.Fatal("Cancel on an unknown build should return false")
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- r.Build(context.Background(), env, build) }()
+	<-exec.started
+	if !r.Cancel("b1") {
+		t.Fatal("Cancel on a running build should return true")
+	}
+	if err := <-done; err == nil {
+		t.Fatal("cancelled Build should return an error")
+	}
+
+	got, _ := store.GetBuild("p1", "b1")
+	if got.Status != models.BuildStatusCancelled {
+		t.Errorf("build status = %v, want cancelled", got.Status)
+	}
+	if r.Cancel("b1") {
+		t.Error("Cancel after the build finished should return false")
+	}
+}
+
+func TestRunner_CancelQueuedBuildKeepsEnvStatus(t *testing.T) {
+	r, store, _, env, _, exec := newRunnerTest(t)
+	env.Status = models.EnvStatusRunning
+	_ = store.SaveEnvironment(env)
+
+	// Another build of the same env holds the slot.
+	release, err := r.queue.Acquire(context.Background(), env.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	build := &models.Build{ID: "b1", EnvID: env.ID, Status: models.BuildStatusRunning}
+	_ = store.SaveBuild("p1", build)
+	done := make(chan error, 1)
+	go func() { done <- r.Build(context.Background(), env, build) }()
+	for !r.Cancel("b1") {
+		time.Sleep(time.Millisecond)
+	}
+	if err := <-done; err == nil {
+		t.Fatal("cancelled Build should return an error")
+	}
+
+	if got, _ := store.GetBuild("p1", "b1"); got.Status != models.BuildStatusCancelled {
+		t.Errorf("build status = %v, want cancelled", got.Status)
+	}
+	if got, _ := store.GetEnvironment(env.ProjectID, env.BranchSlug); got.Status != models.EnvStatusRunning {
+		t.Errorf("env status = %v, want it left at running", got.Status)
+	}
+	if exec.calls != 0 {
+		t.Errorf("a build cancelled in the queue ran compose %d time(s)", exec.calls)
+	}
+}
+
+func TestRunner_ProfilesPassedToCompose(t *testing.T) {
+	r, store, project, env, _, _ := newRunnerTest(t)
+	exec := &fakeOrderedExecutor{}
+	r.exec = exec
+	project.Profiles = []string{"debug", "metrics"}
+	if err := store.SaveProject(project); err != nil {
+		t.Fatal(err)
+	}
+
+	build := &models.Build{ID: "b1", EnvID: env.ID, Status: models.BuildStatusRunning}
+	_ = store.SaveBuild("p1", build)
+	if err := r.Build(context.Background(), env, build); err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if err := r.Teardown(context.Background(), env); err != nil {
+		t.Fatalf("Teardown: %v", err)
+	}
+
+	if len(exec.argsList) != 5 {
+		t.Fatalf("compose calls = %d, want 5 (config + build + pull + up + down): %v", len(exec.argsList), exec.argsList)
+	}
+	for _, args := range exec.argsList {
+		joined := strings.Join(args, " ")
+		if !strings.Contains(joined, "--profile debug --profile metrics") {
+			t.Errorf("call missing profiles: %v", args)
+		}
+	}
+}
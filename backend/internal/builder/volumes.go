@@ -0,0 +1,44 @@
+package builder
+
+import (
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// composeVolumeNames returns the docker names of the named volumes the
+// compose file at composePath declares under project: `name:` when set,
+// otherwise compose's default <project>_<key>. External volumes are
+// skipped — compose never creates or removes them. Best-effort: an
+// unreadable file yields nil.
+func composeVolumeNames(composePath, project string) []string {
+	data, err := os.ReadFile(composePath)
+	if err != nil {
+		return nil
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+	vols := labelsFindMapValue(doc.Content[0], "volumes")
+	if vols == nil || vols.Kind != yaml.MappingNode {
+		return nil
+	}
+	var names []string
+	for i := 0; i+1 < len(vols.Content); i += 2 {
+		key, spec := vols.Content[i].Value, vols.Content[i+1]
+		name := project + "_" + key
+		if spec != nil && spec.Kind == yaml.MappingNode {
+			if ext := labelsFindMapValue(spec, "external"); ext != nil && ext.Value != "false" {
+				continue
+			}
+			if n := labelsFindMapValue(spec, "name"); n != nil && n.Value != "" {
+				name = n.Value
+			}
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
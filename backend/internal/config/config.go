@@ -1,8 +1,13 @@
 package config
 
 import (
+	"net/netip"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
 )
 
 // Config holds the application configuration
@@ -15,6 +20,11 @@ type Config struct {
 	TraefikIP        string
 	ProxyNetwork     string
 	LetsencryptEmail string // empty = LE disabled, public domains fall back to HTTP
+	// TraefikWebEntrypoint / TraefikWebsecureEntrypoint name the host
+	// Traefik's HTTP and TLS entrypoints, for installs that run Traefik with
+	// custom entrypoints (e.g. on non-standard ports). Defaults: web, websecure.
+	TraefikWebEntrypoint       string
+	TraefikWebsecureEntrypoint string
 	// LabMode opens read-only endpoints (project list, env list, build logs,
 	// topology, etc.) without authentication — convenient for a homelab where
 	// every device on the LAN is trusted. Set LAB_MODE=false in any deployment
@@ -22,66 +32,296 @@ type Config struct {
 	// endpoint. Default: true (preserves existing homelab installs).
 	LabMode bool
 
+	// LogLevel (debug|info|warn|error) and LogFormat (json|console) shape
+	// the process logger. Defaults: info, json.
+	LogLevel  string
+	LogFormat string
+
+	// ContainerLog* set the logging driver + rotation applied to every
+	// deployed compose service that doesn't declare its own `logging:`.
+	// Defaults: json-file, 10m, 3 files.
+	ContainerLogDriver  string
+	ContainerLogMaxSize string
+	ContainerLogMaxFile string
+
+	// DefaultRestartPolicy / DefaultMemoryLimit fill `restart:` and
+	// `mem_limit:` on deployed compose services that don't set their own.
+	// Empty (default) leaves Docker's defaults: no restart, no limit.
+	DefaultRestartPolicy string
+	DefaultMemoryLimit   string
+	// DefaultInit (DEFAULT_INIT=true) runs an init process (tini) as PID 1
+	// in every deployed service that doesn't set `init:` itself, and in
+	// the postgres and redis singletons.
+	DefaultInit bool
+	// DefaultTZ (DEFAULT_TZ, e.g. "Europe/Amsterdam") is set as TZ in every
+	// deployed service that doesn't set TZ in its environment. Empty
+	// (default) leaves containers on their image's zone, usually UTC.
+	DefaultTZ string
+
+	// DockerOpTimeout bounds individual docker API calls (inspect, list,
+	// stop, ...); DockerPullTimeout bounds image pulls. 0 disables.
+	// Defaults: 30s, 15m.
+	DockerOpTimeout   time.Duration
+	DockerPullTimeout time.Duration
+	// DockerMaxConcurrentOps caps in-flight docker API calls (inspect,
+	// list, stop, ...); callers past the cap queue. 0 = unbounded.
+	// Default 8.
+	DockerMaxConcurrentOps int
+	// DockerMaxConcurrentPulls caps simultaneous image pulls across
+	// builds, the env pull endpoint and service-plane bootstrap; further
+	// pulls queue. 0 = unbounded. Default 2.
+	DockerMaxConcurrentPulls int
+	// DockerHost / DockerAPIVersion / DockerCertPath pick the daemon
+	// explicitly (DOCKER_HOST, DOCKER_API_VERSION, DOCKER_CERT_PATH) —
+	// readable from CONFIG_FILE too, unlike docker's own env handling.
+	// DockerCertPath holds ca.pem / cert.pem / key.pem for mutual TLS.
+	// Empty = the default socket.
+	DockerHost       string
+	DockerAPIVersion string
+	DockerCertPath   string
+	// DockerPlatform ("linux/arm64") forces the platform images are pulled
+	// and containers created for (DOCKER_DEFAULT_PLATFORM), for hosts
+	// where an image isn't multi-arch aware. Empty = the daemon's own.
+	DockerPlatform string
+
+	// GitCloneDepth limits new project clones to the latest N commits per
+	// branch (GIT_CLONE_DEPTH). 0 = full history. Default 0.
+	GitCloneDepth int
+	// GitFetchAttempts / GitFetchTimeout bound the retried `git fetch` that
+	// webhook deliveries and reconcile run before deploying: up to N tries
+	// with exponential backoff (GIT_FETCH_ATTEMPTS, default 3), all within
+	// GIT_FETCH_TIMEOUT (default 10s, inside a webhook's response window).
+	GitFetchAttempts int
+	GitFetchTimeout  time.Duration
+
+	// APIRateLimit is the per-client request rate (req/s) allowed on
+	// /api/v1, with bursts up to APIRateBurst; over-limit requests get 429.
+	// 0 disables. Defaults: 20 req/s, burst 40.
+	APIRateLimit float64
+	APIRateBurst int
+	// TrustedProxies (TRUSTED_PROXIES, comma-separated IPs or CIDRs) are
+	// the reverse proxies whose X-Forwarded-For / X-Real-IP the rate
+	// limiter believes. Everyone else is keyed on the connecting address,
+	// so a client can't dodge its limit by forging the header. Default
+	// none.
+	TrustedProxies []netip.Prefix
+	// WSMaxConnections caps concurrently open WebSocket log streams;
+	// further upgrades get 503. 0 disables. Default 64.
+	WSMaxConnections int
+
 	// LicenseEnforce turns on signed-license verification. The "sold product"
 	// build sets it via env. With it off (default), the server runs with no
 	// constraints — fine for the publisher's own homelab and for CI.
 	LicenseEnforce  bool
 	LicensePublicKey string // base64 Ed25519 public key — embedded by the publisher
 	LicenseFile      string // path to .lic file; default <DataDir>/license.lic
+
+	// BasePath serves the UI and API under a subpath (BASE_PATH, e.g.
+	// "/envmgr") for hosting behind a shared reverse proxy. Normalised to
+	// a leading slash and no trailing one. Empty (default) = domain root.
+	BasePath string
+
+	// StopOnShutdown (STOP_ON_SHUTDOWN=true) stops every running env's
+	// containers when the server gets SIGTERM/SIGINT and starts them again
+	// on the next boot — "done for the day" on a laptop. Off by default so
+	// a server restart never takes deployments down.
+	StopOnShutdown bool
+
+	// ResourceAlertInterval is how often running env containers are
+	// sampled for the resource alerts below (RESOURCE_ALERT_INTERVAL).
+	// 0 disables the monitor. Default 1m.
+	ResourceAlertInterval time.Duration
+	// ResourceAlertMemPercent / ResourceAlertCPUPercent are the shares of
+	// a container's memory / CPU limit that count as near the limit
+	// (RESOURCE_ALERT_MEMORY_PERCENT, default 90; RESOURCE_ALERT_CPU_PERCENT,
+	// default 0 = off). ResourceAlertMemBytes is the absolute threshold for
+	// containers with no memory limit (RESOURCE_ALERT_MEMORY_ABSOLUTE,
+	// "2g"; default 0 = skip them). An alert fires once usage has stayed
+	// over for ResourceAlertFor (RESOURCE_ALERT_FOR, default 2m).
+	ResourceAlertMemPercent float64
+	ResourceAlertCPUPercent float64
+	ResourceAlertMemBytes   int64
+	ResourceAlertFor        time.Duration
+
+	// ConfigFile is the CONFIG_FILE path the values were (partly) read
+	// from. Empty = environment only.
+	ConfigFile string
 }
 
-// Load loads configuration from environment variables
+// Load loads configuration from environment variables, falling back to
+// the YAML file named by CONFIG_FILE (when set) for anything the
+// environment leaves unset. See readFile for the file format.
 func Load() (*Config, error) {
+	configFile := os.Getenv("CONFIG_FILE")
+	src := source{}
+	if configFile != "" {
+		file, err := readFile(configFile)
+		if err != nil {
+			return nil, err
+		}
+		src = file
+	}
+
 	port := 8080
-	if p := os.Getenv("PORT"); p != "" {
+	if p := src.get("PORT"); p != "" {
 		if parsed, err := strconv.Atoi(p); err == nil {
 			port = parsed
 		}
 	}
 
-	dataDir := os.Getenv("DATA_DIR")
+	dataDir := src.get("DATA_DIR")
 	if dataDir == "" {
 		dataDir = "./data"
 	}
 
-	staticDir := os.Getenv("STATIC_DIR")
+	staticDir := src.get("STATIC_DIR")
 	if staticDir == "" {
 		staticDir = "./static"
 	}
 
-	gitRemote := os.Getenv("GIT_REMOTE")
-	baseDomain := os.Getenv("BASE_DOMAIN")
+	gitRemote := src.get("GIT_REMOTE")
+	baseDomain := src.get("BASE_DOMAIN")
 	if baseDomain == "" {
 		baseDomain = "localhost"
 	}
 
-	traefikIP := os.Getenv("TRAEFIK_IP")
+	traefikIP := src.get("TRAEFIK_IP")
 	if traefikIP == "" {
 		traefikIP = "127.0.0.1"
 	}
 
-	proxyNetwork := os.Getenv("PROXY_NETWORK")
+	proxyNetwork := src.get("PROXY_NETWORK")
 	if proxyNetwork == "" {
 		proxyNetwork = "env-manager-net"
 	}
 
-	letsencryptEmail := os.Getenv("LETSENCRYPT_EMAIL")
+	letsencryptEmail := src.get("LETSENCRYPT_EMAIL")
+	traefikWebEntrypoint := src.or("TRAEFIK_WEB_ENTRYPOINT", "web")
+	traefikWebsecureEntrypoint := src.or("TRAEFIK_WEBSECURE_ENTRYPOINT", "websecure")
+
+	containerLogDriver := src.or("CONTAINER_LOG_DRIVER", "json-file")
+	containerLogMaxSize := src.or("CONTAINER_LOG_MAX_SIZE", "10m")
+	containerLogMaxFile := src.or("CONTAINER_LOG_MAX_FILE", "3")
+
+	defaultRestartPolicy := src.get("DEFAULT_RESTART_POLICY")
+	defaultMemoryLimit := src.get("DEFAULT_MEMORY_LIMIT")
+	defaultInit := false
+	if v := src.get("DEFAULT_INIT"); v != "" {
+		if parsed, err := strconv.ParseBool(v); err == nil {
+			defaultInit = parsed
+		}
+	}
+
+	defaultTZ := src.get("DEFAULT_TZ")
+
+	dockerOpTimeout := src.duration("DOCKER_OP_TIMEOUT", 30*time.Second)
+	dockerPullTimeout := src.duration("DOCKER_PULL_TIMEOUT", 15*time.Minute)
+	dockerMaxOps := 8
+	if v := src.get("DOCKER_MAX_CONCURRENT_OPS"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil {
+			dockerMaxOps = parsed
+		}
+	}
+
+	dockerMaxPulls := 2
+	if v := src.get("DOCKER_MAX_CONCURRENT_PULLS"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil {
+			dockerMaxPulls = parsed
+		}
+	}
+
+	dockerHost := src.get("DOCKER_HOST")
+	dockerAPIVersion := src.get("DOCKER_API_VERSION")
+	dockerCertPath := src.get("DOCKER_CERT_PATH")
+	dockerPlatform := src.get("DOCKER_DEFAULT_PLATFORM")
+
+	gitCloneDepth := 0
+	if v := src.get("GIT_CLONE_DEPTH"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed >= 0 {
+			gitCloneDepth = parsed
+		}
+	}
+
+	gitFetchAttempts := 3
+	if v := src.get("GIT_FETCH_ATTEMPTS"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil && parsed >= 1 {
+			gitFetchAttempts = parsed
+		}
+	}
+	gitFetchTimeout := src.duration("GIT_FETCH_TIMEOUT", 10*time.Second)
+
+	apiRateLimit := 20.0
+	if v := src.get("API_RATE_LIMIT"); v != "" {
+		if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+			apiRateLimit = parsed
+		}
+	}
+	apiRateBurst := 40
+	if v := src.get("API_RATE_BURST"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil {
+			apiRateBurst = parsed
+		}
+	}
+
+	trustedProxies := parseTrustedProxies(src.get("TRUSTED_PROXIES"))
+
+	wsMaxConns := 64
+	if v := src.get("WS_MAX_CONNECTIONS"); v != "" {
+		if parsed, err := strconv.Atoi(v); err == nil {
+			wsMaxConns = parsed
+		}
+	}
+
+	basePath := strings.TrimRight(src.get("BASE_PATH"), "/")
+	if basePath != "" && !strings.HasPrefix(basePath, "/") {
+		basePath = "/" + basePath
+	}
+
+	logLevel := src.or("LOG_LEVEL", "info")
+	logFormat := src.or("LOG_FORMAT", "json")
 
 	labMode := true
-	if v := os.Getenv("LAB_MODE"); v != "" {
+	if v := src.get("LAB_MODE"); v != "" {
 		if parsed, err := strconv.ParseBool(v); err == nil {
 			labMode = parsed
 		}
 	}
 
 	licenseEnforce := false
-	if v := os.Getenv("LICENSE_ENFORCE"); v != "" {
+	if v := src.get("LICENSE_ENFORCE"); v != "" {
 		if parsed, err := strconv.ParseBool(v); err == nil {
 			licenseEnforce = parsed
 		}
 	}
-	licensePublicKey := os.Getenv("LICENSE_PUBLIC_KEY")
-	licenseFile := os.Getenv("LICENSE_FILE")
+	stopOnShutdown := false
+	if v := src.get("STOP_ON_SHUTDOWN"); v != "" {
+		if parsed, err := strconv.ParseBool(v); err == nil {
+			stopOnShutdown = parsed
+		}
+	}
+	alertInterval := src.duration("RESOURCE_ALERT_INTERVAL", time.Minute)
+	alertFor := src.duration("RESOURCE_ALERT_FOR", 2*time.Minute)
+	alertMemPercent := 90.0
+	if v := src.get("RESOURCE_ALERT_MEMORY_PERCENT"); v != "" {
+		if parsed, err := strconv.ParseFloat(v, 64); err == nil && parsed >= 0 {
+			alertMemPercent = parsed
+		}
+	}
+	alertCPUPercent := 0.0
+	if v := src.get("RESOURCE_ALERT_CPU_PERCENT"); v != "" {
+		if parsed, err := strconv.ParseFloat(v, 64); err == nil && parsed >= 0 {
+			alertCPUPercent = parsed
+		}
+	}
+	var alertMemBytes int64
+	if v := src.get("RESOURCE_ALERT_MEMORY_ABSOLUTE"); v != "" {
+		if parsed, err := units.RAMInBytes(v); err == nil && parsed >= 0 {
+			alertMemBytes = parsed
+		}
+	}
+
+	licensePublicKey := src.get("LICENSE_PUBLIC_KEY")
+	licenseFile := src.get("LICENSE_FILE")
 	if licenseFile == "" {
 		licenseFile = dataDir + "/license.lic"
 	}
@@ -95,9 +335,90 @@ func Load() (*Config, error) {
 		TraefikIP:        traefikIP,
 		ProxyNetwork:     proxyNetwork,
 		LetsencryptEmail: letsencryptEmail,
+		TraefikWebEntrypoint:       traefikWebEntrypoint,
+		TraefikWebsecureEntrypoint: traefikWebsecureEntrypoint,
 		LabMode:          labMode,
+		LogLevel:         logLevel,
+		LogFormat:        logFormat,
+		ContainerLogDriver:  containerLogDriver,
+		ContainerLogMaxSize: containerLogMaxSize,
+		ContainerLogMaxFile: containerLogMaxFile,
+		DefaultRestartPolicy: defaultRestartPolicy,
+		DefaultMemoryLimit:   defaultMemoryLimit,
+		DefaultInit:          defaultInit,
+		DefaultTZ:            defaultTZ,
+		DockerOpTimeout:   dockerOpTimeout,
+		DockerPullTimeout: dockerPullTimeout,
+		DockerMaxConcurrentOps: dockerMaxOps,
+		DockerMaxConcurrentPulls: dockerMaxPulls,
+		DockerHost:             dockerHost,
+		DockerAPIVersion:       dockerAPIVersion,
+		DockerCertPath:         dockerCertPath,
+		DockerPlatform:         dockerPlatform,
+		GitCloneDepth:          gitCloneDepth,
+		GitFetchAttempts:       gitFetchAttempts,
+		GitFetchTimeout:        gitFetchTimeout,
+		APIRateLimit:           apiRateLimit,
+		APIRateBurst:           apiRateBurst,
+		TrustedProxies:         trustedProxies,
+		WSMaxConnections:       wsMaxConns,
 		LicenseEnforce:   licenseEnforce,
 		LicensePublicKey: licensePublicKey,
 		LicenseFile:      licenseFile,
+		BasePath:         basePath,
+		StopOnShutdown:   stopOnShutdown,
+		ResourceAlertInterval:   alertInterval,
+		ResourceAlertMemPercent: alertMemPercent,
+		ResourceAlertCPUPercent: alertCPUPercent,
+		ResourceAlertMemBytes:   alertMemBytes,
+		ResourceAlertFor:        alertFor,
+		ConfigFile:       configFile,
 	}, nil
 }
+
+// source resolves config keys: the process environment wins, then the
+// config file's values (keyed by the same names as the env vars).
+type source map[string]string
+
+// parseTrustedProxies parses a comma-separated list of IPs and CIDRs.
+// A bare IP trusts that one address. Unparseable entries are skipped.
+func parseTrustedProxies(v string) []netip.Prefix {
+	var out []netip.Prefix
+	for _, f := range strings.Split(v, ",") {
+		f = strings.TrimSpace(f)
+		if p, err := netip.ParsePrefix(f); err == nil {
+			out = append(out, p.Masked())
+		} else if a, err := netip.ParseAddr(f); err == nil {
+			out = append(out, netip.PrefixFrom(a, a.BitLen()))
+		}
+	}
+	return out
+}
+
+// get returns the env var key, or the file's value for it when the env var
+// is unset/empty.
+func (s source) get(key string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return s[key]
+}
+
+// or returns get(key), or fallback when both are unset/empty.
+func (s source) or(key, fallback string) string {
+	if v := s.get(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// duration parses get(key) as a time.Duration ("30s", "2m"), returning
+// fallback when unset or unparseable.
+func (s source) duration(key string, fallback time.Duration) time.Duration {
+	if v := s.get(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad_ConfigFile(t *testing.T) {
+	path := writeConfigFile(t, "base_domain: home.example.com\nPORT: 9090\nlab_mode: false\ndocker_op_timeout: 45s\ndocker_host: tcp://10.0.0.5:2376\n")
+	t.Setenv("CONFIG_FILE", path)
+	// Env beats file.
+	t.Setenv("BASE_DOMAIN", "env.example.com")
+	t.Setenv("DOCKER_HOST", "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.BaseDomain != "env.example.com" {
+		t.Errorf("BaseDomain = %q, env var should win", cfg.BaseDomain)
+	}
+	if cfg.Port != 9090 {
+		t.Errorf("Port = %d, want 9090 from file", cfg.Port)
+	}
+	if cfg.LabMode {
+		t.Error("LabMode should be false from file")
+	}
+	if cfg.DockerOpTimeout != 45*time.Second {
+		t.Errorf("DockerOpTimeout = %v, want 45s", cfg.DockerOpTimeout)
+	}
+	if cfg.DockerHost != "tcp://10.0.0.5:2376" {
+		t.Errorf("DockerHost = %q, want value from file", cfg.DockerHost)
+	}
+	if cfg.ConfigFile != path {
+		t.Errorf("ConfigFile = %q", cfg.ConfigFile)
+	}
+}
+
+func TestLoad_ConfigFileErrors(t *testing.T) {
+	for name, content := range map[string]string{
+		"nested":         "docker:\n  timeout: 1s\n",
+		"credential key": "credential_key: abc\n",
+		"not a mapping":  "- a\n- b\n",
+	} {
+		t.Setenv("CONFIG_FILE", writeConfigFile(t, content))
+		if _, err := Load(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	t.Setenv("CONFIG_FILE", filepath.Join(t.TempDir(), "missing.yaml"))
+	if _, err := Load(); err == nil {
+		t.Error("missing file: expected error")
+	}
+}
+
+func TestLoad_NoConfigFile(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ConfigFile != "" {
+		t.Errorf("ConfigFile = %q, want empty", cfg.ConfigFile)
+	}
+}
+
+func TestLoad_BasePathNormalised(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	for in, want := range map[string]string{"": "", "/": "", "envmgr": "/envmgr", "/envmgr/": "/envmgr", "/a/b": "/a/b"} {
+		t.Setenv("BASE_PATH", in)
+		cfg, err := Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.BasePath != want {
+			t.Errorf("BASE_PATH=%q: BasePath = %q, want %q", in, cfg.BasePath, want)
+		}
+	}
+}
+
+func TestLoad_TrustedProxies(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("TRUSTED_PROXIES", "172.18.0.0/16, 10.0.0.5,not-an-ip, ::1")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, p := range cfg.TrustedProxies {
+		got = append(got, p.String())
+	}
+	if want := "172.18.0.0/16 10.0.0.5/32 ::1/128"; strings.Join(got, " ") != want {
+		t.Errorf("TrustedProxies = %v, want %s", got, want)
+	}
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// readFile loads a config file: a flat YAML mapping whose keys are the
+// same names as the environment variables (case-insensitive), e.g.
+//
+//	base_domain: home.example.com
+//	lab_mode: false
+//	docker_op_timeout: 45s
+//
+// Values must be scalars. Environment variables always take precedence
+// over the file. CREDENTIAL_KEY is deliberately not read from here — the
+// key that decrypts the credential store should not sit in a config file
+// next to it.
+func readFile(path string) (source, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+	var raw map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	out := make(source, len(raw))
+	for k, n := range raw {
+		if n.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("config file %s: %s must be a scalar value", path, k)
+		}
+		key := strings.ToUpper(k)
+		if key == "CREDENTIAL_KEY" {
+			return nil, fmt.Errorf("config file %s: CREDENTIAL_KEY must be set in the environment, not the config file", path)
+		}
+		out[key] = n.Value
+	}
+	return out, nil
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"reflect"
+	"time"
+)
+
+// Reloadable lists the Config fields a running server applies when the
+// config file changes. Every other field only takes effect on restart.
+var Reloadable = map[string]bool{
+	"LogLevel":                   true,
+	"LetsencryptEmail":           true,
+	"TraefikWebEntrypoint":       true,
+	"TraefikWebsecureEntrypoint": true,
+	"ContainerLogDriver":         true,
+	"ContainerLogMaxSize":        true,
+	"ContainerLogMaxFile":        true,
+	"DefaultRestartPolicy":       true,
+	"DefaultMemoryLimit":         true,
+	"DefaultInit":                true,
+	"DefaultTZ":                  true,
+}
+
+// Watch polls the config file at path every interval. When its content
+// changes it re-runs Load (so env vars still override the file) and hands
+// the result to onChange; a file that no longer loads is reported through
+// onError and the previous config stays in force. Polling rather than
+// inotify keeps this working on bind mounts and network filesystems where
+// change events are unreliable. Returns when ctx is done.
+func Watch(ctx context.Context, path string, interval time.Duration, onChange func(*Config), onError func(error)) {
+	last, _ := os.ReadFile(path)
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			onError(err)
+			continue
+		}
+		if bytes.Equal(data, last) {
+			continue
+		}
+		last = data
+		cfg, err := Load()
+		if err != nil {
+			onError(err)
+			continue
+		}
+		onChange(cfg)
+	}
+}
+
+// Adopt returns a copy of cur with the Reloadable fields taken from next.
+// The rest keep cur's values, so a running server never sees a field
+// change that only a restart would apply.
+func Adopt(cur, next *Config) *Config {
+	merged := *cur
+	dst, src := reflect.ValueOf(&merged).Elem(), reflect.ValueOf(next).Elem()
+	for name := range Reloadable {
+		dst.FieldByName(name).Set(src.FieldByName(name))
+	}
+	return &merged
+}
+
+// Changed returns the names of the Config fields whose values differ
+// between a and b, in declaration order.
+func Changed(a, b *Config) []string {
+	va, vb := reflect.ValueOf(*a), reflect.ValueOf(*b)
+	var out []string
+	for i := 0; i < va.NumField(); i++ {
+		if !reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			out = append(out, va.Type().Field(i).Name)
+		}
+	}
+	return out
+}
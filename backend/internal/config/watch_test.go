@@ -0,0 +1,63 @@
+package config
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestChanged(t *testing.T) {
+	a := &Config{Port: 80, LogLevel: "info"}
+	b := &Config{Port: 80, LogLevel: "debug", DataDir: "/d"}
+	got := Changed(a, b)
+	if len(got) != 2 || got[0] != "DataDir" || got[1] != "LogLevel" {
+		t.Errorf("Changed = %v, want [DataDir LogLevel]", got)
+	}
+}
+
+func TestAdopt(t *testing.T) {
+	// Every name in the table must be a Config field; Adopt panics on a typo.
+	for name := range Reloadable {
+		if _, ok := reflect.TypeOf(Config{}).FieldByName(name); !ok {
+			t.Fatalf("Reloadable[%q] is not a Config field", name)
+		}
+	}
+
+	cur := &Config{Port: 80, LogLevel: "info", LetsencryptEmail: "old@example.com"}
+	next := &Config{Port: 8080, LogLevel: "debug", LetsencryptEmail: "new@example.com", DefaultInit: true}
+	got := Adopt(cur, next)
+	if got.LogLevel != "debug" || got.LetsencryptEmail != "new@example.com" || !got.DefaultInit {
+		t.Errorf("reloadable fields not adopted: %+v", got)
+	}
+	if got.Port != 80 {
+		t.Errorf("Port = %d, want the boot value 80", got.Port)
+	}
+	if cur.LogLevel != "info" {
+		t.Error("Adopt modified cur")
+	}
+}
+
+func TestWatch_ReloadsOnChange(t *testing.T) {
+	path := writeConfigFile(t, "log_level: info\n")
+	t.Setenv("CONFIG_FILE", path)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	got := make(chan *Config, 1)
+	go Watch(ctx, path, 10*time.Millisecond, func(c *Config) { got <- c }, func(error) {})
+
+	time.Sleep(30 * time.Millisecond)
+	if err := os.WriteFile(path, []byte("log_level: debug\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case c := <-got:
+		if c.LogLevel != "debug" {
+			t.Errorf("LogLevel = %q, want debug", c.LogLevel)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no reload observed")
+	}
+}
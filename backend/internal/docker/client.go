@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/docker/docker/api/types"
@@ -16,28 +17,25 @@ import (
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/pkg/stdcopy"
+
+	"github.com/environment-manager/backend/internal/models"
 )
 
 // Client wraps the Docker client
 type Client struct {
-	cli *client.Client
-	ctx context.Context
+	cli         *client.Client
+	ctx         context.Context
+	opTimeout   time.Duration // see SetTimeouts
+	pullTimeout time.Duration
+	opSem       chan struct{} // see SetMaxConcurrentOps; nil = unbounded
+	pullSem     chan struct{} // see SetMaxConcurrentPulls; nil = unbounded
+	platform    string        // see Options.Platform; "" = the daemon's
 }
 
-// NewClient creates a new Docker client
+// NewClient creates a new Docker client from the environment (DOCKER_HOST
+// etc.). See NewClientWithOptions to pick the daemon explicitly.
 func NewClient() (*Client, error) {
-	cli, err := client.NewClientWithOpts(
-		client.FromEnv,
-		client.WithAPIVersionNegotiation(),
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	return &Client{
-		cli: cli,
-		ctx: context.Background(),
-	}, nil
+	return NewClientWithOptions(Options{})
 }
 
 // Close closes the Docker client
@@ -65,7 +63,11 @@ func (c *Client) GetContainer(id string) (types.ContainerJSON, error) {
 // Use this when you already have docker SDK types. High-level container creation
 // using models.ContainerConfig has been removed (env-manager v2).
 func (c *Client) CreateContainerRaw(name string, cfg *container.Config, hostCfg *container.HostConfig, netCfg *network.NetworkingConfig) (string, error) {
-	resp, err := c.cli.ContainerCreate(c.ctx, cfg, hostCfg, netCfg, nil, name)
+	platform, err := ParsePlatform(c.platform)
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.cli.ContainerCreate(c.ctx, cfg, hostCfg, netCfg, platform, name)
 	if err != nil {
 		return "", err
 	}
@@ -83,7 +85,28 @@ func (c *Client) StopContainer(id string, timeout *int) error {
 	if timeout != nil {
 		timeoutPtr = timeout
 	}
-	return c.cli.ContainerStop(c.ctx, id, container.StopOptions{Timeout: timeoutPtr})
+	ctx, cancel := withTimeout(c.ctx, c.stopTimeout(timeoutPtr))
+	defer cancel()
+	release, err := c.acquireOp(ctx)
+	if err != nil {
+		return wrapTimeout("stop "+id, err)
+	}
+	defer release()
+	return wrapTimeout("stop "+id, c.cli.ContainerStop(ctx, id, container.StopOptions{Timeout: timeoutPtr}))
+}
+
+// stopTimeout bounds a stop/restart call: the container's own grace period
+// (docker's default is 10s) plus the regular op timeout for the API round
+// trip, so a long grace period isn't cut short.
+func (c *Client) stopTimeout(grace *int) time.Duration {
+	if c.opTimeout <= 0 {
+		return 0
+	}
+	g := 10 * time.Second
+	if grace != nil && *grace > 0 {
+		g = time.Duration(*grace) * time.Second
+	}
+	return g + c.opTimeout
 }
 
 // RestartContainer restarts a container
@@ -92,15 +115,29 @@ func (c *Client) RestartContainer(id string, timeout *int) error {
 	if timeout != nil {
 		timeoutPtr = timeout
 	}
-	return c.cli.ContainerRestart(c.ctx, id, container.StopOptions{Timeout: timeoutPtr})
+	ctx, cancel := withTimeout(c.ctx, c.stopTimeout(timeoutPtr))
+	defer cancel()
+	release, err := c.acquireOp(ctx)
+	if err != nil {
+		return wrapTimeout("restart "+id, err)
+	}
+	defer release()
+	return wrapTimeout("restart "+id, c.cli.ContainerRestart(ctx, id, container.StopOptions{Timeout: timeoutPtr}))
 }
 
 // RemoveContainer removes a container
 func (c *Client) RemoveContainer(id string, force bool) error {
-	return c.cli.ContainerRemove(c.ctx, id, container.RemoveOptions{
+	ctx, cancel := withTimeout(c.ctx, c.opTimeout)
+	defer cancel()
+	release, err := c.acquireOp(ctx)
+	if err != nil {
+		return wrapTimeout("remove "+id, err)
+	}
+	defer release()
+	return wrapTimeout("remove "+id, c.cli.ContainerRemove(ctx, id, container.RemoveOptions{
 		Force:         force,
 		RemoveVolumes: false,
-	})
+	}))
 }
 
 // GetContainerLogs returns container logs as a reader
@@ -113,11 +150,30 @@ func (c *Client) GetContainerLogs(id string, follow bool, tail string, since tim
 		Tail:       tail,
 	}
 	if !since.IsZero() {
-		options.Since = since.Format(time.RFC3339)
+		options.Since = since.Format(time.RFC3339Nano)
 	}
 	return c.cli.ContainerLogs(c.ctx, id, options)
 }
 
+// ContainerLogsRange returns a container's logs (not followed) bounded by
+// since/until (zero = unbounded) and tail ("" or "all" = every line). The
+// stream is docker's multiplexed format; demux with stdcopy.
+func (c *Client) ContainerLogsRange(ctx context.Context, id, tail string, since, until time.Time) (io.ReadCloser, error) {
+	options := container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Timestamps: true,
+		Tail:       tail,
+	}
+	if !since.IsZero() {
+		options.Since = since.Format(time.RFC3339Nano)
+	}
+	if !until.IsZero() {
+		options.Until = until.Format(time.RFC3339Nano)
+	}
+	return c.cli.ContainerLogs(ctx, id, options)
+}
+
 
 // ListVolumes returns all volumes
 func (c *Client) ListVolumes() ([]*volume.Volume, error) {
@@ -153,46 +209,24 @@ func (c *Client) GetVolume(name string) (volume.Volume, error) {
 	return c.cli.VolumeInspect(c.ctx, name)
 }
 
-// EnsureNetwork creates the network if it doesn't exist
-func (c *Client) EnsureNetwork(name, subnet string) error {
-	// Check if network exists
-	networks, err := c.cli.NetworkList(c.ctx, types.NetworkListOptions{
-		Filters: filters.NewArgs(filters.Arg("name", name)),
-	})
-	if err != nil {
-		return err
-	}
-
-	if len(networks) > 0 {
-		return nil // Network already exists
-	}
-
-	// Create network
-	_, err = c.cli.NetworkCreate(c.ctx, name, types.NetworkCreate{
-		Driver: "bridge",
-		IPAM: &network.IPAM{
-			Config: []network.IPAMConfig{
-				{Subnet: subnet},
-			},
-		},
-		Labels: map[string]string{
-			"env-manager.managed": "true",
-		},
-	})
-	return err
-}
-
 // PullImage pulls a Docker image
 func (c *Client) PullImage(image string) error {
-	reader, err := c.cli.ImagePull(c.ctx, image, types.ImagePullOptions{})
+	ctx, cancel := withTimeout(c.ctx, c.pullTimeout)
+	defer cancel()
+	release, err := c.AcquirePull(ctx)
 	if err != nil {
-		return err
+		return wrapTimeout("pull "+image, err)
+	}
+	defer release()
+	reader, err := c.cli.ImagePull(ctx, image, types.ImagePullOptions{Platform: c.platform})
+	if err != nil {
+		return wrapTimeout("pull "+image, err)
 	}
 	defer reader.Close()
 
 	// Consume the reader to complete the pull
 	_, err = io.Copy(io.Discard, reader)
-	return err
+	return wrapTimeout("pull "+image, err)
 }
 
 
@@ -255,7 +289,8 @@ func (c *Client) InspectExec(execID string) (types.ContainerExecInspect, error)
 
 // RunSpec describes a service-plane container to launch. Used by RunContainer.
 // Volumes maps a named volume (auto-created if missing) to a container path.
-// Env, Cmd, and Labels are optional.
+// Env, Cmd, and Labels are optional. Init runs Docker's init (tini) as
+// PID 1 to reap zombies and forward signals.
 type RunSpec struct {
 	Name    string
 	Image   string
@@ -264,18 +299,29 @@ type RunSpec struct {
 	Env     map[string]string
 	Cmd     []string
 	Labels  map[string]string
+	Init    bool
+	// Platform ("linux/arm64") overrides the client's default platform
+	// for this container's pull and create. Empty = the client default.
+	Platform string
 }
 
 // ContainerStatus reports whether a container with the given name exists and
 // whether it's running. Both false (with nil error) means the container is
 // absent. Used by service-plane bootstrap for idempotency.
 func (c *Client) ContainerStatus(ctx context.Context, name string) (exists, running bool, err error) {
+	ctx, cancel := withTimeout(ctx, c.opTimeout)
+	defer cancel()
+	release, err := c.acquireOp(ctx)
+	if err != nil {
+		return false, false, wrapTimeout("list "+name, err)
+	}
+	defer release()
 	list, err := c.cli.ContainerList(ctx, container.ListOptions{
 		All:     true,
 		Filters: filters.NewArgs(filters.Arg("name", "^/"+name+"$")),
 	})
 	if err != nil {
-		return false, false, err
+		return false, false, wrapTimeout("list "+name, err)
 	}
 	if len(list) == 0 {
 		return false, false, nil
@@ -283,21 +329,187 @@ func (c *Client) ContainerStatus(ctx context.Context, name string) (exists, runn
 	return true, list[0].State == "running", nil
 }
 
-// RunContainer pulls the image (idempotent), creates the container, attaches
+// ContainerID resolves a container name to its current ID and whether it's
+// running. Returns "" (and no error) when no container has that name. A
+// changed ID for the same name means the container was recreated.
+func (c *Client) ContainerID(ctx context.Context, name string) (id string, running bool, err error) {
+	ctx, cancel := withTimeout(ctx, c.opTimeout)
+	defer cancel()
+	release, err := c.acquireOp(ctx)
+	if err != nil {
+		return "", false, wrapTimeout("list "+name, err)
+	}
+	defer release()
+	list, err := c.cli.ContainerList(ctx, container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("name", "^/"+name+"$")),
+	})
+	if err != nil {
+		return "", false, wrapTimeout("list "+name, err)
+	}
+	if len(list) == 0 {
+		return "", false, nil
+	}
+	return list[0].ID, list[0].State == "running", nil
+}
+
+// ContainerState inspects the named container and returns its runtime state,
+// including the last exit code and whether the kernel OOM-killed it. Returns
+// an errdefs.NotFound error when the container doesn't exist.
+func (c *Client) ContainerState(ctx context.Context, name string) (models.ContainerState, error) {
+	ctx, cancel := withTimeout(ctx, c.opTimeout)
+	defer cancel()
+	release, err := c.acquireOp(ctx)
+	if err != nil {
+		return models.ContainerState{}, wrapTimeout("inspect "+name, err)
+	}
+	defer release()
+	info, err := c.cli.ContainerInspect(ctx, name)
+	if err != nil {
+		return models.ContainerState{}, wrapTimeout("inspect "+name, err)
+	}
+	if info.State == nil {
+		return models.ContainerState{}, fmt.Errorf("inspect %s: no state reported", name)
+	}
+	st := models.ContainerState{
+		Status:    info.State.Status,
+		Running:   info.State.Running,
+		ExitCode:  info.State.ExitCode,
+		OOMKilled: info.State.OOMKilled,
+		ImageID:   info.Image,
+	}
+	// The digest lives on the image, not the container. Best-effort: a
+	// removed or locally built image simply leaves it empty.
+	if info.Image != "" && info.Config != nil {
+		if img, _, ierr := c.cli.ImageInspectWithRaw(ctx, info.Image); ierr == nil {
+			st.ImageDigest = pickRepoDigest(info.Config.Image, img.RepoDigests)
+		}
+	}
+	return st, nil
+}
+
+// pickRepoDigest returns the entry of digests ("repo@sha256:...") whose
+// repo matches the image reference ref ("repo:tag"), falling back to the
+// first entry. An image pulled under several names carries one digest per
+// repo; the container's own reference picks the meaningful one.
+func pickRepoDigest(ref string, digests []string) string {
+	if len(digests) == 0 {
+		return ""
+	}
+	repo := ref
+	if i := strings.Index(repo, "@"); i >= 0 {
+		repo = repo[:i]
+	}
+	if i := strings.LastIndex(repo, ":"); i > strings.LastIndex(repo, "/") {
+		repo = repo[:i]
+	}
+	for _, d := range digests {
+		if strings.HasPrefix(d, repo+"@") {
+			return d
+		}
+	}
+	return digests[0]
+}
+
+// ListComposeContainers returns every container (running or not) labelled
+// with com.docker.compose.project=<project>. Env-manager deploys each env as
+// compose project <env_id>, so this is the env's full container set.
+func (c *Client) ListComposeContainers(ctx context.Context, project string) ([]models.ComposeContainer, error) {
+	ctx, cancel := withTimeout(ctx, c.opTimeout)
+	defer cancel()
+	release, err := c.acquireOp(ctx)
+	if err != nil {
+		return nil, wrapTimeout("list compose project "+project, err)
+	}
+	defer release()
+	list, err := c.cli.ContainerList(ctx, container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", "com.docker.compose.project="+project)),
+	})
+	if err != nil {
+		return nil, wrapTimeout("list compose project "+project, err)
+	}
+	out := make([]models.ComposeContainer, 0, len(list))
+	for _, ct := range list {
+		name := ""
+		if len(ct.Names) > 0 {
+			name = strings.TrimPrefix(ct.Names[0], "/")
+		}
+		cc := models.ComposeContainer{
+			ID:      ct.ID,
+			Name:    name,
+			Service: ct.Labels["com.docker.compose.service"],
+			Image:   ct.Image,
+			State:   ct.State,
+			Status:  ct.Status,
+		}
+		// The list only has the exit code inside the human-readable
+		// Status; inspect stopped containers for the real one.
+		if ct.State == "exited" || ct.State == "dead" {
+			inspect, err := c.cli.ContainerInspect(ctx, ct.ID)
+			if err != nil && !errdefs.IsNotFound(err) {
+				return nil, wrapTimeout("inspect "+name, err)
+			}
+			if err == nil && inspect.State != nil {
+				cc.ExitCode = inspect.State.ExitCode
+				cc.OOMKilled = inspect.State.OOMKilled
+			}
+		}
+		out = append(out, cc)
+	}
+	return out, nil
+}
+
+// ensureImage pulls image unless the daemon already has it — the same
+// "pull missing" rule as `docker run`, so air-gapped hosts work with a
+// preloaded image and no registry round trip. A non-empty platform pulls
+// that variant of a multi-arch image, and also re-pulls when the local
+// copy was built for a different os/arch.
+func (c *Client) ensureImage(ctx context.Context, image, platform string) error {
+	want, err := ParsePlatform(platform)
+	if err != nil {
+		return err
+	}
+	if inspect, _, err := c.cli.ImageInspectWithRaw(ctx, image); err == nil && platformMatches(want, inspect) {
+		return nil
+	}
+	pullCtx, pullCancel := withTimeout(ctx, c.pullTimeout)
+	defer pullCancel()
+	release, err := c.AcquirePull(pullCtx)
+	if err != nil {
+		return wrapTimeout("pull "+image, err)
+	}
+	defer release()
+	pullReader, err := c.cli.ImagePull(pullCtx, image, types.ImagePullOptions{Platform: platform})
+	if err != nil {
+		if timedOut(err) {
+			return wrapTimeout("pull "+image, err)
+		}
+		return fmt.Errorf("pull %s: %w", image, err)
+	}
+	defer pullReader.Close()
+	if _, err := io.Copy(io.Discard, pullReader); err != nil {
+		if timedOut(err) {
+			return wrapTimeout("pull "+image, err)
+		}
+		return fmt.Errorf("drain image pull: %w", err)
+	}
+	return nil
+}
+
+// RunContainer pulls the image if missing, creates the container, attaches
 // it to the named network, mounts the named volumes, and starts it. If a
 // container with that name already exists the call returns nil — caller is
 // expected to check ContainerStatus first if it cares.
 func (c *Client) RunContainer(ctx context.Context, spec RunSpec) error {
-	// Pull image — idempotent; ImagePull short-circuits when the image is local.
-	pullReader, err := c.cli.ImagePull(ctx, spec.Image, types.ImagePullOptions{})
+	platform := c.platformFor(spec.Platform)
+	ociPlatform, err := ParsePlatform(platform)
 	if err != nil {
-		return fmt.Errorf("pull %s: %w", spec.Image, err)
+		return err
 	}
-	if _, err := io.Copy(io.Discard, pullReader); err != nil {
-		_ = pullReader.Close()
-		return fmt.Errorf("drain image pull: %w", err)
+	if err := c.ensureImage(ctx, spec.Image, platform); err != nil {
+		return err
 	}
-	_ = pullReader.Close()
 
 	// Build env slice
 	envSlice := make([]string, 0, len(spec.Env))
@@ -324,6 +536,14 @@ func (c *Client) RunContainer(ctx context.Context, spec RunSpec) error {
 	hostCfg := &container.HostConfig{
 		Mounts:        mounts,
 		RestartPolicy: container.RestartPolicy{Name: "unless-stopped"},
+		// Rotate logs so a chatty long-lived singleton can't fill the disk.
+		LogConfig: container.LogConfig{
+			Type:   "json-file",
+			Config: map[string]string{"max-size": "10m", "max-file": "3"},
+		},
+	}
+	if spec.Init {
+		hostCfg.Init = &spec.Init
 	}
 	netCfg := &network.NetworkingConfig{
 		EndpointsConfig: map[string]*network.EndpointSettings{
@@ -331,7 +551,7 @@ func (c *Client) RunContainer(ctx context.Context, spec RunSpec) error {
 		},
 	}
 
-	resp, err := c.cli.ContainerCreate(ctx, cfg, hostCfg, netCfg, nil, spec.Name)
+	resp, err := c.cli.ContainerCreate(ctx, cfg, hostCfg, netCfg, ociPlatform, spec.Name)
 	if err != nil {
 		// If the daemon already has a container with that name, treat as success.
 		if errdefs.IsConflict(err) {
@@ -375,9 +595,10 @@ func (c *Client) ExecCommand(ctx context.Context, container string, cmd []string
 }
 
 // EnsureBridgeNetwork creates a default-driver bridge network with no IPAM
-// override if absent. Idempotent. Differs from EnsureNetwork (which requires
-// a subnet) — used for paas-net where we just want Docker DNS between
-// service-plane containers and their per-env consumers.
+// override if absent. Idempotent. Used for paas-net where we just want
+// Docker DNS between service-plane containers and their per-env consumers;
+// Docker picks the subnet from its default address pools, so it can't
+// overlap another network.
 func (c *Client) EnsureBridgeNetwork(ctx context.Context, name string) error {
 	networks, err := c.cli.NetworkList(ctx, types.NetworkListOptions{
 		Filters: filters.NewArgs(filters.Arg("name", name)),
@@ -0,0 +1,25 @@
+package docker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/client"
+)
+
+// fakeDaemon serves the Docker API from handler and returns a Client
+// talking to it. The server is closed when the test ends.
+func fakeDaemon(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	cli, err := client.NewClientWithOpts(client.WithHost("tcp://"+strings.TrimPrefix(srv.URL, "http://")), client.WithVersion("1.44"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Client{cli: cli, ctx: context.Background(), pullTimeout: time.Minute}
+}
@@ -0,0 +1,126 @@
+package docker
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeImageDaemon answers image inspect (200 for a linux/amd64 image when
+// present, 404 otherwise) and records pulls as "<image>" or "<image> platform=<platform>".
+func fakeImageDaemon(t *testing.T, present bool) (*Client, *[]string) {
+	t.Helper()
+	var mu sync.Mutex
+	var pulls []string
+	c := fakeDaemon(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/images/create"):
+			mu.Lock()
+			pull := r.URL.Query().Get("fromImage")
+			if p := r.URL.Query().Get("platform"); p != "" {
+				pull += " platform=" + p
+			}
+			pulls = append(pulls, pull)
+			mu.Unlock()
+			_, _ = w.Write([]byte(`{"status":"done"}`))
+		case strings.HasSuffix(r.URL.Path, "/json") && strings.Contains(r.URL.Path, "/images/"):
+			if !present {
+				http.Error(w, `{"message":"no such image"}`, http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(`{"Id":"sha256:abc","Os":"linux","Architecture":"amd64"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	return c, &pulls
+}
+
+func TestEnsureImage_SkipsPullWhenLocal(t *testing.T) {
+	c, pulls := fakeImageDaemon(t, true)
+	if err := c.ensureImage(context.Background(), "postgres:16", ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(*pulls) != 0 {
+		t.Errorf("pulled %v, want no pull for a local image", *pulls)
+	}
+}
+
+func TestEnsureImage_PullsWhenMissing(t *testing.T) {
+	c, pulls := fakeImageDaemon(t, false)
+	if err := c.ensureImage(context.Background(), "postgres:16", ""); err != nil {
+		t.Fatal(err)
+	}
+	if len(*pulls) != 1 || (*pulls)[0] != "postgres" {
+		t.Errorf("pulls = %v, want one pull of postgres", *pulls)
+	}
+}
+
+func TestEnsureImage_PullsPlatform(t *testing.T) {
+	c, pulls := fakeImageDaemon(t, false)
+	if err := c.ensureImage(context.Background(), "postgres:16", "linux/arm64"); err != nil {
+		t.Fatal(err)
+	}
+	if len(*pulls) != 1 || (*pulls)[0] != "postgres platform=linux/arm64" {
+		t.Errorf("pulls = %v, want one linux/arm64 pull of postgres", *pulls)
+	}
+}
+
+func TestEnsureImage_SkipsPullWhenLocalMatchesPlatform(t *testing.T) {
+	c, pulls := fakeImageDaemon(t, true)
+	if err := c.ensureImage(context.Background(), "postgres:16", "linux/amd64"); err != nil {
+		t.Fatal(err)
+	}
+	if len(*pulls) != 0 {
+		t.Errorf("pulled %v, want no pull for a local linux/amd64 image", *pulls)
+	}
+}
+
+func TestEnsureImage_PullsWhenLocalIsOtherPlatform(t *testing.T) {
+	c, pulls := fakeImageDaemon(t, true)
+	if err := c.ensureImage(context.Background(), "postgres:16", "linux/arm64"); err != nil {
+		t.Fatal(err)
+	}
+	if len(*pulls) != 1 || (*pulls)[0] != "postgres platform=linux/arm64" {
+		t.Errorf("pulls = %v, want a linux/arm64 pull over the local amd64 image", *pulls)
+	}
+}
+
+func TestParsePlatform(t *testing.T) {
+	p, err := ParsePlatform("linux/arm/v7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.OS != "linux" || p.Architecture != "arm" || p.Variant != "v7" {
+		t.Errorf("got %+v", p)
+	}
+	if p, err := ParsePlatform(""); p != nil || err != nil {
+		t.Errorf("empty: got %+v, %v; want nil, nil", p, err)
+	}
+	for _, bad := range []string{"linux", "linux/", "/arm64", "linux/arm/v7/x"} {
+		if _, err := ParsePlatform(bad); err == nil {
+			t.Errorf("ParsePlatform(%q): want error", bad)
+		}
+	}
+}
+
+func TestPickRepoDigest(t *testing.T) {
+	digests := []string{"mirror.local/postgres@sha256:aaa", "postgres@sha256:bbb"}
+	cases := map[string]string{
+		"postgres:16":                    "postgres@sha256:bbb",
+		"postgres":                       "postgres@sha256:bbb",
+		"mirror.local/postgres:16":       "mirror.local/postgres@sha256:aaa",
+		"postgres@sha256:bbb":            "postgres@sha256:bbb",
+		"registry:5000/other/thing:v1.2": "mirror.local/postgres@sha256:aaa", // no match → first
+	}
+	for ref, want := range cases {
+		if got := pickRepoDigest(ref, digests); got != want {
+			t.Errorf("pickRepoDigest(%q) = %q, want %q", ref, got, want)
+		}
+	}
+	if got := pickRepoDigest("postgres:16", nil); got != "" {
+		t.Errorf("no digests: got %q", got)
+	}
+}
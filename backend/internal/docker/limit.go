@@ -0,0 +1,60 @@
+package docker
+
+import "context"
+
+// DefaultMaxConcurrentOps caps in-flight docker API calls made through the
+// bounded helpers (inspect / list / stop / restart / remove). Pulls are not
+// counted — they're long-running and have their own cap.
+const DefaultMaxConcurrentOps = 8
+
+// DefaultMaxConcurrentPulls caps simultaneous image pulls, so a host
+// bringing many envs up after a reboot doesn't split its bandwidth across
+// every image at once and time them all out.
+const DefaultMaxConcurrentPulls = 2
+
+// SetMaxConcurrentOps overrides the concurrent-operation cap. n <= 0
+// removes the cap. Call before serving.
+func (c *Client) SetMaxConcurrentOps(n int) {
+	if n <= 0 {
+		c.opSem = nil
+		return
+	}
+	c.opSem = make(chan struct{}, n)
+}
+
+// acquireOp takes a slot in the op semaphore, waiting until one frees up or
+// ctx is done. The returned func releases the slot. Callers acquire after
+// deriving their timeout context, so time spent queued counts against the
+// operation's deadline.
+func (c *Client) acquireOp(ctx context.Context) (func(), error) {
+	return acquire(ctx, c.opSem)
+}
+
+// SetMaxConcurrentPulls overrides the concurrent-pull cap. n <= 0 removes
+// the cap. Call before serving.
+func (c *Client) SetMaxConcurrentPulls(n int) {
+	if n <= 0 {
+		c.pullSem = nil
+		return
+	}
+	c.pullSem = make(chan struct{}, n)
+}
+
+// AcquirePull takes a slot in the pull semaphore, waiting until one frees
+// up or ctx is done. Exported so compose-driven pulls (builder.Runner)
+// share the same cap as the client's own.
+func (c *Client) AcquirePull(ctx context.Context) (func(), error) {
+	return acquire(ctx, c.pullSem)
+}
+
+func acquire(ctx context.Context, sem chan struct{}) (func(), error) {
+	if sem == nil {
+		return func() {}, nil
+	}
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
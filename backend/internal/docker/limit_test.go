@@ -0,0 +1,64 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAcquireOp_WaitsForSlot(t *testing.T) {
+	c := &Client{}
+	c.SetMaxConcurrentOps(1)
+
+	release, err := c.acquireOp(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Second caller queues until its deadline while the slot is held.
+	ctx, cancel := withTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.acquireOp(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want deadline exceeded", err)
+	}
+	release()
+	release2, err := c.acquireOp(context.Background())
+	if err != nil {
+		t.Fatalf("after release: %v", err)
+	}
+	release2()
+}
+
+func TestAcquireOp_Unbounded(t *testing.T) {
+	c := &Client{}
+	c.SetMaxConcurrentOps(0)
+	for i := 0; i < 100; i++ {
+		if _, err := c.acquireOp(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAcquirePull_SeparateFromOps(t *testing.T) {
+	c := &Client{}
+	c.SetMaxConcurrentOps(1)
+	c.SetMaxConcurrentPulls(1)
+
+	releasePull, err := c.AcquirePull(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer releasePull()
+	// A pull in flight doesn't starve inspect/list calls...
+	releaseOp, err := c.acquireOp(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	releaseOp()
+	// ...but a second pull queues.
+	ctx, cancel := withTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.AcquirePull(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want deadline exceeded", err)
+	}
+}
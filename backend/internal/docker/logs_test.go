@@ -0,0 +1,22 @@
+package docker
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestGetContainerLogs_SinceKeepsSubSeconds(t *testing.T) {
+	var since string
+	c := fakeDaemon(t, func(w http.ResponseWriter, r *http.Request) {
+		since = r.URL.Query().Get("since")
+	})
+	rc, err := c.GetContainerLogs("c1", false, "all", time.Unix(1700000000, 123456789).UTC())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc.Close()
+	if since != "1700000000.123456789" {
+		t.Errorf("since = %q, want 1700000000.123456789", since)
+	}
+}
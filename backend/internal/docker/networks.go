@@ -0,0 +1,69 @@
+package docker
+
+import (
+	"context"
+
+	"github.com/docker/docker/api/types"
+
+	"github.com/environment-manager/backend/internal/models"
+)
+
+// ListManagedNetworks returns the networks env-manager may own — labelled
+// env-manager.managed, or belonging to a compose project — with the number
+// of running containers attached to each. Compose networks of stacks env-manager
+// didn't deploy are included too; callers match ComposeProject against
+// their envs.
+func (c *Client) ListManagedNetworks(ctx context.Context) ([]models.ManagedNetwork, error) {
+	ctx, cancel := withTimeout(ctx, c.opTimeout)
+	defer cancel()
+	release, err := c.acquireOp(ctx)
+	if err != nil {
+		return nil, wrapTimeout("list networks", err)
+	}
+	defer release()
+	list, err := c.cli.NetworkList(ctx, types.NetworkListOptions{})
+	if err != nil {
+		return nil, wrapTimeout("list networks", err)
+	}
+	out := []models.ManagedNetwork{}
+	for _, n := range list {
+		m, ok := managedNetwork(n)
+		if !ok {
+			continue
+		}
+		// NetworkList leaves Containers empty; only inspect fills it.
+		full, err := c.cli.NetworkInspect(ctx, n.ID, types.NetworkInspectOptions{})
+		if err != nil {
+			return nil, wrapTimeout("inspect network "+n.Name, err)
+		}
+		m.Containers = len(full.Containers)
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+// RemoveNetwork removes a network by name or ID.
+func (c *Client) RemoveNetwork(ctx context.Context, id string) error {
+	ctx, cancel := withTimeout(ctx, c.opTimeout)
+	defer cancel()
+	release, err := c.acquireOp(ctx)
+	if err != nil {
+		return wrapTimeout("remove network "+id, err)
+	}
+	defer release()
+	return wrapTimeout("remove network "+id, c.cli.NetworkRemove(ctx, id))
+}
+
+// managedNetwork reports whether n is one of ours and maps it.
+func managedNetwork(n types.NetworkResource) (models.ManagedNetwork, bool) {
+	project := n.Labels["com.docker.compose.project"]
+	if n.Labels["env-manager.managed"] != "true" && project == "" {
+		return models.ManagedNetwork{}, false
+	}
+	return models.ManagedNetwork{
+		ID:             n.ID,
+		Name:           n.Name,
+		Driver:         n.Driver,
+		ComposeProject: project,
+	}, true
+}
@@ -0,0 +1,102 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/docker/client"
+)
+
+// Options selects the daemon the client talks to. The zero value keeps the
+// ambient behaviour: DOCKER_HOST / DOCKER_CERT_PATH from the process
+// environment, or the default socket.
+type Options struct {
+	// Host is the daemon address: unix:///var/run/docker.sock,
+	// tcp://10.0.0.5:2376. Empty = environment / default socket.
+	Host string
+	// APIVersion pins the API version (e.g. "1.43"). Empty negotiates.
+	APIVersion string
+	// CertPath is a directory holding ca.pem, cert.pem and key.pem, the
+	// layout `docker --tlsverify` uses. When set the client speaks mutual
+	// TLS and verifies the daemon's certificate against ca.pem.
+	CertPath string
+	// Platform ("linux/arm64", "linux/arm/v7") is pulled and created
+	// instead of the daemon's own platform, for images that aren't
+	// multi-arch aware. Compose subprocesses get it as
+	// DOCKER_DEFAULT_PLATFORM. Empty = the daemon's platform.
+	Platform string
+}
+
+// NewClientWithOptions creates a Docker client for the daemon described by
+// opts, falling back to the environment for anything opts leaves empty.
+func NewClientWithOptions(opts Options) (*Client, error) {
+	clientOpts, err := opts.clientOpts()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ParsePlatform(opts.Platform); err != nil {
+		return nil, err
+	}
+	cli, err := client.NewClientWithOpts(clientOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		cli:         cli,
+		ctx:         context.Background(),
+		opTimeout:   DefaultOpTimeout,
+		pullTimeout: DefaultPullTimeout,
+		opSem:       make(chan struct{}, DefaultMaxConcurrentOps),
+		pullSem:     make(chan struct{}, DefaultMaxConcurrentPulls),
+		platform:    opts.Platform,
+	}, nil
+}
+
+func (o Options) clientOpts() ([]client.Opt, error) {
+	out := []client.Opt{client.FromEnv, client.WithAPIVersionNegotiation()}
+	if o.Host != "" {
+		out = append(out, client.WithHost(o.Host))
+	}
+	if o.APIVersion != "" {
+		out = append(out, client.WithVersion(o.APIVersion))
+	}
+	if o.CertPath != "" {
+		ca, cert, key := o.certFiles()
+		for _, f := range []string{ca, cert, key} {
+			if _, err := os.Stat(f); err != nil {
+				return nil, fmt.Errorf("docker TLS: %w", err)
+			}
+		}
+		out = append(out, client.WithTLSClientConfig(ca, cert, key))
+	}
+	return out, nil
+}
+
+func (o Options) certFiles() (ca, cert, key string) {
+	return filepath.Join(o.CertPath, "ca.pem"),
+		filepath.Join(o.CertPath, "cert.pem"),
+		filepath.Join(o.CertPath, "key.pem")
+}
+
+// Env returns the DOCKER_* variables that point the docker CLI at the same
+// daemon, for `docker compose` subprocesses. Empty when opts is the zero
+// value — the subprocess then inherits the environment as before.
+func (o Options) Env() []string {
+	var env []string
+	if o.Host != "" {
+		env = append(env, "DOCKER_HOST="+o.Host)
+	}
+	if o.APIVersion != "" {
+		env = append(env, "DOCKER_API_VERSION="+o.APIVersion)
+	}
+	if o.CertPath != "" {
+		env = append(env, "DOCKER_CERT_PATH="+o.CertPath, "DOCKER_TLS_VERIFY=1")
+	}
+	if o.Platform != "" {
+		env = append(env, "DOCKER_DEFAULT_PLATFORM="+o.Platform)
+	}
+	return env
+}
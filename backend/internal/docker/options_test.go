@@ -0,0 +1,39 @@
+package docker
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewClientWithOptions_Host(t *testing.T) {
+	c, err := NewClientWithOptions(Options{Host: "tcp://10.0.0.5:2375", APIVersion: "1.43"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if got := c.cli.DaemonHost(); got != "tcp://10.0.0.5:2375" {
+		t.Errorf("DaemonHost = %q", got)
+	}
+	if got := c.cli.ClientVersion(); got != "1.43" {
+		t.Errorf("ClientVersion = %q, want pinned 1.43", got)
+	}
+}
+
+func TestNewClientWithOptions_MissingCerts(t *testing.T) {
+	_, err := NewClientWithOptions(Options{Host: "tcp://10.0.0.5:2376", CertPath: t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "ca.pem") {
+		t.Fatalf("err = %v, want missing ca.pem", err)
+	}
+}
+
+func TestOptions_Env(t *testing.T) {
+	if env := (Options{}).Env(); len(env) != 0 {
+		t.Errorf("zero Options env = %v, want none", env)
+	}
+	got := Options{Host: "tcp://h:2376", APIVersion: "1.43", CertPath: "/certs", Platform: "linux/arm64"}.Env()
+	want := []string{"DOCKER_HOST=tcp://h:2376", "DOCKER_API_VERSION=1.43", "DOCKER_CERT_PATH=/certs", "DOCKER_TLS_VERIFY=1", "DOCKER_DEFAULT_PLATFORM=linux/arm64"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Env = %v, want %v", got, want)
+	}
+}
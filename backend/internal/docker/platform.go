@@ -0,0 +1,55 @@
+package docker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// ParsePlatform parses an "os/arch[/variant]" platform selector such as
+// "linux/arm64" or "linux/arm/v7". Empty returns nil: the daemon's own
+// platform.
+func ParsePlatform(s string) (*ocispec.Platform, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("platform %q: want os/arch[/variant]", s)
+	}
+	for _, p := range parts {
+		if p == "" {
+			return nil, fmt.Errorf("platform %q: want os/arch[/variant]", s)
+		}
+	}
+	p := &ocispec.Platform{OS: strings.ToLower(parts[0]), Architecture: strings.ToLower(parts[1])}
+	if len(parts) == 3 {
+		p.Variant = strings.ToLower(parts[2])
+	}
+	return p, nil
+}
+
+// platformFor returns the platform a pull or create for spec should use:
+// the spec's own, else the client's default. "" = the daemon's platform.
+func (c *Client) platformFor(platform string) string {
+	if platform != "" {
+		return platform
+	}
+	return c.platform
+}
+
+// platformMatches reports whether a local image satisfies want. nil matches
+// anything; a variant is only compared when want names one, since images
+// often leave it blank.
+func platformMatches(want *ocispec.Platform, img types.ImageInspect) bool {
+	if want == nil {
+		return true
+	}
+	if !strings.EqualFold(img.Os, want.OS) || !strings.EqualFold(img.Architecture, want.Architecture) {
+		return false
+	}
+	return want.Variant == "" || strings.EqualFold(img.Variant, want.Variant)
+}
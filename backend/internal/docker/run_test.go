@@ -0,0 +1,56 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// runCreateRequest runs spec against a fake daemon that already has the
+// image, and returns the HostConfig sent with the container create.
+func runCreateRequest(t *testing.T, spec RunSpec) container.HostConfig {
+	t.Helper()
+	var hostCfg container.HostConfig
+	c := fakeDaemon(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "/images/") && strings.HasSuffix(r.URL.Path, "/json"):
+			_, _ = w.Write([]byte(`{"Id":"sha256:abc"}`))
+		case strings.HasSuffix(r.URL.Path, "/containers/create"):
+			var body struct {
+				HostConfig container.HostConfig
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("decode create body: %v", err)
+			}
+			hostCfg = body.HostConfig
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"Id":"c1"}`))
+		case strings.HasSuffix(r.URL.Path, "/start"):
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	if err := c.RunContainer(context.Background(), spec); err != nil {
+		t.Fatal(err)
+	}
+	return hostCfg
+}
+
+func TestRunContainer_Init(t *testing.T) {
+	hc := runCreateRequest(t, RunSpec{Name: "paas-postgres", Image: "postgres:16", Network: "paas-net", Init: true})
+	if hc.Init == nil || !*hc.Init {
+		t.Errorf("HostConfig.Init = %v, want true", hc.Init)
+	}
+}
+
+func TestRunContainer_NoInit(t *testing.T) {
+	hc := runCreateRequest(t, RunSpec{Name: "paas-redis", Image: "redis:7", Network: "paas-net"})
+	if hc.Init != nil {
+		t.Errorf("HostConfig.Init = %v, want unset", *hc.Init)
+	}
+}
@@ -0,0 +1,75 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+
+	"github.com/environment-manager/backend/internal/models"
+)
+
+// ContainerUsage samples the memory and CPU use of the container id
+// alongside the limits it was created with. The daemon takes two CPU
+// readings about a second apart, so this blocks for roughly that long.
+func (c *Client) ContainerUsage(ctx context.Context, id string) (models.ContainerUsage, error) {
+	ctx, cancel := withTimeout(ctx, c.opTimeout)
+	defer cancel()
+	release, err := c.acquireOp(ctx)
+	if err != nil {
+		return models.ContainerUsage{}, wrapTimeout("stats "+id, err)
+	}
+	defer release()
+	info, err := c.cli.ContainerInspect(ctx, id)
+	if err != nil {
+		return models.ContainerUsage{}, wrapTimeout("inspect "+id, err)
+	}
+	resp, err := c.cli.ContainerStats(ctx, id, false)
+	if err != nil {
+		return models.ContainerUsage{}, wrapTimeout("stats "+id, err)
+	}
+	defer resp.Body.Close()
+	var stats types.StatsJSON
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return models.ContainerUsage{}, wrapTimeout("stats "+id, err)
+	}
+	var hostCfg container.HostConfig
+	if info.HostConfig != nil {
+		hostCfg = *info.HostConfig
+	}
+	return containerUsage(stats.Stats, hostCfg), nil
+}
+
+// containerUsage computes usage the way `docker stats` does: memory
+// excludes reclaimable page cache (inactive_file on cgroup v2,
+// total_inactive_file on v1), CPU is the share of host CPU time since the
+// previous reading scaled by the online CPU count.
+func containerUsage(s types.Stats, hostCfg container.HostConfig) models.ContainerUsage {
+	u := models.ContainerUsage{MemUsage: s.MemoryStats.Usage}
+	for _, key := range []string{"inactive_file", "total_inactive_file"} {
+		if v, ok := s.MemoryStats.Stats[key]; ok && v < u.MemUsage {
+			u.MemUsage -= v
+			break
+		}
+	}
+	if hostCfg.Memory > 0 {
+		u.MemLimit = uint64(hostCfg.Memory)
+	}
+	cpuDelta := float64(s.CPUStats.CPUUsage.TotalUsage) - float64(s.PreCPUStats.CPUUsage.TotalUsage)
+	sysDelta := float64(s.CPUStats.SystemUsage) - float64(s.PreCPUStats.SystemUsage)
+	if cpuDelta > 0 && sysDelta > 0 {
+		cpus := float64(s.CPUStats.OnlineCPUs)
+		if cpus == 0 {
+			cpus = float64(len(s.CPUStats.CPUUsage.PercpuUsage))
+		}
+		u.CPUPercent = cpuDelta / sysDelta * cpus * 100
+	}
+	switch {
+	case hostCfg.NanoCPUs > 0:
+		u.CPULimit = float64(hostCfg.NanoCPUs) / 1e9
+	case hostCfg.CPUQuota > 0 && hostCfg.CPUPeriod > 0:
+		u.CPULimit = float64(hostCfg.CPUQuota) / float64(hostCfg.CPUPeriod)
+	}
+	return u
+}
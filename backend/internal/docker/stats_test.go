@@ -0,0 +1,34 @@
+package docker
+
+import (
+	"math"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+)
+
+func TestContainerUsage(t *testing.T) {
+	var s types.Stats
+	s.MemoryStats.Usage = 600 << 20
+	s.MemoryStats.Stats = map[string]uint64{"inactive_file": 100 << 20}
+	s.PreCPUStats.CPUUsage.TotalUsage = 1_000
+	s.PreCPUStats.SystemUsage = 100_000
+	s.CPUStats.CPUUsage.TotalUsage = 6_000
+	s.CPUStats.SystemUsage = 110_000
+	s.CPUStats.OnlineCPUs = 4
+
+	u := containerUsage(s, container.HostConfig{Resources: container.Resources{Memory: 1 << 30, NanoCPUs: 500_000_000}})
+	if u.MemUsage != 500<<20 || u.MemLimit != 1<<30 {
+		t.Errorf("memory = %d / %d, want 500MiB / 1GiB", u.MemUsage, u.MemLimit)
+	}
+	// 5000 of 10000 host ticks across 4 CPUs = two full CPUs.
+	if math.Abs(u.CPUPercent-200) > 0.001 || u.CPULimit != 0.5 {
+		t.Errorf("cpu = %.3f%% limit %v, want 200%% limit 0.5", u.CPUPercent, u.CPULimit)
+	}
+
+	u = containerUsage(types.Stats{}, container.HostConfig{Resources: container.Resources{CPUQuota: 150_000, CPUPeriod: 100_000}})
+	if u.MemLimit != 0 || u.CPUPercent != 0 || u.CPULimit != 1.5 {
+		t.Errorf("unlimited/idle: got %+v", u)
+	}
+}
@@ -0,0 +1,46 @@
+package docker
+
+import (
+	"context"
+	"time"
+
+	"github.com/environment-manager/backend/internal/models"
+)
+
+// sigkillExitCode is what docker reports for a container it had to SIGKILL
+// (128 + 9).
+const sigkillExitCode = 137
+
+// StopContainerReport stops the named container like StopContainer and
+// reports whether it exited on SIGTERM within the grace period or was
+// killed. A container that isn't running is left alone and reported with
+// WasRunning false.
+func (c *Client) StopContainerReport(ctx context.Context, name string, timeout *int) (models.StopReport, error) {
+	before, err := c.ContainerState(ctx, name)
+	if err != nil {
+		return models.StopReport{}, err
+	}
+	if !before.Running {
+		return models.StopReport{}, nil
+	}
+	start := time.Now()
+	if err := c.StopContainer(name, timeout); err != nil {
+		return models.StopReport{WasRunning: true}, err
+	}
+	elapsed := time.Since(start)
+	after, err := c.ContainerState(ctx, name)
+	if err != nil {
+		return models.StopReport{WasRunning: true, DurationMS: elapsed.Milliseconds()}, err
+	}
+	return stopReport(after, elapsed), nil
+}
+
+// stopReport classifies a stopped container's final state.
+func stopReport(st models.ContainerState, elapsed time.Duration) models.StopReport {
+	return models.StopReport{
+		WasRunning: true,
+		Graceful:   !(st.ExitCode == sigkillExitCode && !st.OOMKilled),
+		ExitCode:   st.ExitCode,
+		DurationMS: elapsed.Milliseconds(),
+	}
+}
@@ -0,0 +1,28 @@
+package docker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/environment-manager/backend/internal/models"
+)
+
+func TestStopReport(t *testing.T) {
+	cases := []struct {
+		name     string
+		state    models.ContainerState
+		graceful bool
+	}{
+		{"clean exit on SIGTERM", models.ContainerState{ExitCode: 0}, true},
+		{"app error exit", models.ContainerState{ExitCode: 1}, true},
+		{"SIGTERM default handler", models.ContainerState{ExitCode: 143}, true},
+		{"killed after grace period", models.ContainerState{ExitCode: 137}, false},
+		{"OOM kill is not a stop timeout", models.ContainerState{ExitCode: 137, OOMKilled: true}, true},
+	}
+	for _, c := range cases {
+		got := stopReport(c.state, 10*time.Second)
+		if got.Graceful != c.graceful || got.ExitCode != c.state.ExitCode || !got.WasRunning || got.DurationMS != 10000 {
+			t.Errorf("%s: got %+v, want graceful=%v", c.name, got, c.graceful)
+		}
+	}
+}
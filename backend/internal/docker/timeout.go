@@ -0,0 +1,52 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTimeout is returned (wrapped) when a docker operation exceeds its
+// configured timeout. The wrapped chain also carries
+// context.DeadlineExceeded, so callers that don't import this package can
+// still detect it.
+var ErrTimeout = errors.New("docker operation timed out")
+
+// Default per-operation timeouts. Pulls get far longer than everything
+// else — a multi-GB image on a slow link legitimately takes minutes.
+const (
+	DefaultOpTimeout   = 30 * time.Second
+	DefaultPullTimeout = 15 * time.Minute
+)
+
+// SetTimeouts overrides the per-operation timeouts. op bounds inspect /
+// list / stop / restart / remove calls; pull bounds image pulls. Zero
+// disables the respective timeout. Call before serving.
+func (c *Client) SetTimeouts(op, pull time.Duration) {
+	c.opTimeout = op
+	c.pullTimeout = pull
+}
+
+// withTimeout derives a context bounded by d. d <= 0 returns ctx unchanged.
+func withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+// timedOut reports whether err stems from an exceeded deadline.
+func timedOut(err error) bool {
+	return err != nil && errors.Is(err, context.DeadlineExceeded)
+}
+
+// wrapTimeout turns a deadline-exceeded error into one that matches both
+// ErrTimeout and context.DeadlineExceeded, naming the operation. Other
+// errors pass through untouched.
+func wrapTimeout(op string, err error) error {
+	if timedOut(err) {
+		return fmt.Errorf("%s: %w (%w)", op, ErrTimeout, context.DeadlineExceeded)
+	}
+	return err
+}
@@ -0,0 +1,26 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWrapTimeout(t *testing.T) {
+	ctx, cancel := withTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	err := wrapTimeout("inspect x", ctx.Err())
+	if !errors.Is(err, ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("wrapped error %v should match ErrTimeout and DeadlineExceeded", err)
+	}
+	other := errors.New("boom")
+	if got := wrapTimeout("inspect x", other); got != other {
+		t.Errorf("non-timeout error should pass through, got %v", got)
+	}
+	if wrapTimeout("inspect x", nil) != nil {
+		t.Error("nil should stay nil")
+	}
+}
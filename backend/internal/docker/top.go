@@ -0,0 +1,56 @@
+package docker
+
+import (
+	"context"
+
+	"github.com/environment-manager/backend/internal/models"
+)
+
+// topPsArgs are handed to the host's ps by `docker top`. Naming the
+// columns keeps the output shape stable regardless of ps defaults.
+var topPsArgs = []string{"-o", "pid,user,%cpu,%mem,etime,args"}
+
+// ContainerTop lists the processes running in the named container, as
+// `docker top` sees them. Read-only — a diagnostic that needs no exec.
+func (c *Client) ContainerTop(ctx context.Context, name string) ([]models.ContainerProcess, error) {
+	ctx, cancel := withTimeout(ctx, c.opTimeout)
+	defer cancel()
+	release, err := c.acquireOp(ctx)
+	if err != nil {
+		return nil, wrapTimeout("top "+name, err)
+	}
+	defer release()
+	body, err := c.cli.ContainerTop(ctx, name, topPsArgs)
+	if err != nil {
+		return nil, wrapTimeout("top "+name, err)
+	}
+	return topProcesses(body.Titles, body.Processes), nil
+}
+
+// topProcesses maps ps rows onto ContainerProcess by column title, so an
+// unexpected column order (or a missing column) degrades to empty fields
+// rather than misattributed values.
+func topProcesses(titles []string, rows [][]string) []models.ContainerProcess {
+	idx := map[string]int{}
+	for i, t := range titles {
+		idx[t] = i
+	}
+	col := func(row []string, title string) string {
+		if i, ok := idx[title]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+	out := make([]models.ContainerProcess, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, models.ContainerProcess{
+			PID:     col(row, "PID"),
+			User:    col(row, "USER"),
+			CPU:     col(row, "%CPU"),
+			Mem:     col(row, "%MEM"),
+			Elapsed: col(row, "ELAPSED"),
+			Command: col(row, "COMMAND"),
+		})
+	}
+	return out
+}
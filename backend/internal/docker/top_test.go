@@ -0,0 +1,21 @@
+package docker
+
+import "testing"
+
+func TestTopProcesses(t *testing.T) {
+	titles := []string{"USER", "PID", "%CPU", "%MEM", "ELAPSED", "COMMAND"}
+	rows := [][]string{
+		{"root", "1", "0.0", "0.1", "01:02:03", "nginx: master process"},
+		{"101", "29"}, // short row: missing columns stay empty
+	}
+	got := topProcesses(titles, rows)
+	if len(got) != 2 {
+		t.Fatalf("got %d rows", len(got))
+	}
+	if got[0].PID != "1" || got[0].User != "root" || got[0].Command != "nginx: master process" || got[0].Elapsed != "01:02:03" {
+		t.Errorf("row 0 = %+v", got[0])
+	}
+	if got[1].PID != "29" || got[1].CPU != "" {
+		t.Errorf("row 1 = %+v", got[1])
+	}
+}
@@ -8,6 +8,11 @@ type Config struct {
 	Services    Services   `yaml:"services"`
 	Secrets     []string   `yaml:"secrets"`
 	Hooks       Hooks      `yaml:"hooks"`
+	// ServiceSubdomains routes every other compose service with a ports:
+	// entry at <service>.<env host>, next to the exposed service. Off by
+	// default; see builder.InjectTraefikLabels for the per-service label
+	// overrides.
+	ServiceSubdomains bool `yaml:"service_subdomains"`
 }
 
 // ExposeSpec identifies the user-facing service:port that Traefik routes to.
@@ -0,0 +1,78 @@
+package models
+
+// ContainerState is the subset of a container's `docker inspect` .State
+// block that the status endpoints surface. ExitCode and OOMKilled are only
+// meaningful once the container has stopped; a running container reports
+// zero/false for both.
+type ContainerState struct {
+	Status    string `json:"status"` // created | running | paused | restarting | exited | dead
+	Running   bool   `json:"running"`
+	ExitCode  int    `json:"exit_code"`
+	OOMKilled bool   `json:"oom_killed"`
+	// ImageID is the resolved image the container runs (sha256:...), and
+	// ImageDigest its registry digest (repo@sha256:...) when the image was
+	// pulled; empty for locally built images. Comparing them against a
+	// fresh pull shows whether the container runs an outdated image.
+	ImageID     string `json:"image_id,omitempty"`
+	ImageDigest string `json:"image_digest,omitempty"`
+}
+
+// ComposeContainer is one container belonging to a docker compose project,
+// linked back to its compose service via the com.docker.compose.* labels.
+type ComposeContainer struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Service string `json:"service"` // com.docker.compose.service
+	Image   string `json:"image"`
+	State   string `json:"state"`  // created | running | exited | ...
+	Status  string `json:"status"` // human-readable, e.g. "Up 3 hours"
+	// ExitCode and OOMKilled come from inspecting an exited or dead
+	// container; both are zero for one that hasn't stopped.
+	ExitCode  int  `json:"exit_code,omitempty"`
+	OOMKilled bool `json:"oom_killed,omitempty"`
+}
+
+// ContainerProcess is one row of `docker top` for a container. CPU and Mem
+// are ps's %cpu / %mem columns, verbatim.
+type ContainerProcess struct {
+	PID     string `json:"pid"`
+	User    string `json:"user"`
+	CPU     string `json:"cpu"`
+	Mem     string `json:"mem"`
+	Elapsed string `json:"elapsed"`
+	Command string `json:"command"`
+}
+
+// ManagedNetwork is a docker network env-manager created: one labelled
+// env-manager.managed (paas-net), or a compose project network
+// (<env_id>_default, ...). Containers counts the attached running
+// containers.
+type ManagedNetwork struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	Driver         string `json:"driver"`
+	ComposeProject string `json:"compose_project,omitempty"` // com.docker.compose.project
+	Containers     int    `json:"containers"`
+}
+
+// StopReport describes how a container went down. Graceful is false when
+// it ignored SIGTERM for the whole grace period and docker had to SIGKILL
+// it (exit code 137 without an OOM kill). Fields beyond WasRunning are
+// zero when the container wasn't running — there was nothing to stop.
+type StopReport struct {
+	WasRunning bool  `json:"was_running"`
+	Graceful   bool  `json:"graceful"`
+	ExitCode   int   `json:"exit_code"`
+	DurationMS int64 `json:"duration_ms"`
+}
+
+// ContainerUsage is a point-in-time resource sample of one container.
+// MemLimit and CPULimit are the limits the container was created with; 0
+// means none was set. CPUPercent is docker stats' figure: 100 = one full
+// CPU.
+type ContainerUsage struct {
+	MemUsage   uint64  `json:"mem_usage"`
+	MemLimit   uint64  `json:"mem_limit,omitempty"`
+	CPUPercent float64 `json:"cpu_percent"`
+	CPULimit   float64 `json:"cpu_limit,omitempty"` // in CPUs
+}
@@ -2,6 +2,11 @@ package models
 
 import "time"
 
+// SchemaVersion is the current on-disk schema version of Project and
+// Environment records. Bump it (and register a migration step in
+// projects.MigrateSchema) whenever a field is renamed or reinterpreted.
+const SchemaVersion = 1
+
 // EnvironmentKind classifies an environment's role within a project.
 type EnvironmentKind string
 
@@ -82,6 +87,19 @@ type Project struct {
 	// created from, when applicable. Empty for natively-onboarded projects.
 	MigratedFromCompose string      `yaml:"migrated_from_compose,omitempty" json:"migrated_from_compose,omitempty"`
 	Expose              *ExposeSpec `yaml:"expose,omitempty" json:"expose,omitempty"`
+	// Notes is free-form operator text ("prod DB lives here — ask before
+	// deleting"). Pure metadata: shown in the UI, never reaches docker.
+	Notes string `yaml:"notes,omitempty" json:"notes,omitempty"`
+	// Protected makes DELETE /projects/{id} refuse unless the caller passes
+	// ?force=true&confirm=<id> — a guard against fat-fingering prod.
+	Protected bool `yaml:"protected,omitempty" json:"protected,omitempty"`
+	// Profiles are the compose profiles passed as --profile on every
+	// build and teardown, so optional services (a debug sidecar, say)
+	// only run when enabled. Set via the build trigger.
+	Profiles []string `yaml:"profiles,omitempty" json:"profiles,omitempty"`
+	// SchemaVersion records which layout this row was written with. 0 =
+	// written before versioning existed.
+	SchemaVersion int `yaml:"schema_version,omitempty" json:"schema_version,omitempty"`
 }
 
 // Environment is a deployed instance of a Project for one branch.
@@ -97,6 +115,13 @@ type Environment struct {
 	LastBuildID     string            `yaml:"last_build_id,omitempty" json:"last_build_id,omitempty"`
 	LastDeployedSHA string            `yaml:"last_deployed_sha,omitempty" json:"last_deployed_sha,omitempty"`
 	CreatedAt       time.Time         `yaml:"created_at" json:"created_at"`
+	SchemaVersion   int               `yaml:"schema_version,omitempty" json:"schema_version,omitempty"`
+	// LastExitCode and OOMKilled say why the last build failed: the exit
+	// code of the compose command or container that stopped it, and
+	// whether the kernel OOM killer was the cause. Reset when a build
+	// starts.
+	LastExitCode int  `yaml:"last_exit_code,omitempty" json:"last_exit_code,omitempty"`
+	OOMKilled    bool `yaml:"oom_killed,omitempty" json:"oom_killed,omitempty"`
 }
 
 // Build is one deploy attempt against an Environment.
@@ -109,4 +134,24 @@ type Build struct {
 	FinishedAt  *time.Time   `yaml:"finished_at,omitempty" json:"finished_at,omitempty"`
 	Status      BuildStatus  `yaml:"status" json:"status"`
 	LogPath     string       `yaml:"log_path" json:"log_path"`
+	// ComposePath is a snapshot of the compose as `docker compose config`
+	// resolved it for this build (after label/network/defaults injection
+	// and variable substitution), so what was deployed stays inspectable
+	// after later builds overwrite it.
+	ComposePath string `yaml:"compose_path,omitempty" json:"compose_path,omitempty"`
+}
+
+// EnvEvent is one recorded lifecycle transition of an Environment. Events
+// are appended by the projects store whenever an env's Status changes, so
+// the history survives even when nobody was watching the UI at the time.
+type EnvEvent struct {
+	Time    time.Time         `json:"time"`
+	EnvID   string            `json:"env_id"`
+	From    EnvironmentStatus `json:"from,omitempty"`
+	To      EnvironmentStatus `json:"to"`
+	BuildID string            `json:"build_id,omitempty"`
+	// ExitCode and OOMKilled are copied from the Environment, so a
+	// transition to failed records what killed the build.
+	ExitCode  int  `json:"exit_code,omitempty"`
+	OOMKilled bool `json:"oom_killed,omitempty"`
 }
@@ -0,0 +1,210 @@
+// Package monitor watches the resource use of running env containers and
+// raises an alert when one stays close to its limits — a pending OOM kill
+// or a CPU-starved service — before it turns into an outage.
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/environment-manager/backend/internal/models"
+)
+
+// Thresholds configure when a container counts as over its limits.
+type Thresholds struct {
+	// MemPercent is the share of the container's memory limit (0-100)
+	// above which it's over. 0 disables memory alerts for containers
+	// with a limit.
+	MemPercent float64
+	// MemBytes is the absolute usage above which a container with no
+	// memory limit is over. 0 skips containers without a limit.
+	MemBytes uint64
+	// CPUPercent is the share of the container's CPU limit (0-100) above
+	// which it's over. Containers without a CPU limit are skipped. 0
+	// disables CPU alerts.
+	CPUPercent float64
+	// For is how long a container must stay over before the alert fires,
+	// so a brief spike doesn't page anyone.
+	For time.Duration
+}
+
+// Alert is one container that stayed over a threshold for Thresholds.For.
+type Alert struct {
+	EnvID     string `json:"env_id"`
+	Service   string `json:"service"`
+	Container string `json:"container"`
+	Resource  string `json:"resource"` // "memory" | "cpu"
+	Message   string `json:"message"`
+}
+
+// Docker is the container surface the monitor samples.
+// *docker.Client satisfies it.
+type Docker interface {
+	ListComposeContainers(ctx context.Context, project string) ([]models.ComposeContainer, error)
+	ContainerUsage(ctx context.Context, id string) (models.ContainerUsage, error)
+}
+
+// EnvLister lists the envs to watch. *projects.Store satisfies it.
+type EnvLister interface {
+	ListProjects() ([]*models.Project, error)
+	ListEnvironments(projectID string) ([]*models.Environment, error)
+}
+
+// Monitor samples every container of every running env on each pass.
+// An alert fires once when a container has been over a threshold for
+// Thresholds.For, and re-arms after usage drops back under it.
+type Monitor struct {
+	docker Docker
+	envs   EnvLister
+	th     Thresholds
+	notify func(Alert)
+	logger *zap.Logger
+	now    func() time.Time
+
+	overSince map[string]time.Time // container/resource → first pass seen over
+	fired     map[string]bool
+}
+
+// New returns a Monitor that calls notify for every alert.
+func New(docker Docker, envs EnvLister, th Thresholds, notify func(Alert), logger *zap.Logger) *Monitor {
+	return &Monitor{
+		docker:    docker,
+		envs:      envs,
+		th:        th,
+		notify:    notify,
+		logger:    logger,
+		now:       time.Now,
+		overSince: map[string]time.Time{},
+		fired:     map[string]bool{},
+	}
+}
+
+// Run checks every interval until ctx is done.
+func (m *Monitor) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			m.check(ctx)
+		}
+	}
+}
+
+// check is one pass over all running envs. Sampling errors are logged and
+// the container skipped; its over-threshold timer keeps running.
+func (m *Monitor) check(ctx context.Context) {
+	projs, err := m.envs.ListProjects()
+	if err != nil {
+		m.logger.Warn("Resource monitor: list projects failed", zap.Error(err))
+		return
+	}
+	seen := map[string]bool{}
+	for _, p := range projs {
+		envs, err := m.envs.ListEnvironments(p.ID)
+		if err != nil {
+			m.logger.Warn("Resource monitor: list envs failed", zap.String("project", p.ID), zap.Error(err))
+			continue
+		}
+		for _, env := range envs {
+			if env.Status != models.EnvStatusRunning {
+				continue
+			}
+			containers, err := m.docker.ListComposeContainers(ctx, env.ID)
+			if err != nil {
+				m.logger.Warn("Resource monitor: list containers failed", zap.String("env", env.ID), zap.Error(err))
+				continue
+			}
+			for _, ct := range containers {
+				if ct.State != "running" {
+					continue
+				}
+				for _, res := range []string{"memory", "cpu"} {
+					seen[ct.ID+"/"+res] = true
+				}
+				usage, err := m.docker.ContainerUsage(ctx, ct.ID)
+				if err != nil {
+					m.logger.Warn("Resource monitor: sample failed", zap.String("container", ct.Name), zap.Error(err))
+					continue
+				}
+				m.evaluate(env.ID, ct, usage)
+			}
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}
+	for key := range m.overSince {
+		if !seen[key] {
+			delete(m.overSince, key)
+			delete(m.fired, key)
+		}
+	}
+}
+
+func (m *Monitor) evaluate(envID string, ct models.ComposeContainer, u models.ContainerUsage) {
+	memMsg := m.memoryOver(u)
+	m.track(envID, ct, "memory", memMsg)
+	cpuMsg := ""
+	if m.th.CPUPercent > 0 && u.CPULimit > 0 {
+		if pct := u.CPUPercent / u.CPULimit; pct > m.th.CPUPercent {
+			cpuMsg = fmt.Sprintf("CPU at %.0f%% of its %.2g CPU limit", pct, u.CPULimit)
+		}
+	}
+	m.track(envID, ct, "cpu", cpuMsg)
+}
+
+// memoryOver returns why u is over the memory threshold, or "" if it isn't.
+func (m *Monitor) memoryOver(u models.ContainerUsage) string {
+	if u.MemLimit > 0 {
+		if m.th.MemPercent <= 0 {
+			return ""
+		}
+		if pct := float64(u.MemUsage) / float64(u.MemLimit) * 100; pct > m.th.MemPercent {
+			return fmt.Sprintf("memory at %.0f%% of its %s limit (%s)", pct, mib(u.MemLimit), mib(u.MemUsage))
+		}
+		return ""
+	}
+	if m.th.MemBytes > 0 && u.MemUsage > m.th.MemBytes {
+		return fmt.Sprintf("memory at %s with no limit set (alert threshold %s)", mib(u.MemUsage), mib(m.th.MemBytes))
+	}
+	return ""
+}
+
+// track advances the over-threshold timer for one container resource and
+// fires the alert once it has been over for Thresholds.For. An empty msg
+// means under the threshold, which re-arms the alert.
+func (m *Monitor) track(envID string, ct models.ComposeContainer, resource, msg string) {
+	key := ct.ID + "/" + resource
+	if msg == "" {
+		delete(m.overSince, key)
+		delete(m.fired, key)
+		return
+	}
+	now := m.now()
+	since, ok := m.overSince[key]
+	if !ok {
+		m.overSince[key] = now
+		since = now
+	}
+	if m.fired[key] || now.Sub(since) < m.th.For {
+		return
+	}
+	m.fired[key] = true
+	m.notify(Alert{
+		EnvID:     envID,
+		Service:   ct.Service,
+		Container: ct.Name,
+		Resource:  resource,
+		Message:   fmt.Sprintf("%s: %s for %s", ct.Name, msg, now.Sub(since).Round(time.Second)),
+	})
+}
+
+func mib(b uint64) string {
+	return fmt.Sprintf("%dMiB", b>>20)
+}
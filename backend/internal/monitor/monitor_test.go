@@ -0,0 +1,119 @@
+package monitor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/environment-manager/backend/internal/models"
+)
+
+type fakeDocker struct {
+	containers map[string][]models.ComposeContainer
+	usage      map[string]models.ContainerUsage
+}
+
+func (f *fakeDocker) ListComposeContainers(_ context.Context, project string) ([]models.ComposeContainer, error) {
+	return f.containers[project], nil
+}
+
+func (f *fakeDocker) ContainerUsage(_ context.Context, id string) (models.ContainerUsage, error) {
+	return f.usage[id], nil
+}
+
+type fakeEnvs struct{ envs []*models.Environment }
+
+func (f *fakeEnvs) ListProjects() ([]*models.Project, error) {
+	return []*models.Project{{ID: "p1"}}, nil
+}
+
+func (f *fakeEnvs) ListEnvironments(string) ([]*models.Environment, error) {
+	return f.envs, nil
+}
+
+func TestMonitor_FiresAfterSustainedUsage(t *testing.T) {
+	docker := &fakeDocker{
+		containers: map[string][]models.ComposeContainer{
+			"p1--main": {
+				{ID: "a", Name: "p1--main-web-1", Service: "web", State: "running"},
+				{ID: "b", Name: "p1--main-db-1", Service: "db", State: "running"},
+				{ID: "c", Name: "p1--main-job-1", Service: "job", State: "exited"},
+			},
+			"p1--feat": {{ID: "d", Name: "p1--feat-web-1", Service: "web", State: "running"}},
+		},
+		usage: map[string]models.ContainerUsage{
+			"a": {MemUsage: 950 << 20, MemLimit: 1000 << 20},      // 95% of limit
+			"b": {MemUsage: 3 << 30, CPUPercent: 90, CPULimit: 1}, // no mem limit, over absolute
+			"c": {MemUsage: 999 << 20, MemLimit: 1000 << 20},      // not running
+			"d": {MemUsage: 999 << 20, MemLimit: 1000 << 20},      // env not running
+		},
+	}
+	envs := &fakeEnvs{envs: []*models.Environment{
+		{ID: "p1--main", Status: models.EnvStatusRunning},
+		{ID: "p1--feat", Status: models.EnvStatusFailed},
+	}}
+	var alerts []Alert
+	m := New(docker, envs, Thresholds{MemPercent: 90, MemBytes: 2 << 30, CPUPercent: 80, For: time.Minute},
+		func(a Alert) { alerts = append(alerts, a) }, zap.NewNop())
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+
+	m.check(context.Background())
+	if len(alerts) != 0 {
+		t.Fatalf("alerted on first sample: %+v", alerts)
+	}
+	now = now.Add(time.Minute)
+	m.check(context.Background())
+	if len(alerts) != 3 {
+		t.Fatalf("alerts = %+v, want web memory, db memory, db cpu", alerts)
+	}
+	for _, a := range alerts {
+		if a.EnvID != "p1--main" || a.Message == "" {
+			t.Errorf("alert = %+v", a)
+		}
+	}
+	if a := alerts[0]; a.Service != "web" || a.Resource != "memory" || !strings.Contains(a.Message, "95%") {
+		t.Errorf("web alert = %+v", a)
+	}
+
+	// Still over: no repeat.
+	now = now.Add(time.Minute)
+	m.check(context.Background())
+	if len(alerts) != 3 {
+		t.Fatalf("repeated alerts: %+v", alerts[3:])
+	}
+
+	// Dropping under re-arms; going over again needs another full For.
+	docker.usage["a"] = models.ContainerUsage{MemUsage: 100 << 20, MemLimit: 1000 << 20}
+	m.check(context.Background())
+	docker.usage["a"] = models.ContainerUsage{MemUsage: 950 << 20, MemLimit: 1000 << 20}
+	m.check(context.Background())
+	if len(alerts) != 3 {
+		t.Fatalf("re-alerted without waiting: %+v", alerts[3:])
+	}
+	now = now.Add(time.Minute)
+	m.check(context.Background())
+	if len(alerts) != 4 || alerts[3].Service != "web" {
+		t.Fatalf("alerts = %+v, want web to fire again", alerts)
+	}
+}
+
+func TestMonitor_DisabledThresholds(t *testing.T) {
+	docker := &fakeDocker{
+		containers: map[string][]models.ComposeContainer{
+			"p1--main": {{ID: "a", Name: "web", State: "running"}},
+		},
+		usage: map[string]models.ContainerUsage{"a": {MemUsage: 8 << 30, CPUPercent: 400}},
+	}
+	envs := &fakeEnvs{envs: []*models.Environment{{ID: "p1--main", Status: models.EnvStatusRunning}}}
+	fired := false
+	m := New(docker, envs, Thresholds{MemPercent: 90, CPUPercent: 80}, func(Alert) { fired = true }, zap.NewNop())
+	m.check(context.Background())
+	m.check(context.Background())
+	if fired {
+		t.Error("alerted for a container without limits and no absolute threshold")
+	}
+}
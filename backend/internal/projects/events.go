@@ -0,0 +1,148 @@
+package projects
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/environment-manager/backend/internal/models"
+)
+
+// maxEnvEvents caps how many transitions are kept per environment. The
+// JSONL file is compacted back down to this size once it grows past twice
+// the cap. A per-file line count (read once, on the first append after
+// startup) keeps appends O(1) until then.
+const maxEnvEvents = 200
+
+func (s *Store) eventsPath(projectID, branchSlug string) string {
+	return filepath.Join(s.root, projectID, "events", branchSlug+".jsonl")
+}
+
+// appendEnvEvent records a status transition. Caller must hold s.mu.
+func (s *Store) appendEnvEvent(e *models.Environment, from models.EnvironmentStatus) error {
+	ev := models.EnvEvent{
+		Time:      time.Now().UTC(),
+		EnvID:     e.ID,
+		From:      from,
+		To:        e.Status,
+		BuildID:   e.LastBuildID,
+		ExitCode:  e.LastExitCode,
+		OOMKilled: e.OOMKilled,
+	}
+	line, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	path := s.eventsPath(e.ProjectID, e.BranchSlug)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	n, ok := s.eventLines[path]
+	if !ok {
+		events, err := readEnvEvents(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		n = len(events)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if s.eventLines == nil {
+		s.eventLines = map[string]int{}
+	}
+	s.eventLines[path] = n + 1
+	if s.onEvent != nil {
+		s.onEvent(ev)
+	}
+
+	if n+1 <= 2*maxEnvEvents {
+		return nil
+	}
+	events, err := readEnvEvents(path)
+	if err != nil {
+		return err
+	}
+	if len(events) > maxEnvEvents {
+		events = events[len(events)-maxEnvEvents:]
+	}
+	if err := writeEnvEvents(path, events); err != nil {
+		return err
+	}
+	s.eventLines[path] = len(events)
+	return nil
+}
+
+// OnEnvEvent registers fn to be called with every recorded status
+// transition, after it's persisted. fn runs with the store lock held, so it
+// must not call back into the Store. Call before serving.
+func (s *Store) OnEnvEvent(fn func(models.EnvEvent)) {
+	s.onEvent = fn
+}
+
+// ListEnvEvents returns the most recent transitions for an environment,
+// oldest first. limit <= 0 returns everything retained. Events outlive the
+// environment row so a destroyed env's history stays inspectable.
+func (s *Store) ListEnvEvents(projectID, branchSlug string, limit int) ([]models.EnvEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	events, err := readEnvEvents(s.eventsPath(projectID, branchSlug))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []models.EnvEvent{}, nil
+		}
+		return nil, err
+	}
+	if limit > 0 && len(events) > limit {
+		events = events[len(events)-limit:]
+	}
+	return events, nil
+}
+
+func readEnvEvents(path string) ([]models.EnvEvent, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	out := []models.EnvEvent{}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var ev models.EnvEvent
+		if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
+			continue // skip a torn trailing line from a crash mid-write
+		}
+		out = append(out, ev)
+	}
+	return out, sc.Err()
+}
+
+func writeEnvEvents(path string, events []models.EnvEvent) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for _, ev := range events {
+		if err := enc.Encode(ev); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -1,9 +1,15 @@
 package projects
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 )
 
 // DevDirExistsForBranch checks if the given branch's tree (in the local
@@ -19,16 +25,146 @@ func DevDirExistsForBranch(repoPath, branch string) bool {
 	return strings.TrimSpace(string(out)) != ""
 }
 
-// FetchOrigin runs `git fetch origin --prune` in repoPath. When token is
-// non-empty, it's injected via a one-off git credential helper so HTTPS
-// remotes (typically GitHub PATs from the credential store) authenticate
-// successfully. GIT_TERMINAL_PROMPT=0 is set unconditionally so a stale or
-// missing token fails fast with a clear error rather than hanging on a TTY
-// prompt.
+// FetchOrigin runs `git fetch origin --prune` in repoPath, authenticating
+// with token when non-empty (see gitAuth).
 //
 // Returns the combined output and any error. Best-effort: callers typically
 // log + continue.
 func FetchOrigin(repoPath, token string) ([]byte, error) {
+	return fetchOrigin(context.Background(), repoPath, token)
+}
+
+func fetchOrigin(ctx context.Context, repoPath, token string) ([]byte, error) {
+	args, env := gitAuth(token)
+	args = append(args, "fetch", "origin", "--prune")
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = repoPath
+	cmd.Env = env
+	return cmd.CombinedOutput()
+}
+
+// FetchRetry bounds FetchOriginWithRetry.
+type FetchRetry struct {
+	// Attempts is the total number of fetches tried. <= 1 disables retry.
+	Attempts int
+	// Timeout caps all attempts together, waits included. 0 = no cap.
+	Timeout time.Duration
+}
+
+// defaultFetchRetry fits inside a webhook delivery's response window.
+var defaultFetchRetry = FetchRetry{Attempts: 3, Timeout: 10 * time.Second}
+
+// SetFetchRetry overrides the FetchOriginWithRetry policy for this store.
+// Call before serving.
+func (s *Store) SetFetchRetry(p FetchRetry) {
+	s.fetchRetry = p
+}
+
+// ErrGitLocked means a repository has a git lock file left behind — most
+// often by a fetch that was killed when its deadline passed. Every git
+// command that needs the lock fails until it's removed, so it's reported
+// rather than retried.
+var ErrGitLocked = errors.New("repository is locked by a stale git lock file")
+
+// FetchOriginWithRetry is FetchOrigin retried with exponential backoff, for
+// transient failures like a remote that's briefly unavailable right after
+// a push. Auth and missing-repository failures are returned at once —
+// retrying can't fix them — as is ErrGitLocked, naming the lock files.
+// Returns the last attempt's output.
+func (s *Store) FetchOriginWithRetry(ctx context.Context, repoPath, token string) ([]byte, error) {
+	policy := s.fetchRetry
+	if policy.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, policy.Timeout)
+		defer cancel()
+	}
+	for n := 1; ; n++ {
+		out, err := fetchOrigin(ctx, repoPath, token)
+		if err == nil {
+			return out, nil
+		}
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		// A lock git complains about, or one the attempt just killed may
+		// have left behind.
+		if strings.Contains(string(out), ".lock': File exists") || ctx.Err() != nil {
+			if locks := gitLockFiles(repoPath); len(locks) > 0 {
+				return out, fmt.Errorf("git fetch failed after %d attempt(s): %w: %s", n, ErrGitLocked, strings.Join(locks, ", "))
+			}
+		}
+		if n >= policy.Attempts || ctx.Err() != nil || permanentFetchError(out) {
+			return out, fmt.Errorf("git fetch failed after %d attempt(s): %w", n, err)
+		}
+		select {
+		case <-ctx.Done():
+			return out, fmt.Errorf("git fetch failed after %d attempt(s): %w", n, ctx.Err())
+		case <-time.After(s.fetchRetryDelay << (n - 1)):
+		}
+	}
+}
+
+// gitLockFiles returns the *.lock files under repoPath's .git directory,
+// relative to repoPath. Object storage is skipped: a fetch doesn't leave
+// locks there, and it's by far the largest part of the tree.
+func gitLockFiles(repoPath string) []string {
+	var locks []string
+	gitDir := filepath.Join(repoPath, ".git")
+	_ = filepath.WalkDir(gitDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() && path == filepath.Join(gitDir, "objects") {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() && strings.HasSuffix(d.Name(), ".lock") {
+			if rel, err := filepath.Rel(repoPath, path); err == nil {
+				locks = append(locks, filepath.ToSlash(rel))
+			}
+		}
+		return nil
+	})
+	return locks
+}
+
+// permanentFetchErrors are git messages for failures a retry won't fix.
+var permanentFetchErrors = []string{
+	"Authentication failed",
+	"could not read Username",
+	"terminal prompts disabled",
+	"Repository not found",
+	"does not appear to be a git repository",
+}
+
+func permanentFetchError(out []byte) bool {
+	for _, msg := range permanentFetchErrors {
+		if strings.Contains(string(out), msg) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckRemote runs `git ls-remote --heads origin` in repoPath — the cheapest
+// round trip that proves the remote is reachable and the token (if any) is
+// accepted. Authentication is the same as FetchOrigin. Returns the combined
+// output and any error; a nil error means the remote answered.
+func CheckRemote(ctx context.Context, repoPath, token string) ([]byte, error) {
+	args, env := gitAuth(token)
+	args = append(args, "ls-remote", "--heads", "origin")
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = repoPath
+	cmd.Env = env
+	return cmd.CombinedOutput()
+}
+
+// gitAuth returns the leading git args and the process env for a
+// remote-touching git command. When token is non-empty, it's injected via a
+// one-off git credential helper so HTTPS remotes (typically GitHub PATs
+// from the credential store) authenticate. GIT_TERMINAL_PROMPT=0 is set
+// unconditionally so a stale or missing token fails fast rather than
+// hanging on a TTY prompt.
+func gitAuth(token string) ([]string, []string) {
 	var args []string
 	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
 
@@ -42,12 +178,7 @@ func FetchOrigin(repoPath, token string) ([]byte, error) {
 		)
 		env = append(env, "ENVM_GIT_TOKEN="+token)
 	}
-
-	args = append(args, "fetch", "origin", "--prune")
-	cmd := exec.Command("git", args...)
-	cmd.Dir = repoPath
-	cmd.Env = env
-	return cmd.CombinedOutput()
+	return args, env
 }
 
 // ListRemoteBranches returns the short names of remote branches under
@@ -74,3 +205,108 @@ func ListRemoteBranches(repoPath string) []string {
 	}
 	return branches
 }
+
+// GCRepo runs `git gc` in repoPath to repack loose objects and drop
+// unreachable ones. Long-lived clones that are fetched on every push
+// accumulate both. Local only — no remote round trip, so no auth. Returns
+// the combined output and any error.
+//
+// GC isn't serialized with webhook or reconcile fetches, so it keeps git's
+// default prune grace period: --prune=now could delete objects a
+// concurrent fetch has just written but not yet referenced.
+func GCRepo(ctx context.Context, repoPath string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", "gc", "--quiet")
+	cmd.Dir = repoPath
+	return cmd.CombinedOutput()
+}
+
+// GitDirSize returns the total size in bytes of repoPath/.git. Unreadable
+// entries are skipped; the result is for display, not accounting.
+func GitDirSize(repoPath string) int64 {
+	var total int64
+	_ = filepath.Walk(filepath.Join(repoPath, ".git"), func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
+
+// Divergence counts how far sha has drifted from origin/<branch> in the
+// local clone: ahead = commits in sha that the branch no longer has (a
+// force-push rewrote history), behind = commits on the branch not in sha
+// (pushed but not deployed). Reads local refs only — as fresh as the last
+// fetch. sha comes from a webhook payload, so anything but a hex commit
+// hash is rejected before it reaches git.
+func Divergence(repoPath, sha, branch string) (ahead, behind int, err error) {
+	if !shaPattern.MatchString(sha) {
+		return 0, 0, fmt.Errorf("%q is not a commit hash", sha)
+	}
+	cmd := exec.Command("git", "rev-list", "--left-right", "--count", sha+"...origin/"+branch)
+	cmd.Dir = repoPath
+	out, err := cmd.Output()
+	if err != nil {
+		return 0, 0, fmt.Errorf("git rev-list %s...origin/%s: %w", sha, branch, err)
+	}
+	if _, err := fmt.Sscanf(string(out), "%d %d", &ahead, &behind); err != nil {
+		return 0, 0, fmt.Errorf("parse rev-list output %q: %w", strings.TrimSpace(string(out)), err)
+	}
+	return ahead, behind, nil
+}
+
+// ErrRevisionNotFound is returned by FileAtRevision when the commit is
+// unknown to the local clone or the file doesn't exist in it.
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// FileRevision is one commit that touched a file.
+type FileRevision struct {
+	SHA     string    `json:"sha"`
+	Author  string    `json:"author"`
+	Date    time.Time `json:"date"`
+	Subject string    `json:"subject"`
+}
+
+// FileHistory lists the commits on origin/<branch> that touched path,
+// newest first, following renames. limit > 0 caps the count. Reads local
+// refs only — as fresh as the last fetch.
+func FileHistory(repoPath, branch, path string, limit int) ([]FileRevision, error) {
+	args := []string{"log", "--follow", "--format=%H%x1f%an%x1f%aI%x1f%s"}
+	if limit > 0 {
+		args = append(args, fmt.Sprintf("-n%d", limit))
+	}
+	args = append(args, "origin/"+branch, "--", path)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoPath
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log origin/%s -- %s: %w", branch, path, err)
+	}
+	revs := []FileRevision{}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		f := strings.SplitN(line, "\x1f", 4)
+		if len(f) != 4 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, f[2])
+		revs = append(revs, FileRevision{SHA: f[0], Author: f[1], Date: date, Subject: f[3]})
+	}
+	return revs, nil
+}
+
+var shaPattern = regexp.MustCompile(`^[0-9a-f]{4,64}$`)
+
+// FileAtRevision returns the content of path as of commit sha (full or
+// abbreviated hex). Returns ErrRevisionNotFound when either is missing.
+func FileAtRevision(repoPath, sha, path string) ([]byte, error) {
+	if !shaPattern.MatchString(sha) {
+		return nil, fmt.Errorf("%w: %q is not a commit hash", ErrRevisionNotFound, sha)
+	}
+	cmd := exec.Command("git", "show", sha+":"+path)
+	cmd.Dir = repoPath
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s at %s", ErrRevisionNotFound, path, sha)
+	}
+	return out, nil
+}
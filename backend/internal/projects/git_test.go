@@ -0,0 +1,177 @@
+package projects
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDivergence(t *testing.T) {
+	_, project, _ := setupReconcileFixture(t)
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = project.LocalPath
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	deployed := git("rev-parse", "HEAD")
+
+	if a, b, err := Divergence(project.LocalPath, deployed, "main"); err != nil || a != 0 || b != 0 {
+		t.Fatalf("in sync: ahead=%d behind=%d err=%v", a, b, err)
+	}
+
+	git("commit", "--allow-empty", "-m", "two")
+	git("commit", "--allow-empty", "-m", "three")
+	git("push", "origin", "main")
+	if a, b, err := Divergence(project.LocalPath, deployed, "main"); err != nil || a != 0 || b != 2 {
+		t.Errorf("after push: ahead=%d behind=%d err=%v, want 0/2", a, b, err)
+	}
+
+	// A deployed commit the branch doesn't have (history was rewritten).
+	git("reset", "--hard", deployed)
+	git("commit", "--allow-empty", "-m", "local only")
+	local := git("rev-parse", "HEAD")
+	if a, b, err := Divergence(project.LocalPath, local, "main"); err != nil || a != 1 || b != 2 {
+		t.Errorf("diverged: ahead=%d behind=%d err=%v", a, b, err)
+	}
+
+	if _, _, err := Divergence(project.LocalPath, "deadbeef", "main"); err == nil {
+		t.Error("expected an error for an unknown sha")
+	}
+	for _, bad := range []string{"--output=/tmp/x", "HEAD", ""} {
+		if _, _, err := Divergence(project.LocalPath, bad, "main"); err == nil || !strings.Contains(err.Error(), "not a commit hash") {
+			t.Errorf("sha %q: err = %v, want rejected before git runs", bad, err)
+		}
+	}
+}
+
+func TestFileHistory(t *testing.T) {
+	_, project, _ := setupReconcileFixture(t)
+	git := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = project.LocalPath
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	const file = ".dev/docker-compose.dev.yml"
+	first := git("rev-parse", "HEAD")
+	if err := os.WriteFile(filepath.Join(project.LocalPath, file), []byte("services:\n  web:\n    image: nginx\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("commit", "-am", "add web")
+	git("commit", "--allow-empty", "-m", "unrelated")
+	git("push", "origin", "main")
+
+	revs, err := FileHistory(project.LocalPath, "main", file, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revs) != 2 || revs[0].Subject != "add web" || revs[1].SHA != first {
+		t.Fatalf("history = %+v, want [add web, initial]", revs)
+	}
+	if revs[0].Author != "T" || revs[0].Date.IsZero() {
+		t.Errorf("revision metadata = %+v", revs[0])
+	}
+	if revs, _ := FileHistory(project.LocalPath, "main", file, 1); len(revs) != 1 {
+		t.Errorf("limit 1: got %d revisions", len(revs))
+	}
+
+	got, err := FileAtRevision(project.LocalPath, first[:12], file)
+	if err != nil || string(got) != "services:\n  app:\n    image: app\n" {
+		t.Errorf("at %s: %q, %v", first[:12], got, err)
+	}
+	for _, sha := range []string{"deadbeef", "--output=x", "HEAD"} {
+		if _, err := FileAtRevision(project.LocalPath, sha, file); !errors.Is(err, ErrRevisionNotFound) {
+			t.Errorf("FileAtRevision(%q) err = %v, want ErrRevisionNotFound", sha, err)
+		}
+	}
+}
+
+func TestFetchOriginWithRetry(t *testing.T) {
+	store, project, _ := setupReconcileFixture(t)
+	if _, err := store.FetchOriginWithRetry(context.Background(), project.LocalPath, ""); err != nil {
+		t.Fatalf("reachable origin: %v", err)
+	}
+
+	store.SetFetchRetry(FetchRetry{Attempts: 3, Timeout: 30 * time.Second})
+	store.fetchRetryDelay = 10 * time.Millisecond
+	setOrigin := func(url string) {
+		cmd := exec.Command("git", "remote", "set-url", "origin", url)
+		cmd.Dir = project.LocalPath
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("set-url: %v\n%s", err, out)
+		}
+	}
+
+	// Connection refused is transient: every attempt is used.
+	setOrigin("http://127.0.0.1:1/repo.git")
+	if _, err := store.FetchOriginWithRetry(context.Background(), project.LocalPath, ""); err == nil || !strings.Contains(err.Error(), "after 3 attempt(s)") {
+		t.Errorf("unreachable: err = %v, want failure after 3 attempts", err)
+	}
+
+	// A missing repository won't appear on retry.
+	setOrigin(filepath.Join(t.TempDir(), "missing.git"))
+	if _, err := store.FetchOriginWithRetry(context.Background(), project.LocalPath, ""); err == nil || !strings.Contains(err.Error(), "after 1 attempt(s)") {
+		t.Errorf("missing repo: err = %v, want failure after 1 attempt", err)
+	}
+}
+
+func TestFetchOriginWithRetry_PolicyIsPerStore(t *testing.T) {
+	a, project, _ := setupReconcileFixture(t)
+	b, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.SetFetchRetry(FetchRetry{Attempts: 1})
+	if b.fetchRetry != defaultFetchRetry {
+		t.Errorf("other store's policy = %+v, want the default", b.fetchRetry)
+	}
+
+	cmd := exec.Command("git", "remote", "set-url", "origin", "http://127.0.0.1:1/repo.git")
+	cmd.Dir = project.LocalPath
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("set-url: %v\n%s", err, out)
+	}
+	if _, err := a.FetchOriginWithRetry(context.Background(), project.LocalPath, ""); err == nil || !strings.Contains(err.Error(), "after 1 attempt(s)") {
+		t.Errorf("err = %v, want failure after the store's single attempt", err)
+	}
+}
+
+func TestFetchOriginWithRetry_ReportsStaleLock(t *testing.T) {
+	store, project, _ := setupReconcileFixture(t)
+	store.fetchRetryDelay = 10 * time.Millisecond
+	// What a fetch killed mid-ref-update leaves behind.
+	lock := filepath.Join(project.LocalPath, ".git", "refs", "remotes", "origin", "main.lock")
+	if err := os.WriteFile(lock, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("git", "commit", "--allow-empty", "-m", "two")
+	cmd.Dir = project.LocalPath
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("commit: %v\n%s", err, out)
+	}
+	cmd = exec.Command("git", "push", "origin", "HEAD:main")
+	cmd.Dir = project.LocalPath
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("push: %v\n%s", err, out)
+	}
+
+	_, err := store.FetchOriginWithRetry(context.Background(), project.LocalPath, "")
+	if !errors.Is(err, ErrGitLocked) {
+		t.Fatalf("err = %v, want ErrGitLocked", err)
+	}
+	if !strings.Contains(err.Error(), ".git/refs/remotes/origin/main.lock") || !strings.Contains(err.Error(), "after 1 attempt(s)") {
+		t.Errorf("err = %v, want the lock named on the first attempt", err)
+	}
+}
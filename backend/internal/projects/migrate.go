@@ -0,0 +1,84 @@
+package projects
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/environment-manager/backend/internal/models"
+)
+
+// projectMigrations[v] upgrades a project row from schema v to v+1.
+// v0 → v1 only introduces the version field itself, so there's nothing to
+// rewrite beyond the stamp SaveProject applies.
+var projectMigrations = map[int]func(*models.Project){
+	0: func(*models.Project) {},
+}
+
+// envMigrations is the Environment counterpart of projectMigrations.
+var envMigrations = map[int]func(*models.Environment){
+	0: func(*models.Environment) {},
+}
+
+// MigrateSchema upgrades every Project and Environment row older than
+// models.SchemaVersion, one version step at a time, and rewrites it. Run
+// once at boot before anything else reads the store. Rows written by a
+// NEWER server (version above current) are left untouched and logged — a
+// downgrade must not silently drop fields it doesn't know about.
+//
+// Returns the number of rows rewritten.
+func MigrateSchema(s *Store, logger *zap.Logger) (int, error) {
+	all, err := s.ListProjects()
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	for _, p := range all {
+		switch {
+		case p.SchemaVersion > models.SchemaVersion:
+			logger.Warn("project written by a newer schema; leaving as-is",
+				zap.String("project", p.ID),
+				zap.Int("schema_version", p.SchemaVersion),
+				zap.Int("supported", models.SchemaVersion))
+		case p.SchemaVersion < models.SchemaVersion:
+			from := p.SchemaVersion
+			for v := from; v < models.SchemaVersion; v++ {
+				projectMigrations[v](p)
+			}
+			if err := s.SaveProject(p); err != nil {
+				return count, err
+			}
+			logger.Info("migrated project schema",
+				zap.String("project", p.ID),
+				zap.Int("from", from),
+				zap.Int("to", models.SchemaVersion))
+			count++
+		}
+
+		envs, err := s.ListEnvironments(p.ID)
+		if err != nil {
+			return count, err
+		}
+		for _, e := range envs {
+			switch {
+			case e.SchemaVersion > models.SchemaVersion:
+				logger.Warn("environment written by a newer schema; leaving as-is",
+					zap.String("env_id", e.ID),
+					zap.Int("schema_version", e.SchemaVersion),
+					zap.Int("supported", models.SchemaVersion))
+			case e.SchemaVersion < models.SchemaVersion:
+				from := e.SchemaVersion
+				for v := from; v < models.SchemaVersion; v++ {
+					envMigrations[v](e)
+				}
+				if err := s.SaveEnvironment(e); err != nil {
+					return count, err
+				}
+				logger.Info("migrated environment schema",
+					zap.String("env_id", e.ID),
+					zap.Int("from", from),
+					zap.Int("to", models.SchemaVersion))
+				count++
+			}
+		}
+	}
+	return count, nil
+}
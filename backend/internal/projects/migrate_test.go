@@ -0,0 +1,64 @@
+package projects
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/environment-manager/backend/internal/models"
+)
+
+func TestMigrateSchema_UpgradesUnversionedRows(t *testing.T) {
+	dir := t.TempDir()
+	s, _ := NewStore(dir)
+	// Hand-write pre-versioning rows: no schema_version key.
+	projDir := filepath.Join(s.Root(), "p1")
+	if err := os.MkdirAll(filepath.Join(projDir, "environments"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.WriteFile(filepath.Join(projDir, "project.yaml"),
+		[]byte("id: p1\nname: myapp\nstatus: active\n"), 0644)
+	_ = os.WriteFile(filepath.Join(projDir, "environments", "main.yaml"),
+		[]byte("id: p1--main\nproject_id: p1\nbranch: main\nbranch_slug: main\nstatus: running\n"), 0644)
+
+	n, err := MigrateSchema(s, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("migrated = %d, want 2", n)
+	}
+	p, _ := s.GetProject("p1")
+	if p.SchemaVersion != models.SchemaVersion {
+		t.Errorf("project schema = %d, want %d", p.SchemaVersion, models.SchemaVersion)
+	}
+	e, _ := s.GetEnvironment("p1", "main")
+	if e.SchemaVersion != models.SchemaVersion || e.Status != models.EnvStatusRunning {
+		t.Errorf("env = %+v", e)
+	}
+
+	// Second run is a no-op.
+	if n, _ := MigrateSchema(s, zap.NewNop()); n != 0 {
+		t.Errorf("second run migrated %d rows, want 0", n)
+	}
+}
+
+func TestMigrateSchema_LeavesNewerRowsAlone(t *testing.T) {
+	dir := t.TempDir()
+	s, _ := NewStore(dir)
+	projDir := filepath.Join(s.Root(), "p1")
+	_ = os.MkdirAll(projDir, 0755)
+	raw := "id: p1\nname: myapp\nfuture_field: x\nschema_version: 99\n"
+	_ = os.WriteFile(filepath.Join(projDir, "project.yaml"), []byte(raw), 0644)
+
+	if n, err := MigrateSchema(s, zap.NewNop()); err != nil || n != 0 {
+		t.Fatalf("n=%d err=%v, want 0 rows and no error", n, err)
+	}
+	got, _ := os.ReadFile(filepath.Join(projDir, "project.yaml"))
+	if !strings.Contains(string(got), "future_field") {
+		t.Error("newer-schema row was rewritten")
+	}
+}
@@ -2,6 +2,7 @@ package projects
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"go.uber.org/zap"
@@ -34,70 +35,102 @@ func ReconcileBranches(ctx context.Context, store *Store, spawner EnvSpawner, fa
 	}
 	var summaries []string
 	for _, p := range allProjects {
-		if p.LocalPath == "" || p.RepoURL == "" {
-			// Legacy migrated projects (no repo): skip.
-			continue
-		}
 		token := ""
 		if gitToken != nil {
 			token = gitToken()
 		}
-		if out, err := FetchOrigin(p.LocalPath, token); err != nil {
-			logger.Warn("git fetch failed during reconcile",
-				zap.String("project", p.ID),
-				zap.String("out", string(out)),
-				zap.Error(err))
-			// continue — we still attempt the local→remote diff with stale data
-		}
+		summaries = append(summaries, ReconcileProject(ctx, store, spawner, p, logger, token)...)
+	}
+	return summaries, nil
+}
 
-		remoteBranches := ListRemoteBranches(p.LocalPath)
-		remoteSet := make(map[string]bool, len(remoteBranches))
-		for _, b := range remoteBranches {
-			remoteSet[b] = true
-		}
+// ReconcileProject is ReconcileBranches for a single project: fetch origin
+// with token, then converge p's envs to its remote branches. Returns one
+// summary line per change. Legacy projects without a repo are skipped.
+func ReconcileProject(ctx context.Context, store *Store, spawner EnvSpawner, p *models.Project, logger *zap.Logger, token string) []string {
+	if p.LocalPath == "" || p.RepoURL == "" {
+		// Legacy migrated projects (no repo): skip.
+		return nil
+	}
+	var summaries []string
+	if out, err := store.FetchOriginWithRetry(ctx, p.LocalPath, token); err != nil {
+		logger.Warn("git fetch failed during reconcile",
+			zap.String("project", p.ID),
+			zap.String("out", string(out)),
+			zap.Error(err))
+		// continue — we still attempt the local→remote diff with stale data
+		summaries = append(summaries, p.ID+": "+err.Error()+"; reconciled against the last fetched branches")
+	}
 
-		// 1. Tear down envs whose branch is gone (skip prod and legacy).
-		envs, _ := store.ListEnvironments(p.ID)
-		for _, e := range envs {
-			if e.Kind == models.EnvKindProd || e.Kind == models.EnvKindLegacy {
+	remoteBranches := ListRemoteBranches(p.LocalPath)
+	remoteSet := make(map[string]bool, len(remoteBranches))
+	for _, b := range remoteBranches {
+		remoteSet[b] = true
+	}
+
+	// 1. Tear down envs whose branch is gone (skip prod and legacy).
+	envs, _ := store.ListEnvironments(p.ID)
+	for _, e := range envs {
+		if e.Kind == models.EnvKindProd || e.Kind == models.EnvKindLegacy {
+			continue
+		}
+		if !remoteSet[e.Branch] {
+			logger.Info("reconcile: branch gone, tearing down",
+				zap.String("project", p.ID),
+				zap.String("branch", e.Branch))
+			if err := spawner.Teardown(ctx, e); err != nil {
+				logger.Warn("reconcile teardown failed", zap.Error(err))
 				continue
 			}
-			if !remoteSet[e.Branch] {
-				logger.Info("reconcile: branch gone, tearing down",
+			if err := store.DeleteEnvironment(p.ID, e.BranchSlug); err != nil {
+				logger.Error("reconcile: delete env record failed",
 					zap.String("project", p.ID),
-					zap.String("branch", e.Branch))
-				if err := spawner.Teardown(ctx, e); err != nil {
-					logger.Warn("reconcile teardown failed", zap.Error(err))
-					continue
-				}
-				_ = store.DeleteEnvironment(p.ID, e.BranchSlug)
-				summaries = append(summaries, p.ID+": tore down "+e.BranchSlug)
+					zap.String("branch", e.BranchSlug),
+					zap.Error(err))
 			}
+			summaries = append(summaries, p.ID+": tore down "+e.BranchSlug)
 		}
+	}
 
-		// 2. Spawn envs for new branches with .dev/.
-		for _, branch := range remoteBranches {
-			slug, err := BranchSlug(branch)
-			if err != nil {
-				continue
-			}
-			if _, err := store.GetEnvironment(p.ID, slug); err == nil {
-				continue // env already exists
-			}
-			if !DevDirExistsForBranch(p.LocalPath, branch) {
-				continue
+	// 2. Spawn envs for new branches with .dev/.
+	for _, branch := range remoteBranches {
+		slug, err := BranchSlug(branch)
+		if err != nil {
+			continue
+		}
+		if _, err := store.GetEnvironment(p.ID, slug); err == nil {
+			continue // env already exists
+		}
+		if !DevDirExistsForBranch(p.LocalPath, branch) {
+			continue
+		}
+		// Same gate as a webhook push: a broken compose file isn't deployed.
+		composeFile := ".dev/docker-compose.dev.yml"
+		if branch == p.DefaultBranch {
+			composeFile = ".dev/docker-compose.prod.yml"
+		}
+		if verr := ValidateBranchCompose(p.LocalPath, branch, composeFile); verr != nil {
+			msg := "reconcile: compose file is invalid; not spawning"
+			if !errors.Is(verr, ErrInvalidCompose) {
+				msg = "reconcile: could not read compose file; not spawning"
 			}
-			logger.Info("reconcile: spawning missing env",
+			logger.Warn(msg,
 				zap.String("project", p.ID),
-				zap.String("branch", branch))
-			if err := spawner.SpawnPreview(ctx, p, branch, slug); err != nil {
-				logger.Warn("reconcile spawn failed", zap.Error(err))
-				continue
-			}
-			summaries = append(summaries, p.ID+": spawned "+slug)
+				zap.String("branch", branch),
+				zap.Error(verr))
+			summaries = append(summaries, p.ID+": skipped "+slug+": "+verr.Error())
+			continue
+		}
+		logger.Info("reconcile: spawning missing env",
+			zap.String("project", p.ID),
+			zap.String("branch", branch))
+		if err := spawner.SpawnPreview(ctx, p, branch, slug); err != nil {
+			logger.Warn("reconcile spawn failed", zap.Error(err))
+			continue
 		}
+		summaries = append(summaries, p.ID+": spawned "+slug)
 	}
-	return summaries, nil
+	return summaries
 }
 
 // MarkStuckBuildsFailed scans every project's builds and rewrites any with
@@ -8,13 +8,15 @@ import (
 	"testing"
 
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 
 	"github.com/environment-manager/backend/internal/models"
 )
 
 type fakeSpawner struct {
-	spawned  []string // branch names
-	tornDown []string // env IDs
+	spawned    []string // branch names
+	tornDown   []string // env IDs
+	onTeardown func(env *models.Environment)
 }
 
 func (f *fakeSpawner) SpawnPreview(ctx context.Context, project *models.Project, branch, slug string) error {
@@ -24,6 +26,9 @@ func (f *fakeSpawner) SpawnPreview(ctx context.Context, project *models.Project,
 
 func (f *fakeSpawner) Teardown(ctx context.Context, env *models.Environment) error {
 	f.tornDown = append(f.tornDown, env.ID)
+	if f.onTeardown != nil {
+		f.onTeardown(env)
+	}
 	return nil
 }
 
@@ -55,8 +60,8 @@ func setupReconcileFixture(t *testing.T) (*Store, *models.Project, string) {
 	}
 	for f, c := range map[string]string{
 		".dev/Dockerfile.dev":          "FROM alpine\n",
-		".dev/docker-compose.prod.yml": "services: {}\n",
-		".dev/docker-compose.dev.yml":  "services: {}\n",
+		".dev/docker-compose.prod.yml": "services:\n  app:\n    image: app\n",
+		".dev/docker-compose.dev.yml":  "services:\n  app:\n    image: app\n",
 		".dev/config.yaml":             "project_name: r\n",
 	} {
 		if err := os.WriteFile(filepath.Join(workdir, f), []byte(c), 0644); err != nil {
@@ -118,6 +123,42 @@ func TestReconcileBranches_SpawnsForNewRemoteBranch(t *testing.T) {
 	}
 }
 
+func TestReconcileBranches_SkipsInvalidCompose(t *testing.T) {
+	store, project, upstream := setupReconcileFixture(t)
+
+	workdir2 := filepath.Join(filepath.Dir(project.LocalPath), "work2")
+	runIn := func(dir string, args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	runIn(filepath.Dir(project.LocalPath), "clone", upstream, workdir2)
+	runIn(workdir2, "config", "user.email", "t@t")
+	runIn(workdir2, "config", "user.name", "T")
+	runIn(workdir2, "checkout", "-b", "broken")
+	if err := os.WriteFile(filepath.Join(workdir2, ".dev/docker-compose.dev.yml"), []byte("services:\n  app:\n    ports: ['80:80']\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runIn(workdir2, "commit", "-am", "drop image")
+	runIn(workdir2, "push", "origin", "broken")
+
+	spawner := &fakeSpawner{}
+	summaries, err := ReconcileBranches(context.Background(), store, spawner, "home", zap.NewNop(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range spawner.spawned {
+		if b == "broken" {
+			t.Errorf("spawned a branch with an invalid compose file; summaries=%v", summaries)
+		}
+	}
+	if _, err := store.GetEnvironment("p1", "broken"); err == nil {
+		t.Error("env saved for an invalid compose file")
+	}
+}
+
 func TestReconcileBranches_TearsDownGoneBranch(t *testing.T) {
 	store, project, _ := setupReconcileFixture(t)
 
@@ -144,6 +185,45 @@ func TestReconcileBranches_TearsDownGoneBranch(t *testing.T) {
 	_ = project
 }
 
+func TestReconcileBranches_DeleteFailureIsLogged(t *testing.T) {
+	store, _, _ := setupReconcileFixture(t)
+	ghost := &models.Environment{
+		ID: "p1--ghost", ProjectID: "p1",
+		Branch: "ghost", BranchSlug: "ghost",
+		Kind: models.EnvKindPreview, Status: models.EnvStatusRunning,
+	}
+	_ = store.SaveEnvironment(ghost)
+
+	// Once torn down, swap the record for a non-empty directory so the
+	// store can't remove it.
+	spawner := &fakeSpawner{onTeardown: func(env *models.Environment) {
+		path := store.envPath(env.ProjectID, env.BranchSlug)
+		if err := os.Remove(path); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Join(path, "keep"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}}
+	core, logs := observer.New(zap.ErrorLevel)
+	summaries, err := ReconcileBranches(context.Background(), store, spawner, "home", zap.New(core), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entries := logs.FilterMessage("reconcile: delete env record failed").All()
+	if len(entries) != 1 {
+		t.Fatalf("got %d delete-failure logs, want 1: %v", len(entries), logs.All())
+	}
+	if f := entries[0].ContextMap(); f["project"] != "p1" || f["branch"] != "ghost" || f["error"] == nil {
+		t.Errorf("log fields = %v, want project, branch and error", f)
+	}
+	// The env is gone from docker either way, so the teardown still counts.
+	if len(summaries) == 0 || summaries[0] != "p1: tore down ghost" {
+		t.Errorf("summaries = %v, want the teardown reported", summaries)
+	}
+}
+
 func TestReconcileBranches_ProdExempt(t *testing.T) {
 	store, project, _ := setupReconcileFixture(t)
 
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"gopkg.in/yaml.v3"
 
@@ -16,11 +17,24 @@ import (
 // ErrNotFound is returned when an entity does not exist on disk.
 var ErrNotFound = errors.New("not found")
 
+// ErrNewerSchema is returned when saving a row read with a SchemaVersion
+// above models.SchemaVersion. This binary's structs would drop the fields
+// the newer layout added, so the row is left as the newer server wrote it.
+var ErrNewerSchema = errors.New("row was written by a newer schema version")
+
 // Store persists Projects, Environments, and Builds under {root}/projects/.
 // One directory per project; environments and builds nest underneath.
 type Store struct {
-	root string
-	mu   sync.RWMutex
+	root    string
+	mu      sync.RWMutex
+	onEvent func(models.EnvEvent) // nil = no listener
+	// eventLines counts the lines in each events file, keyed by path, so
+	// appendEnvEvent only reads a file back when compacting. Filled lazily.
+	eventLines map[string]int
+	// fetchRetry bounds FetchOriginWithRetry; fetchRetryDelay is the wait
+	// before its second attempt, doubling per attempt.
+	fetchRetry      FetchRetry
+	fetchRetryDelay time.Duration
 }
 
 // NewStore creates the projects root if missing and returns a ready Store.
@@ -29,7 +43,7 @@ func NewStore(dataDir string) (*Store, error) {
 	if err := os.MkdirAll(root, 0755); err != nil {
 		return nil, fmt.Errorf("mkdir projects root: %w", err)
 	}
-	return &Store{root: root}, nil
+	return &Store{root: root, fetchRetry: defaultFetchRetry, fetchRetryDelay: time.Second}, nil
 }
 
 // Root returns the directory used by this store. For tests + diagnostics.
@@ -39,7 +53,10 @@ func (s *Store) projectPath(id string) string {
 	return filepath.Join(s.root, id, "project.yaml")
 }
 
-// SaveProject writes the project metadata, creating the project dir.
+// SaveProject writes the project metadata, creating the project dir. The row
+// is stamped with the current models.SchemaVersion. A row read with a newer
+// version is refused with ErrNewerSchema rather than rewritten without the
+// fields this binary doesn't know.
 func (s *Store) SaveProject(p *models.Project) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -47,6 +64,10 @@ func (s *Store) SaveProject(p *models.Project) error {
 	if p.ID == "" {
 		return errors.New("project ID required")
 	}
+	if p.SchemaVersion > models.SchemaVersion {
+		return fmt.Errorf("project %s: %w", p.ID, ErrNewerSchema)
+	}
+	p.SchemaVersion = models.SchemaVersion
 	dir := filepath.Join(s.root, p.ID)
 	if err := os.MkdirAll(filepath.Join(dir, "environments"), 0755); err != nil {
 		return err
@@ -124,7 +145,11 @@ func (s *Store) envPath(projectID, branchSlug string) string {
 	return filepath.Join(s.root, projectID, "environments", branchSlug+".yaml")
 }
 
-// SaveEnvironment writes an environment to disk under its project.
+// SaveEnvironment writes an environment to disk under its project. When the
+// status differs from the previously persisted one, the transition is
+// appended to the env's event history (see ListEnvEvents). Like projects,
+// the row is stamped with the current models.SchemaVersion, and one read
+// with a newer version is refused with ErrNewerSchema.
 func (s *Store) SaveEnvironment(e *models.Environment) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -132,15 +157,33 @@ func (s *Store) SaveEnvironment(e *models.Environment) error {
 	if e.ProjectID == "" || e.BranchSlug == "" {
 		return errors.New("environment ProjectID and BranchSlug required")
 	}
+	if e.SchemaVersion > models.SchemaVersion {
+		return fmt.Errorf("environment %s: %w", e.ID, ErrNewerSchema)
+	}
+	e.SchemaVersion = models.SchemaVersion
 	dir := filepath.Join(s.root, e.ProjectID, "environments")
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
 	}
+	var prevStatus models.EnvironmentStatus
+	if prev, err := os.ReadFile(s.envPath(e.ProjectID, e.BranchSlug)); err == nil {
+		var p models.Environment
+		if yaml.Unmarshal(prev, &p) == nil {
+			prevStatus = p.Status
+		}
+	}
 	data, err := yaml.Marshal(e)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(s.envPath(e.ProjectID, e.BranchSlug), data, 0644)
+	if err := os.WriteFile(s.envPath(e.ProjectID, e.BranchSlug), data, 0644); err != nil {
+		return err
+	}
+	if e.Status != prevStatus {
+		// History is best-effort — never fail the primary write over it.
+		_ = s.appendEnvEvent(e, prevStatus)
+	}
+	return nil
 }
 
 // GetEnvironment loads an environment by project ID and branch slug.
@@ -250,3 +250,112 @@ func TestStore_GetProjectByRepoURL(t *testing.T) {
 		t.Errorf("expected ErrNotFound, got %v", err)
 	}
 }
+
+func TestStore_SaveEnvironment_RecordsStatusTransitions(t *testing.T) {
+	s := newTestStore(t)
+	env := &models.Environment{ID: "p1--main", ProjectID: "p1", BranchSlug: "main", Status: models.EnvStatusPending}
+	for _, st := range []models.EnvironmentStatus{
+		models.EnvStatusPending, // first save: "" → pending
+		models.EnvStatusPending, // unchanged: no event
+		models.EnvStatusBuilding,
+		models.EnvStatusFailed,
+	} {
+		env.Status = st
+		if err := s.SaveEnvironment(env); err != nil {
+			t.Fatal(err)
+		}
+	}
+	events, err := s.ListEnvEvents("p1", "main", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3: %+v", len(events), events)
+	}
+	last := events[2]
+	if last.From != models.EnvStatusBuilding || last.To != models.EnvStatusFailed || last.EnvID != "p1--main" {
+		t.Errorf("last event = %+v", last)
+	}
+
+	limited, _ := s.ListEnvEvents("p1", "main", 1)
+	if len(limited) != 1 || limited[0].To != models.EnvStatusFailed {
+		t.Errorf("limit=1 should return the newest event, got %+v", limited)
+	}
+}
+
+func TestStore_OnEnvEvent(t *testing.T) {
+	s := newTestStore(t)
+	var got []models.EnvEvent
+	s.OnEnvEvent(func(ev models.EnvEvent) { got = append(got, ev) })
+	env := &models.Environment{ID: "p1--main", ProjectID: "p1", BranchSlug: "main", Status: models.EnvStatusPending}
+	_ = s.SaveEnvironment(env)
+	_ = s.SaveEnvironment(env) // unchanged: no callback
+	env.Status = models.EnvStatusRunning
+	_ = s.SaveEnvironment(env)
+	if len(got) != 2 || got[1].From != models.EnvStatusPending || got[1].To != models.EnvStatusRunning {
+		t.Errorf("callbacks = %+v, want 2 ending pending → running", got)
+	}
+}
+
+func TestStore_ListEnvEvents_Missing(t *testing.T) {
+	s := newTestStore(t)
+	events, err := s.ListEnvEvents("nope", "main", 10)
+	if err != nil || len(events) != 0 {
+		t.Errorf("got %v, %v; want empty, nil", events, err)
+	}
+}
+
+func TestStore_EnvEvents_Compacted(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := &models.Environment{ID: "p1--main", ProjectID: "p1", BranchSlug: "main", Status: models.EnvStatusRunning}
+	for i := 0; i < maxEnvEvents; i++ {
+		if err := s.appendEnvEvent(env, models.EnvStatusBuilding); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A restarted store picks up the existing line count.
+	s, err = NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < maxEnvEvents; i++ {
+		if err := s.appendEnvEvent(env, models.EnvStatusBuilding); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if events, _ := s.ListEnvEvents("p1", "main", 0); len(events) != 2*maxEnvEvents {
+		t.Fatalf("got %d events before the cap, want %d", len(events), 2*maxEnvEvents)
+	}
+	if err := s.appendEnvEvent(env, models.EnvStatusBuilding); err != nil {
+		t.Fatal(err)
+	}
+	if events, _ := s.ListEnvEvents("p1", "main", 0); len(events) != maxEnvEvents {
+		t.Errorf("got %d events after compaction, want %d", len(events), maxEnvEvents)
+	}
+}
+
+func TestStore_SaveRefusesNewerSchemaVersion(t *testing.T) {
+	s := newTestStore(t)
+	newer := models.SchemaVersion + 1
+	if err := s.SaveProject(&models.Project{ID: "p1", Name: "myapp", SchemaVersion: newer}); !errors.Is(err, ErrNewerSchema) {
+		t.Errorf("SaveProject err = %v, want ErrNewerSchema", err)
+	}
+	if _, err := s.GetProject("p1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("refused project was written: %v", err)
+	}
+	if err := s.SaveEnvironment(&models.Environment{ID: "p1--main", ProjectID: "p1", BranchSlug: "main", SchemaVersion: newer}); !errors.Is(err, ErrNewerSchema) {
+		t.Errorf("SaveEnvironment err = %v, want ErrNewerSchema", err)
+	}
+
+	// Rows from before versioning are still stamped.
+	if err := s.SaveProject(&models.Project{ID: "p2", Name: "other"}); err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := s.GetProject("p2"); p.SchemaVersion != models.SchemaVersion {
+		t.Errorf("unversioned project schema = %d, want %d", p.SchemaVersion, models.SchemaVersion)
+	}
+}
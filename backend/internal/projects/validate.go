@@ -0,0 +1,82 @@
+package projects
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidCompose wraps the errors from ValidateBranchCompose that blame
+// the compose file itself, so callers can tell them apart from a git
+// failure.
+var ErrInvalidCompose = errors.New("invalid compose file")
+
+// ValidateBranchCompose checks the compose file at composeFile (repo
+// relative, e.g. ".dev/docker-compose.prod.yml") as it exists on
+// origin/<branch> in the local clone — the fetched tree, read with git, so
+// nothing is checked out. The file must be YAML with a non-empty services
+// mapping (or a top-level include:), and each service must declare an
+// image, a build, or extends. It's a cheap syntax gate, not a full compose
+// schema check: it catches the edits that would otherwise fail opaquely
+// mid-deploy.
+//
+// A file missing from the branch is an ErrInvalidCompose. A git failure
+// (unknown ref, broken clone) is returned without it: the push may be
+// fine, the local clone just can't say.
+func ValidateBranchCompose(repoPath, branch, composeFile string) error {
+	// ls-tree succeeds with no output when the path is absent, so a
+	// missing file is told apart from git itself failing.
+	cmd := exec.Command("git", "ls-tree", "origin/"+branch, "--", composeFile)
+	cmd.Dir = repoPath
+	out, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("git ls-tree origin/%s: %w", branch, err)
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) < 3 || fields[1] != "blob" {
+		return fmt.Errorf("%w: %s not found on %s", ErrInvalidCompose, composeFile, branch)
+	}
+	cmd = exec.Command("git", "cat-file", "blob", fields[2])
+	cmd.Dir = repoPath
+	data, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("git cat-file %s: %w", fields[2], err)
+	}
+	return validateCompose(data)
+}
+
+func validateCompose(data []byte) error {
+	var doc struct {
+		Services map[string]yaml.Node `yaml:"services"`
+		Include  yaml.Node            `yaml:"include"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCompose, err)
+	}
+	// Services can come entirely from top-level include: files, which
+	// aren't fetched here — only a file with neither is empty.
+	if len(doc.Services) == 0 && doc.Include.Kind == 0 {
+		return fmt.Errorf("%w: no services defined", ErrInvalidCompose)
+	}
+	for name, svc := range doc.Services {
+		if svc.Kind != yaml.MappingNode {
+			return fmt.Errorf("%w: service %q is not a mapping", ErrInvalidCompose, name)
+		}
+		var fields struct {
+			Image   string    `yaml:"image"`
+			Build   yaml.Node `yaml:"build"`
+			Extends yaml.Node `yaml:"extends"`
+		}
+		if err := svc.Decode(&fields); err != nil {
+			return fmt.Errorf("%w: service %q: %v", ErrInvalidCompose, name, err)
+		}
+		// extends: inherits image/build from the base service.
+		if strings.TrimSpace(fields.Image) == "" && fields.Build.Kind == 0 && fields.Extends.Kind == 0 {
+			return fmt.Errorf("%w: service %q has neither image nor build", ErrInvalidCompose, name)
+		}
+	}
+	return nil
+}
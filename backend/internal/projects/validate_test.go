@@ -0,0 +1,53 @@
+package projects
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateCompose(t *testing.T) {
+	cases := []struct {
+		name, data string
+		ok         bool
+	}{
+		{"image", "services:\n  app:\n    image: nginx\n", true},
+		{"build", "services:\n  app:\n    build: .\n", true},
+		{"build mapping", "services:\n  app:\n    build:\n      context: .\n", true},
+		{"extends only", "services:\n  base:\n    image: app\n  worker:\n    extends: base\n", true},
+		{"extends other file", "services:\n  web:\n    extends:\n      file: common.yml\n      service: web\n", true},
+		{"include only", "include:\n  - common.yml\n", true},
+		{"include plus services", "include:\n  - db.yml\nservices:\n  app:\n    image: app\n", true},
+		{"bad yaml", "services:\n  app:\n    image: [x\n", false},
+		{"no services", "version: '3'\n", false},
+		{"service not mapping", "services:\n  app: nginx\n", false},
+		{"no image or build", "services:\n  app:\n    ports: ['80:80']\n", false},
+	}
+	for _, c := range cases {
+		err := validateCompose([]byte(c.data))
+		if c.ok && err != nil {
+			t.Errorf("%s: unexpected error %v", c.name, err)
+		}
+		if !c.ok && !errors.Is(err, ErrInvalidCompose) {
+			t.Errorf("%s: err = %v, want ErrInvalidCompose", c.name, err)
+		}
+	}
+}
+
+func TestValidateBranchCompose(t *testing.T) {
+	_, project, _ := setupReconcileFixture(t)
+	if err := ValidateBranchCompose(project.LocalPath, "main", ".dev/docker-compose.prod.yml"); err != nil {
+		t.Errorf("valid file: %v", err)
+	}
+	// A file the branch doesn't have blames the compose file.
+	if err := ValidateBranchCompose(project.LocalPath, "main", ".dev/missing.yml"); !errors.Is(err, ErrInvalidCompose) {
+		t.Errorf("missing file: err = %v, want ErrInvalidCompose", err)
+	}
+	// A directory is not a compose file either.
+	if err := ValidateBranchCompose(project.LocalPath, "main", ".dev"); !errors.Is(err, ErrInvalidCompose) {
+		t.Errorf("directory: err = %v, want ErrInvalidCompose", err)
+	}
+	// A ref the clone doesn't have is a git failure, not a bad file.
+	if err := ValidateBranchCompose(project.LocalPath, "unfetched", ".dev/docker-compose.prod.yml"); err == nil || errors.Is(err, ErrInvalidCompose) {
+		t.Errorf("unknown ref: err = %v, want a git error without ErrInvalidCompose", err)
+	}
+}
@@ -24,6 +24,7 @@ import (
 type Manager struct {
 	basePath    string
 	credentials *credentials.Store
+	cloneDepth  int
 	mu          sync.RWMutex
 }
 
@@ -46,6 +47,13 @@ func (m *Manager) Credentials() *credentials.Store {
 	return m.credentials
 }
 
+// SetCloneDepth makes Clone fetch only the latest depth commits of each
+// branch. 0 (the default) clones full history. Later `git fetch`es keep
+// the clone shallow, so long-lived repos stay small.
+func (m *Manager) SetCloneDepth(depth int) {
+	m.cloneDepth = depth
+}
+
 // headSHA reads the current HEAD commit SHA of a local clone. Returns the
 // short form (first 7 chars) for display.
 func headSHA(localPath string) string {
@@ -103,6 +111,7 @@ func (m *Manager) Clone(ctx context.Context, req models.CloneRequest) (*models.R
 	cloneOpts := &git.CloneOptions{
 		URL:      req.URL,
 		Progress: nil,
+		Depth:    m.cloneDepth,
 	}
 
 	// Set branch if specified
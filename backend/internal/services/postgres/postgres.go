@@ -46,6 +46,7 @@ type RunSpec struct {
 	Env     map[string]string
 	Cmd     []string
 	Labels  map[string]string
+	Init    bool
 }
 
 // Docker is the minimal subset of docker.Client behaviour the provisioner needs.
@@ -81,6 +82,7 @@ type Provisioner struct {
 	logger      *zap.Logger
 	passwordGen func() (string, error)
 	now         func() time.Time
+	init        bool
 }
 
 // New constructs a Provisioner with sensible defaults.
@@ -97,6 +99,10 @@ func New(d Docker, creds CredStore, logger *zap.Logger) *Provisioner {
 	}
 }
 
+// SetInit makes EnsureService create the container with an init process
+// (tini) as PID 1. Off by default; wired to DEFAULT_INIT.
+func (p *Provisioner) SetInit(on bool) { p.init = on }
+
 // defaultPasswordGen returns 24 random bytes hex-encoded (48 chars).
 func defaultPasswordGen() (string, error) {
 	buf := make([]byte, defaultPwBytes)
@@ -160,6 +166,7 @@ func (p *Provisioner) EnsureService(ctx context.Context) error {
 		Name:    ContainerName,
 		Image:   Image,
 		Network: NetworkName,
+		Init:    p.init,
 		Volumes: map[string]string{VolumeName: MountPath},
 		Env:     map[string]string{"POSTGRES_PASSWORD": pw},
 		Labels: map[string]string{
@@ -196,6 +196,9 @@ func TestEnsureService_FreshBoot(t *testing.T) {
 	if spec.Labels["env-manager.singleton"] != "postgres" {
 		t.Errorf("singleton label missing")
 	}
+	if spec.Init {
+		t.Errorf("Init set; it is opt-in via SetInit")
+	}
 	// Superuser password persisted
 	saved, err := fc.GetSystemSecret(SuperuserKey)
 	if err != nil || saved != spec.Env["POSTGRES_PASSWORD"] {
@@ -207,6 +210,22 @@ func TestEnsureService_FreshBoot(t *testing.T) {
 	}
 }
 
+func TestEnsureService_InitOptIn(t *testing.T) {
+	fd := &fakeDocker{
+		statuses:    map[string]containerState{},
+		execResults: []execResult{{exitCode: 0}},
+	}
+	p := newTestProvisioner(t, fd, newFakeCreds())
+	p.SetInit(true)
+
+	if err := p.EnsureService(context.Background()); err != nil {
+		t.Fatalf("EnsureService: %v", err)
+	}
+	if len(fd.runCalls) != 1 || !fd.runCalls[0].Init {
+		t.Errorf("run calls = %+v, want one with Init set", fd.runCalls)
+	}
+}
+
 func TestEnsureService_ReusesStoredPassword(t *testing.T) {
 	fd := &fakeDocker{
 		statuses:    map[string]containerState{},
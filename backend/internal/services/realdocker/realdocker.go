@@ -50,6 +50,7 @@ func (a *PostgresAdapter) RunContainer(ctx context.Context, spec postgres.RunSpe
 		Env:     spec.Env,
 		Cmd:     spec.Cmd,
 		Labels:  spec.Labels,
+		Init:    spec.Init,
 	})
 }
 
@@ -88,5 +89,6 @@ func (a *RedisAdapter) RunContainer(ctx context.Context, spec redis.RunSpec) err
 		Env:     spec.Env,
 		Cmd:     spec.Cmd,
 		Labels:  spec.Labels,
+		Init:    spec.Init,
 	})
 }
@@ -37,6 +37,7 @@ type RunSpec struct {
 	Env     map[string]string
 	Cmd     []string
 	Labels  map[string]string
+	Init    bool
 }
 
 // Docker is the minimal docker.Client subset the provisioner needs.
@@ -71,6 +72,7 @@ type Provisioner struct {
 	logger      *zap.Logger
 	passwordGen func() (string, error)
 	now         func() time.Time
+	init        bool
 }
 
 func New(d Docker, creds CredStore, logger *zap.Logger) *Provisioner {
@@ -86,6 +88,10 @@ func New(d Docker, creds CredStore, logger *zap.Logger) *Provisioner {
 	}
 }
 
+// SetInit makes EnsureService create the container with an init process
+// (tini) as PID 1. Off by default; wired to DEFAULT_INIT.
+func (p *Provisioner) SetInit(on bool) { p.init = on }
+
 func defaultPasswordGen() (string, error) {
 	buf := make([]byte, defaultPwBytes)
 	if _, err := rand.Read(buf); err != nil {
@@ -147,6 +153,7 @@ func (p *Provisioner) EnsureService(ctx context.Context) error {
 		Name:    ContainerName,
 		Image:   Image,
 		Network: NetworkName,
+		Init:    p.init,
 		Volumes: map[string]string{VolumeName: MountPath},
 		Cmd:     []string{"redis-server", "--requirepass", pw},
 		Labels: map[string]string{
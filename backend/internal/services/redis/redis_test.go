@@ -209,6 +209,9 @@ func TestRedisEnsureService_FreshBoot(t *testing.T) {
 	if spec.Labels["env-manager.singleton"] != "redis" {
 		t.Errorf("singleton label missing or wrong: got %q", spec.Labels["env-manager.singleton"])
 	}
+	if spec.Init {
+		t.Errorf("Init set; it is opt-in via SetInit")
+	}
 	if spec.Volumes[VolumeName] != MountPath {
 		t.Errorf("volume mount wrong: %v", spec.Volumes)
 	}
@@ -225,6 +228,22 @@ func TestRedisEnsureService_FreshBoot(t *testing.T) {
 	}
 }
 
+func TestRedisEnsureService_InitOptIn(t *testing.T) {
+	fd := &fakeDocker{
+		statuses:    map[string]containerState{},
+		execResults: []execResult{{stdout: "PONG", exitCode: 0}},
+	}
+	p := newTestProvisioner(t, fd, newFakeCreds())
+	p.SetInit(true)
+
+	if err := p.EnsureService(context.Background()); err != nil {
+		t.Fatalf("EnsureService: %v", err)
+	}
+	if len(fd.runCalls) != 1 || !fd.runCalls[0].Init {
+		t.Errorf("run calls = %+v, want one with Init set", fd.runCalls)
+	}
+}
+
 func TestRedisEnsureService_RunningIsNoop(t *testing.T) {
 	fd := &fakeDocker{
 		statuses:    map[string]containerState{ContainerName: {exists: true, running: true}},
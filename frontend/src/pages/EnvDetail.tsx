@@ -7,6 +7,7 @@ import {
   listBuildsForEnv,
   triggerBuild,
   envRuntimeLogWsUrl,
+  BASE_PATH,
   type Environment,
   type Build,
 } from '@/services/api'
@@ -328,7 +329,7 @@ export default function EnvDetail() {
                           </span>
                         ) : (
                           <a
-                            href={`/api/v1/builds/${b.id}/log`}
+                            href={`${BASE_PATH}/api/v1/builds/${b.id}/log`}
                             target="_blank"
                             rel="noreferrer"
                             className="text-[11px] text-muted-foreground hover:text-foreground transition-colors"
@@ -4,7 +4,12 @@
 // Mutating endpoints (POST/PUT/DELETE) require the admin token stored in
 // localStorage["envm_token"] — set via the Settings page.
 
-const API_BASE = '/api/v1'
+// BASE_PATH is the subpath the server is mounted under (its BASE_PATH
+// setting), read from the <base href> the server injects into index.html.
+// Empty when served at the domain root.
+export const BASE_PATH = (document.querySelector('base')?.getAttribute('href') || '/').replace(/\/+$/, '')
+
+const API_BASE = `${BASE_PATH}/api/v1`
 
 // --- Token storage ---------------------------------------------------------
 
@@ -98,6 +103,8 @@ export interface ServiceStatus {
   image: string
   running: boolean
   exists: boolean
+  image_id?: string
+  image_digest?: string
 }
 
 export interface LicenseStatus {
@@ -174,7 +181,7 @@ export async function getBuildLog(buildId: string): Promise<string> {
   const headers: Record<string, string> = {}
   const token = getStoredToken()
   if (token) headers['Authorization'] = `Bearer ${token}`
-  const r = await fetch(`/api/v1/builds/${buildId}/log`, { headers })
+  const r = await fetch(`${API_BASE}/builds/${buildId}/log`, { headers })
   if (!r.ok) {
     const text = await r.text().catch(() => '')
     throw new Error(`HTTP ${r.status}: ${text || r.statusText}`)
@@ -218,18 +225,18 @@ function wsTokenSuffix(existing?: string): string {
 
 export function buildLogWsUrl(envId: string): string {
   const proto = window.location.protocol === 'https:' ? 'wss:' : 'ws:'
-  return `${proto}//${window.location.host}/ws/envs/${envId}/build-logs${wsTokenSuffix()}`
+  return `${proto}//${window.location.host}${BASE_PATH}/ws/envs/${envId}/build-logs${wsTokenSuffix()}`
 }
 
 export function envRuntimeLogWsUrl(envId: string, service?: string): string {
   const proto = window.location.protocol === 'https:' ? 'wss:' : 'ws:'
   const qs = service ? `?service=${encodeURIComponent(service)}` : ''
-  return `${proto}//${window.location.host}/ws/envs/${envId}/runtime-logs${wsTokenSuffix(qs)}`
+  return `${proto}//${window.location.host}${BASE_PATH}/ws/envs/${envId}/runtime-logs${wsTokenSuffix(qs)}`
 }
 
 export function serviceRuntimeLogWsUrl(name: string): string {
   const proto = window.location.protocol === 'https:' ? 'wss:' : 'ws:'
-  return `${proto}//${window.location.host}/ws/services/${encodeURIComponent(name)}/runtime-logs${wsTokenSuffix()}`
+  return `${proto}//${window.location.host}${BASE_PATH}/ws/services/${encodeURIComponent(name)}/runtime-logs${wsTokenSuffix()}`
 }
 
 // Topology
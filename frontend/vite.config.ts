@@ -4,6 +4,9 @@ import path from 'path'
 
 // https://vite.dev/config/
 export default defineConfig({
+  // Relative asset URLs: the server injects <base href> into index.html,
+  // so one build works at the domain root and under any BASE_PATH.
+  base: './',
   plugins: [react()],
   resolve: {
     alias: {